@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS idempotency_key;
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    key             TEXT NOT NULL,
+    request_hash    TEXT NOT NULL,
+    status_code     INT DEFAULT 0,
+    content_type    TEXT DEFAULT '',
+    body            BYTEA,
+    completed       BOOL DEFAULT 'f',
+    created_at      TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY(key)
+);
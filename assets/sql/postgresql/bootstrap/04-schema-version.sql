@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS schema_version;
+CREATE TABLE IF NOT EXISTS schema_version (
+    version         INT NOT NULL,
+    applied_at      TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY(version)
+);
+
+INSERT INTO schema_version(version) VALUES (1);
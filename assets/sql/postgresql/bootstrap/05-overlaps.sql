@@ -0,0 +1,20 @@
+-- sessions stored under the warn overlap policy are left out of the
+-- constraint.
+ALTER TABLE time_tracker ADD COLUMN IF NOT EXISTS overlap_accepted BOOL NOT NULL DEFAULT 'f';
+
+-- sessions overlapping before the constraint existed are kept, they are
+-- reported by GET /api/v1/tracker/overlaps.
+UPDATE time_tracker t
+SET overlap_accepted = 't'
+WHERE t.deleted = 'f' AND EXISTS (
+    SELECT 1
+    FROM time_tracker o
+    WHERE o.id <> t.id AND o.deleted = 'f' AND tsrange(o.started, o.ended) && tsrange(t.started, t.ended)
+);
+
+ALTER TABLE time_tracker
+    ADD CONSTRAINT time_tracker_no_overlap
+    EXCLUDE USING gist (tsrange(started, ended) WITH &&)
+    WHERE (deleted = 'f' AND NOT overlap_accepted);
+
+INSERT INTO schema_version(version) VALUES (2);
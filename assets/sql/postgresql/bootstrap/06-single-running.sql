@@ -0,0 +1,9 @@
+-- trackers written with the single running mode on are flagged, at most one
+-- of them can be running.
+ALTER TABLE time_tracker ADD COLUMN IF NOT EXISTS single_running BOOL NOT NULL DEFAULT 'f';
+
+CREATE UNIQUE INDEX IF NOT EXISTS time_tracker_single_running
+    ON time_tracker (single_running)
+    WHERE ended IS NULL AND deleted = 'f' AND single_running;
+
+INSERT INTO schema_version(version) VALUES (3);
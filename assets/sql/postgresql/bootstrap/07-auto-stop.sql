@@ -0,0 +1,7 @@
+-- last activity reported by a running tracker, and the record of the
+-- scheduler stopping a tracker left running.
+ALTER TABLE time_tracker ADD COLUMN IF NOT EXISTS last_heartbeat TIMESTAMP;
+ALTER TABLE time_tracker ADD COLUMN IF NOT EXISTS auto_stop_reason TEXT;
+ALTER TABLE time_tracker ADD COLUMN IF NOT EXISTS auto_stopped_at TIMESTAMP;
+
+INSERT INTO schema_version(version) VALUES (4);
@@ -0,0 +1,19 @@
+-- time planned per project, compared with the tracked time in reports.
+CREATE TABLE IF NOT EXISTS planned_block (
+    id              SERIAL,
+    started         TIMESTAMP NOT NULL,
+    ended           TIMESTAMP NOT NULL,
+    project         TEXT NOT NULL,
+    note            TEXT NOT NULL DEFAULT '',
+    deleted         BOOL DEFAULT 'f',
+    version         INT DEFAULT 1,
+    created_at      TIMESTAMP DEFAULT NOW(),
+    updated_at      TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY(id),
+    CHECK (ended > started)
+);
+
+CREATE INDEX IF NOT EXISTS planned_block_started ON planned_block (started) WHERE deleted = 'f';
+
+INSERT INTO schema_version(version) VALUES (5);
@@ -0,0 +1,33 @@
+-- hour and money budgets per project, hours are stored in seconds and money
+-- in minor units of currency.
+CREATE TABLE IF NOT EXISTS budget (
+    id              SERIAL,
+    project         TEXT NOT NULL,
+    period          TEXT NOT NULL,
+    hours           DOUBLE PRECISION NOT NULL DEFAULT 0,
+    amount          BIGINT NOT NULL DEFAULT 0,
+    hourly_rate     BIGINT NOT NULL DEFAULT 0,
+    currency        TEXT NOT NULL DEFAULT '',
+    thresholds      INT[] NOT NULL DEFAULT '{}',
+    deleted         BOOL DEFAULT 'f',
+    version         INT DEFAULT 1,
+    created_at      TIMESTAMP DEFAULT NOW(),
+    updated_at      TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS budget_project ON budget (project) WHERE deleted = 'f';
+
+-- thresholds already notified, so every alert goes out once per period.
+CREATE TABLE IF NOT EXISTS budget_alert (
+    budget_id       INT NOT NULL REFERENCES budget(id),
+    period_start    TIMESTAMP NOT NULL,
+    kind            TEXT NOT NULL,
+    threshold       INT NOT NULL,
+    created_at      TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY(budget_id, period_start, kind, threshold)
+);
+
+INSERT INTO schema_version(version) VALUES (6);
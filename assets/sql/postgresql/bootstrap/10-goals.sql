@@ -0,0 +1,16 @@
+-- daily hour goals indexed from sunday and the weekly goal, in seconds. The
+-- application has no users, so there is a single row.
+CREATE TABLE IF NOT EXISTS goal (
+    id              INT NOT NULL DEFAULT 1,
+    daily           INT[] NOT NULL DEFAULT '{0,0,0,0,0,0,0}',
+    weekly          INT NOT NULL DEFAULT 0,
+    version         INT DEFAULT 1,
+    created_at      TIMESTAMP DEFAULT NOW(),
+    updated_at      TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY(id),
+    CHECK (id = 1),
+    CHECK (array_length(daily, 1) = 7)
+);
+
+INSERT INTO schema_version(version) VALUES (7);
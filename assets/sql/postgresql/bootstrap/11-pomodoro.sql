@@ -0,0 +1,40 @@
+-- pomodoro sessions with their schedule, durations are stored in seconds.
+-- tracker_id is the tracker of the focus phase in progress.
+CREATE TABLE IF NOT EXISTS pomodoro (
+    id                  SERIAL,
+    name                TEXT NOT NULL,
+    project             TEXT,
+    tags                TEXT[] NOT NULL DEFAULT '{}',
+    focus               INT NOT NULL,
+    short_break         INT NOT NULL DEFAULT 0,
+    long_break          INT NOT NULL DEFAULT 0,
+    long_break_every    INT NOT NULL DEFAULT 0,
+    rounds              INT NOT NULL DEFAULT 0,
+    started             TIMESTAMP NOT NULL,
+    ended               TIMESTAMP,
+    tracker_id          INT,
+    version             INT DEFAULT 1,
+    created_at          TIMESTAMP DEFAULT NOW(),
+    updated_at          TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX IF NOT EXISTS pomodoro_active ON pomodoro (started) WHERE ended IS NULL;
+
+-- finished phases, breaks are only recorded here and never as trackers.
+CREATE TABLE IF NOT EXISTS pomodoro_phase (
+    pomodoro_id         INT NOT NULL REFERENCES pomodoro(id),
+    round               INT NOT NULL,
+    kind                TEXT NOT NULL,
+    started             TIMESTAMP NOT NULL,
+    ended               TIMESTAMP NOT NULL,
+    completed           BOOL NOT NULL DEFAULT 'f',
+    tracker_id          INT,
+
+    PRIMARY KEY(pomodoro_id, round, kind)
+);
+
+CREATE INDEX IF NOT EXISTS pomodoro_phase_started ON pomodoro_phase (started);
+
+INSERT INTO schema_version(version) VALUES (8);
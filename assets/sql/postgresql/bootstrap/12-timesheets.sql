@@ -0,0 +1,30 @@
+-- weekly timesheets, started and ended bound the week in the configured
+-- location. Weeks without a row are open.
+CREATE TABLE IF NOT EXISTS timesheet (
+    id              SERIAL,
+    started         TIMESTAMP NOT NULL,
+    ended           TIMESTAMP NOT NULL,
+    status          TEXT NOT NULL,
+    version         INT DEFAULT 1,
+    created_at      TIMESTAMP DEFAULT NOW(),
+    updated_at      TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY(id),
+    UNIQUE(started)
+);
+
+CREATE INDEX IF NOT EXISTS timesheet_approved ON timesheet (started, ended) WHERE status = 'approved';
+
+-- the comment left with every action taken on a timesheet.
+CREATE TABLE IF NOT EXISTS timesheet_comment (
+    id              SERIAL,
+    timesheet_id    INT NOT NULL REFERENCES timesheet(id),
+    action          TEXT NOT NULL,
+    author          TEXT NOT NULL DEFAULT '',
+    body            TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMP NOT NULL,
+
+    PRIMARY KEY(id)
+);
+
+INSERT INTO schema_version(version) VALUES (9);
@@ -0,0 +1,6 @@
+-- trackers belong to an optional project and carry free form tags, served by
+-- /api/v2/tracker.
+ALTER TABLE time_tracker ADD COLUMN IF NOT EXISTS project TEXT;
+ALTER TABLE time_tracker ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+INSERT INTO schema_version(version) VALUES (10);
@@ -0,0 +1,19 @@
+-- trackers running before 06-single-running.sql weren't flagged, which left
+-- them out of the single running index. The index allows one flagged tracker,
+-- the latest one is flagged. Older ones still running are caught by the
+-- check on start until they are stopped.
+UPDATE time_tracker
+SET single_running = 't'
+WHERE id = (
+    SELECT id
+    FROM time_tracker
+    WHERE ended IS NULL AND deleted = 'f'
+    ORDER BY started DESC, id DESC
+    LIMIT 1
+) AND NOT EXISTS (
+    SELECT 1
+    FROM time_tracker
+    WHERE ended IS NULL AND deleted = 'f' AND single_running
+);
+
+INSERT INTO schema_version(version) VALUES (11);
@@ -0,0 +1,11 @@
+-- running trackers have no end, the constraint saw them overlap every later
+-- start. It now guards stopped sessions only, running trackers are checked
+-- up to now by the service and again when they are stopped.
+ALTER TABLE time_tracker DROP CONSTRAINT IF EXISTS time_tracker_no_overlap;
+
+ALTER TABLE time_tracker
+    ADD CONSTRAINT time_tracker_no_overlap
+    EXCLUDE USING gist (tsrange(started, ended) WITH &&)
+    WHERE (deleted = 'f' AND NOT overlap_accepted AND ended IS NOT NULL);
+
+INSERT INTO schema_version(version) VALUES (12);
@@ -1,69 +1,440 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
 	"database/sql"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"pento/code-challenge/application/handlers"
+	"pento/code-challenge/application/metrics"
+	"pento/code-challenge/application/middleware"
+	"pento/code-challenge/application/scheduler"
+	"pento/code-challenge/application/tracing"
+	v2handlers "pento/code-challenge/application/v2/handlers"
+	"pento/code-challenge/broadcast"
+	"pento/code-challenge/certs"
+	"pento/code-challenge/config"
+	"pento/code-challenge/domain/tracker/models"
 	"pento/code-challenge/domain/tracker/services"
+	"pento/code-challenge/notify"
 	"pento/code-challenge/repositories/postgresql"
+	"pento/code-challenge/web"
+	"syscall"
+	"time"
 
 	gHandlers "github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 
 	_ "github.com/jackc/pgx/stdlib"
 )
 
+const (
+	eventHistorySize  = 256
+	idempotencyKeyTTL = 24 * time.Hour
+)
+
 var (
-	pgsqlAddr = ""
-	pgsqlPort = 0
-	kafkaAddr = ""
-	kafkaPort = 0
+	tlsVersions = map[string]uint16{
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+	clientAuthTypes = map[string]tls.ClientAuthType{
+		"require":  tls.RequireAndVerifyClientCert,
+		"optional": tls.VerifyClientCertIfGiven,
+	}
 )
 
-//SetupAPI ...
-func SetupAPI() {
+// SetupAPI ...
+func SetupAPI(cfg config.Config, logger *logrus.Logger) error {
+
+	pool, err := sql.Open("pgx", cfg.Database.DSN())
+	if err != nil {
+		return fmt.Errorf("%w failed to open database", err)
+	}
+	defer pool.Close()
 
-	getEnvironmentVariables()
+	pool.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	pool.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	pool.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
-	connString := fmt.Sprintf("host=%s port=%d user=postgres password=postgres dbname=postgres sslmode=disable", pgsqlAddr, pgsqlPort)
+	health := postgresql.NewHealthStore(pool)
+	if err := waitForDatabase(health, cfg.Database, logger); err != nil {
+		return err
+	}
 
-	pool, err := sql.Open("pgx", connString)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+		defer cancel()
+
+		if err := shutdownTracing(ctx); err != nil {
+			logger.WithError(err).Error("failed to flush traces")
+		}
+	}()
+
+	broadcaster := broadcast.NewBroadcaster(eventHistorySize)
+	autoStop, err := autoStopRules(cfg.Running)
+	if err != nil {
+		return err
+	}
+
+	budgetLocation, err := time.LoadLocation(cfg.Budgets.Location)
+	if err != nil {
+		return fmt.Errorf("%w invalid budgets location", err)
+	}
+
+	notifier := notify.Multi{notify.NewLogNotifier(logger)}
+	if cfg.Budgets.WebhookURL != "" {
+		notifier = append(notifier, notify.NewWebhookNotifier(cfg.Budgets.WebhookURL, cfg.Budgets.WebhookTimeout))
+	}
+
+	timesheetLocation, err := time.LoadLocation(cfg.Timesheets.Location)
+	if err != nil {
+		return fmt.Errorf("%w invalid timesheets location", err)
 	}
-	defer pool.Close()
 
-	store := postgresql.NewTrackerStore(pool)
-	service := services.NewTrackerService(store)
-	handler := handlers.NewTrackerHandler(service)
+	clients, err := clientAddress(cfg)
+	if err != nil {
+		return err
+	}
+
+	rules := services.ValidationRules{
+		FutureTolerance:  cfg.Validation.FutureTolerance,
+		MaxSessionLength: cfg.Validation.MaxSessionLength,
+		MaxNameLength:    cfg.Validation.MaxNameLength,
+	}
+
+	store := postgresql.NewTrackerStore(pool, cfg.Running.Single, logger)
+	budgets := services.NewBudgetService(postgresql.NewBudgetStore(pool), store, notifier, rules, cfg.Budgets.Thresholds, budgetLocation, logger)
+	timesheets := services.NewTimesheetService(postgresql.NewTimesheetStore(pool), store, timesheetLocation)
+	service := services.NewTrackerService(store, broadcaster, rules, services.OverlapPolicy(cfg.Validation.OverlapPolicy), services.RunningPolicy{
+		Single:   cfg.Running.Single,
+		AutoStop: cfg.Running.AutoStop,
+	}, autoStop, logger).WithBudgets(budgets)
+	if cfg.Timesheets.Lock {
+		service = service.WithLocks(timesheets)
+	}
+
+	reports := services.NewReportService(store, models.Rounding{
+		Mode:        models.RoundingMode(cfg.Rounding.Mode),
+		Increment:   cfg.Rounding.Increment,
+		Aggregation: models.RoundingAggregation(cfg.Rounding.Aggregation),
+	})
+
+	plans := services.NewPlanService(postgresql.NewPlanStore(pool), store, services.ValidationRules{
+		MaxSessionLength: cfg.Validation.MaxSessionLength,
+		MaxNameLength:    cfg.Validation.MaxNameLength,
+	})
+
+	appMetrics := metrics.New(pool, cfg.Database.Name)
+	appMetrics.Register(metrics.NewRunningTrackersCollector(service))
+	instrumented := appMetrics.InstrumentTrackerService(tracing.NewTracedTrackerService(service))
+
+	pomodoros := services.NewPomodoroService(postgresql.NewPomodoroStore(pool), instrumented, models.PomodoroSchedule{
+		Focus:          cfg.Pomodoro.Focus,
+		ShortBreak:     cfg.Pomodoro.ShortBreak,
+		LongBreak:      cfg.Pomodoro.LongBreak,
+		LongBreakEvery: cfg.Pomodoro.LongBreakEvery,
+		Rounds:         cfg.Pomodoro.Rounds,
+	}, logger)
+
+	handler := handlers.NewTrackerHandler(instrumented, logger)
+	eventHandler := handlers.NewEventHandler(broadcaster, logger)
+	v2Handler := v2handlers.NewTrackerHandler(instrumented, logger)
+	reportHandler := v2handlers.NewReportHandler(reports, logger)
+	planHandler := v2handlers.NewPlanHandler(plans, logger)
+	budgetHandler := v2handlers.NewBudgetHandler(budgets, logger)
+	timesheetHandler := v2handlers.NewTimesheetHandler(timesheets, logger)
+	pomodoroHandler := v2handlers.NewPomodoroHandler(pomodoros, logger)
+	goalHandler := v2handlers.NewGoalHandler(services.NewGoalService(postgresql.NewGoalStore(pool), store), logger)
+	healthHandler := handlers.NewHealthHandler(health, cfg.Debug.Token, logger)
+	idempotency := middleware.NewIdempotency(postgresql.NewIdempotencyStore(pool, idempotencyKeyTTL), logger)
 
 	router := mux.NewRouter().StrictSlash(true)
+	router.Use(otelmux.Middleware(cfg.Tracing.ServiceName))
+	router.Use(middleware.NewLogging(logger).Middleware)
+	router.Use(appMetrics.Middleware)
+	router.Use(middleware.NewRateLimiter(middleware.NewMemoryRateLimitBackend(), rateLimits(cfg), logger).WithClientAddress(clients).Middleware)
+	router.Use(middleware.NewTimeout(cfg.HTTP.RouteTimeouts).Middleware)
 
+	router.HandleFunc("/healthz", healthHandler.Liveness).Methods("GET")
+	router.HandleFunc("/readyz", healthHandler.Readiness).Methods("GET")
+	router.HandleFunc("/debug/status", healthHandler.Status).Methods("GET")
+	router.Handle("/metrics", appMetrics.Handler()).Methods("GET")
+
+	router.HandleFunc("/api/v1/tracker/events", eventHandler.StreamEvents).Methods("GET")
+	router.HandleFunc("/api/v1/tracker/overlaps", handler.ListOverlaps).Methods("GET")
 	router.HandleFunc("/api/v1/tracker/{id}", handler.GetTracker).Methods("GET")
 	router.HandleFunc("/api/v1/tracker", handler.ListTrackers).Methods("GET")
-	router.HandleFunc("/api/v1/tracker", handler.CreateTracker).Methods("POST")
-	router.HandleFunc("/api/v1/tracker/{id}", handler.UpdateTracker).Methods("PUT")
+	router.HandleFunc("/api/v1/tracker", idempotency.Handler(handler.CreateTracker)).Methods("POST")
+	router.HandleFunc("/api/v1/tracker/batch", idempotency.Handler(handler.Batch)).Methods("POST")
+	router.HandleFunc("/api/v1/tracker/{id}", idempotency.Handler(handler.UpdateTracker)).Methods("PUT")
+	router.HandleFunc("/api/v1/tracker/{id}/heartbeat", handler.Heartbeat).Methods("POST")
 	router.HandleFunc("/api/v1/tracker/{id}", handler.DeleteTracker).Methods("DELETE")
+	// the goal routes were introduced under /api/v1 and stay there as aliases
+	router.HandleFunc("/api/v1/goals", goalHandler.GetGoals).Methods("GET")
+	router.HandleFunc("/api/v1/goals", goalHandler.UpdateGoals).Methods("PUT")
+	router.HandleFunc("/api/v1/goals/progress", goalHandler.Progress).Methods("GET")
+
+	router.HandleFunc("/api/v2/tracker/{id}", v2Handler.GetTracker).Methods("GET")
+	router.HandleFunc("/api/v2/tracker", v2Handler.ListTrackers).Methods("GET")
+	router.HandleFunc("/api/v2/tracker", idempotency.Handler(v2Handler.CreateTracker)).Methods("POST")
+	router.HandleFunc("/api/v2/tracker/{id}", idempotency.Handler(v2Handler.UpdateTracker)).Methods("PUT")
+	router.HandleFunc("/api/v2/tracker/{id}", v2Handler.DeleteTracker).Methods("DELETE")
+	router.HandleFunc("/api/v2/plans/{id}", planHandler.GetBlock).Methods("GET")
+	router.HandleFunc("/api/v2/plans", planHandler.ListBlocks).Methods("GET")
+	router.HandleFunc("/api/v2/plans", idempotency.Handler(planHandler.CreateBlock)).Methods("POST")
+	router.HandleFunc("/api/v2/plans/{id}", planHandler.UpdateBlock).Methods("PUT")
+	router.HandleFunc("/api/v2/plans/{id}", planHandler.DeleteBlock).Methods("DELETE")
+	router.HandleFunc("/api/v2/budgets/{id}/burndown", budgetHandler.BurnDown).Methods("GET")
+	router.HandleFunc("/api/v2/budgets/{id}", budgetHandler.GetBudget).Methods("GET")
+	router.HandleFunc("/api/v2/budgets", budgetHandler.ListBudgets).Methods("GET")
+	router.HandleFunc("/api/v2/budgets", idempotency.Handler(budgetHandler.CreateBudget)).Methods("POST")
+	router.HandleFunc("/api/v2/budgets/{id}", budgetHandler.UpdateBudget).Methods("PUT")
+	router.HandleFunc("/api/v2/budgets/{id}", budgetHandler.DeleteBudget).Methods("DELETE")
+	router.HandleFunc("/api/v2/timesheets", timesheetHandler.ListTimesheets).Methods("GET")
+	router.HandleFunc("/api/v2/timesheets/{week}", timesheetHandler.GetTimesheet).Methods("GET")
+	router.HandleFunc("/api/v2/timesheets/{week}/{action:submit|approve|reject|reopen}", timesheetHandler.Transition).Methods("POST")
+	router.HandleFunc("/api/v2/goals", goalHandler.GetGoals).Methods("GET")
+	router.HandleFunc("/api/v2/goals", goalHandler.UpdateGoals).Methods("PUT")
+	router.HandleFunc("/api/v2/goals/progress", goalHandler.Progress).Methods("GET")
+	router.HandleFunc("/api/v2/pomodoro/report", pomodoroHandler.Report).Methods("GET")
+	router.HandleFunc("/api/v2/pomodoro/{id}", pomodoroHandler.GetPomodoro).Methods("GET")
+	router.HandleFunc("/api/v2/pomodoro", pomodoroHandler.ListPomodoros).Methods("GET")
+	router.HandleFunc("/api/v2/pomodoro", idempotency.Handler(pomodoroHandler.StartPomodoro)).Methods("POST")
+	router.HandleFunc("/api/v2/pomodoro/{id}/stop", idempotency.Handler(pomodoroHandler.StopPomodoro)).Methods("POST")
+	router.HandleFunc("/api/v2/reports/time", reportHandler.TimeReport).Methods("GET")
+	router.HandleFunc("/api/v2/reports/plan", planHandler.PlanReport).Methods("GET")
 
-	headersOk := gHandlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
-	originsOk := gHandlers.AllowedOrigins([]string{"*"})
+	// registered last, it falls back to index.html for client side routes
+	if cfg.Frontend.Enabled {
+		frontend, err := web.NewHandler(web.Dist(), cfg.Frontend.APIBaseURL)
+		if err != nil {
+			return err
+		}
+		router.PathPrefix("/").Handler(frontend).Methods("GET", "HEAD")
+	}
+
+	headersOk := gHandlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "Last-Event-ID", "Idempotency-Key", "traceparent", "tracestate", "X-Request-ID"})
+	originsOk := gHandlers.AllowedOrigins(cfg.HTTP.CORSOrigins)
 	methodsOk := gHandlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS", "DELETE"})
+	exposedOk := gHandlers.ExposedHeaders([]string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "Idempotent-Replayed", "X-Request-ID"})
+
+	server := &http.Server{
+		Addr:              cfg.HTTP.ListenAddress,
+		Handler:           gHandlers.CORS(originsOk, headersOk, methodsOk, exposedOk)(router),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	// streaming clients never go idle, end their streams so shutdown only
+	// waits for regular requests.
+	server.RegisterOnShutdown(broadcaster.Close)
+
+	var redirect *http.Server
+
+	if cfg.TLS.Enabled() {
+		reloader, err := certs.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile, logger)
+		if err != nil {
+			return err
+		}
+
+		watchCtx, stopWatching := context.WithCancel(context.Background())
+		defer stopWatching()
+		go reloader.Watch(watchCtx, cfg.TLS.ReloadInterval)
+
+		// NextProtos is set up front since per connection configs, used for
+		// client authentication, are cloned before net/http adds h2.
+		server.TLSConfig = reloader.TLSConfig(&tls.Config{
+			MinVersion: tlsVersions[cfg.TLS.MinVersion],
+			NextProtos: []string{"h2", "http/1.1"},
+		}, clientAuthTypes[cfg.TLS.ClientAuth])
+
+		if cfg.TLS.RedirectAddress != "" {
+			redirect = &http.Server{
+				Addr:              cfg.TLS.RedirectAddress,
+				Handler:           redirectToHTTPS(cfg.HTTP.ListenAddress),
+				ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+				ReadTimeout:       cfg.HTTP.ReadTimeout,
+				IdleTimeout:       cfg.HTTP.IdleTimeout,
+			}
+		}
+	}
+
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
 
-	log.Println("starting tracker API")
-	log.Fatal(http.ListenAndServe(":8080", gHandlers.CORS(originsOk, headersOk, methodsOk)(router)))
+	if autoStop.Enabled() {
+		go scheduler.NewAutoStopper(service, cfg.Running.CheckInterval, logger).Run(schedulerCtx)
+	}
+	go scheduler.NewPomodoroTicker(pomodoros, cfg.Pomodoro.CheckInterval, logger).Run(schedulerCtx)
+
+	return serve(server, redirect, cfg.HTTP.ShutdownTimeout, logger)
+}
+
+// waitForDatabase retries the initial connection with exponential backoff so
+// the API can start before PostgreSQL is ready.
+func waitForDatabase(health *postgresql.HealthStore, cfg config.DatabaseConfig, logger logrus.FieldLogger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+
+	interval := cfg.ConnectRetryInterval
+
+	for attempt := 1; ; attempt++ {
+		err := health.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		logger.WithError(err).WithFields(logrus.Fields{
+			"attempt":  attempt,
+			"retry_in": interval.String(),
+		}).Warn("database not ready")
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w database unreachable after %d attempts", err, attempt)
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > cfg.ConnectRetryMaxInterval {
+			interval = cfg.ConnectRetryMaxInterval
+		}
+	}
 }
 
-func getEnvironmentVariables() {
-	env := os.Getenv("ENVIRONMENT")
+// serve runs the server, and the optional HTTP to HTTPS redirect server,
+// until one fails or a SIGINT/SIGTERM is received, in which case in-flight
+// requests are given shutdownTimeout to complete.
+func serve(server, redirect *http.Server, shutdownTimeout time.Duration, logger logrus.FieldLogger) error {
+	serverErr := make(chan error, 2)
+
+	go func() {
+		if server.TLSConfig != nil {
+			logger.WithField("address", server.Addr).Info("starting tracker API with TLS")
+			serverErr <- server.ListenAndServeTLS("", "")
+
+			return
+		}
 
-	if env == "docker" {
-		pgsqlAddr = "psql"
-		pgsqlPort = 5432
-	} else {
-		pgsqlAddr = "localhost"
-		pgsqlPort = 5434
+		logger.WithField("address", server.Addr).Info("starting tracker API")
+		serverErr <- server.ListenAndServe()
+	}()
+
+	if redirect != nil {
+		go func() {
+			logger.WithField("address", redirect.Addr).Info("redirecting HTTP to HTTPS")
+			serverErr <- redirect.ListenAndServe()
+		}()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("%w server stopped", err)
+	case sig := <-signals:
+		logger.WithField("signal", sig.String()).Info("shutting down")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if redirect != nil {
+		if err := redirect.Shutdown(ctx); err != nil {
+			logger.WithError(err).Warn("failed to stop the redirect listener")
+		}
+	}
+
+	if err := server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("%w failed to drain connections", err)
 	}
+
+	logger.Info("tracker API stopped")
+
+	return nil
+}
+
+// redirectToHTTPS sends plain HTTP requests to the same host and path on the
+// HTTPS listen address, 308 keeps the method and body of API calls.
+func redirectToHTTPS(listenAddress string) http.Handler {
+	_, port, _ := net.SplitHostPort(listenAddress)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = hostname
+		}
+		if port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
+}
+
+func autoStopRules(cfg config.RunningConfig) (services.AutoStopRules, error) {
+	endOfDay, err := cfg.EndOfDayOffset()
+	if err != nil {
+		return services.AutoStopRules{}, err
+	}
+
+	location, err := time.LoadLocation(cfg.Location)
+	if err != nil {
+		return services.AutoStopRules{}, err
+	}
+
+	return services.AutoStopRules{
+		MaxDuration: cfg.MaxDuration,
+		EndOfDay:    endOfDay,
+		Location:    location,
+	}, nil
+}
+
+func rateLimits(cfg config.Config) map[string]middleware.Limit {
+	limits := make(map[string]middleware.Limit, len(cfg.RateLimits))
+
+	for group, limit := range cfg.RateLimits {
+		limits[group] = middleware.Limit{
+			Requests: limit.Requests,
+			Period:   limit.Period,
+			Burst:    limit.Burst,
+		}
+	}
+
+	return limits
+}
+
+func clientAddress(cfg config.Config) (middleware.ClientAddress, error) {
+	clients := middleware.ClientAddress{
+		Header:         cfg.HTTP.ClientIPHeader,
+		TrustedProxies: make([]*net.IPNet, 0, len(cfg.HTTP.TrustedProxies)),
+	}
+
+	for _, proxy := range cfg.HTTP.TrustedProxies {
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return middleware.ClientAddress{}, fmt.Errorf("%w invalid trusted proxy", err)
+		}
+		clients.TrustedProxies = append(clients.TrustedProxies, network)
+	}
+
+	return clients, nil
 }
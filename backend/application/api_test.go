@@ -0,0 +1,89 @@
+package api
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+func Test_serve_drainsInFlightRequests(t *testing.T) {
+	g := NewWithT(t)
+
+	// the test process keeps running whether serve caught the signal or not
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGTERM)
+	defer signal.Stop(guard)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).ToNot(HaveOccurred(), "should find a free port")
+	address := listener.Addr().String()
+	g.Expect(listener.Close()).To(Succeed(), "should free the port")
+
+	started := make(chan struct{})
+	server := &http.Server{
+		Addr: address,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			_, _ = w.Write([]byte("done"))
+		}),
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(server, nil, time.Second, logrus.New())
+	}()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		var (
+			response *http.Response
+			err      error
+		)
+		for attempt := 0; attempt < 50; attempt++ {
+			response, err = http.Get("http://" + address)
+			if err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err != nil {
+			responses <- result{err: err}
+
+			return
+		}
+		defer response.Body.Close()
+
+		body, err := ioutil.ReadAll(response.Body)
+		responses <- result{status: response.StatusCode, body: string(body), err: err}
+	}()
+
+	g.Eventually(started).Should(BeClosed(), "should start handling the request")
+	g.Expect(syscall.Kill(os.Getpid(), syscall.SIGTERM)).To(Succeed(), "should signal the shutdown")
+
+	var response result
+	g.Eventually(responses).Should(Receive(&response), "should answer the request")
+	g.Expect(response.err).ToNot(HaveOccurred(), "should finish the request in flight")
+	g.Expect(response.status).To(Equal(http.StatusOK), "should finish the request in flight")
+	g.Expect(response.body).To(Equal("done"), "should finish the request in flight")
+
+	var serveErr error
+	g.Eventually(served).Should(Receive(&serveErr), "should stop serving")
+	g.Expect(serveErr).ToNot(HaveOccurred(), "should stop cleanly")
+
+	_, err = http.Get("http://" + address)
+	g.Expect(err).To(HaveOccurred(), "should refuse new connections")
+}
@@ -0,0 +1,70 @@
+// Package apierror maps the errors of the tracker domain to the HTTP statuses
+// and error codes answered by both API versions.
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"pento/code-challenge/domain/tracker/services"
+)
+
+// The codes error responses carry next to the message, they tell apart the
+// conflicts sharing the 409 status.
+const (
+	CodeWrongVersion      = "wrong_version"
+	CodeOverlap           = "overlap"
+	CodeTrackerRunning    = "tracker_running"
+	CodeNotRunning        = "not_running"
+	CodePeriodLocked      = "period_locked"
+	CodeBudgetExists      = "budget_exists"
+	CodeInvalidTransition = "invalid_transition"
+	CodePomodoroEnded     = "pomodoro_ended"
+)
+
+// Status returns the status answering a request that failed with err,
+// errors the domain doesn't define are internal errors.
+func Status(err error) int {
+	switch {
+	case errors.Is(err, services.ErrTrackerNotFound), errors.Is(err, services.ErrBlockNotFound), errors.Is(err, services.ErrBudgetNotFound),
+		errors.Is(err, services.ErrPomodoroNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrWrongVersion), errors.Is(err, services.ErrOverlap), errors.Is(err, services.ErrTrackerRunning),
+		errors.Is(err, services.ErrNotRunning), errors.Is(err, services.ErrBudgetExists), errors.Is(err, services.ErrPeriodLocked),
+		errors.Is(err, services.ErrInvalidTransition), errors.Is(err, services.ErrPomodoroEnded):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrUnknownOperation), errors.Is(err, services.ErrEmptyBatch), errors.Is(err, services.ErrBatchTooLarge),
+		errors.Is(err, services.ErrUnknownTimesheetAction), errors.Is(err, services.ErrInvalidRounding):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Code returns the code of a conflict, an empty string for other errors.
+func Code(err error) string {
+	switch {
+	case errors.Is(err, services.ErrWrongVersion):
+		return CodeWrongVersion
+	case errors.Is(err, services.ErrOverlap):
+		return CodeOverlap
+	case errors.Is(err, services.ErrTrackerRunning):
+		return CodeTrackerRunning
+	case errors.Is(err, services.ErrNotRunning):
+		return CodeNotRunning
+	case errors.Is(err, services.ErrPeriodLocked):
+		return CodePeriodLocked
+	case errors.Is(err, services.ErrBudgetExists):
+		return CodeBudgetExists
+	case errors.Is(err, services.ErrInvalidTransition):
+		return CodeInvalidTransition
+	case errors.Is(err, services.ErrPomodoroEnded):
+		return CodePomodoroEnded
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,108 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"pento/code-challenge/domain/tracker/services"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_Status(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		err         error
+		expected    int
+	}{
+		{
+			description: "when a resource is missing",
+			err:         fmt.Errorf("%w failed to get tracker", services.ErrTrackerNotFound),
+			expected:    http.StatusNotFound,
+		},
+		{
+			description: "when the period is locked",
+			err:         fmt.Errorf("%w: week of 2021-05-03", services.ErrPeriodLocked),
+			expected:    http.StatusConflict,
+		},
+		{
+			description: "when the tracker overlaps others",
+			err:         &services.OverlapError{IDs: []uint64{1}},
+			expected:    http.StatusConflict,
+		},
+		{
+			description: "when the tracker is invalid",
+			err:         &services.ValidationError{},
+			expected:    http.StatusUnprocessableEntity,
+		},
+		{
+			description: "when a batch operation is unknown",
+			err:         services.ErrUnknownOperation,
+			expected:    http.StatusBadRequest,
+		},
+		{
+			description: "when the request deadline passed",
+			err:         fmt.Errorf("%w failed to list trackers", context.DeadlineExceeded),
+			expected:    http.StatusServiceUnavailable,
+		},
+		{
+			description: "when the error is unexpected",
+			err:         errors.New("connection refused"),
+			expected:    http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(Status(tc.err)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_Code(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		err         error
+		expected    string
+	}{
+		{
+			description: "when the version is outdated",
+			err:         fmt.Errorf("%w failed to store tracker", services.ErrWrongVersion),
+			expected:    CodeWrongVersion,
+		},
+		{
+			description: "when the period is locked",
+			err:         fmt.Errorf("%w: week of 2021-05-03", services.ErrPeriodLocked),
+			expected:    CodePeriodLocked,
+		},
+		{
+			description: "when another tracker is running",
+			err:         services.ErrTrackerRunning,
+			expected:    CodeTrackerRunning,
+		},
+		{
+			description: "when the tracker overlaps others",
+			err:         &services.OverlapError{IDs: []uint64{1}},
+			expected:    CodeOverlap,
+		},
+		{
+			description: "when the error isn't a conflict",
+			err:         services.ErrTrackerNotFound,
+			expected:    "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(Code(tc.err)).To(Equal(tc.expected))
+		})
+	}
+}
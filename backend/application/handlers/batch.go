@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/services"
+	"time"
+)
+
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best_effort"
+
+	batchStatusApplied     = "applied"
+	batchStatusFailed      = "failed"
+	batchStatusRolledBack  = "rolled_back"
+	batchStatusNotExecuted = "not_executed"
+)
+
+type batchOperationRequest struct {
+	Op      services.BatchOperationType `json:"op"`
+	ID      uint64                      `json:"id"`
+	Start   time.Time                   `json:"start"`
+	End     time.Time                   `json:"end"`
+	Name    string                      `json:"name"`
+	Project string                      `json:"project"`
+	Tags    []string                    `json:"tags"`
+	Version uint32                      `json:"version"`
+}
+
+type batchRequest struct {
+	Mode       string                  `json:"mode"`
+	Operations []batchOperationRequest `json:"operations"`
+}
+
+type batchResultResponse struct {
+	Index  int                         `json:"index"`
+	Op     services.BatchOperationType `json:"op"`
+	Status string                      `json:"status"`
+	Code   int                         `json:"code"`
+	// ErrorCode tells apart the conflicts answered with 409.
+	ErrorCode string                `json:"error_code,omitempty"`
+	Tracker   *TimeTrackerResponse  `json:"tracker,omitempty"`
+	Error     string                `json:"error,omitempty"`
+	Fields    []services.FieldError `json:"fields,omitempty"`
+	Overlaps  []uint64              `json:"overlaps,omitempty"`
+}
+
+type batchResponse struct {
+	Mode    string                `json:"mode"`
+	Results []batchResultResponse `json:"results"`
+}
+
+func (h TrackerHandler) Batch(w http.ResponseWriter, r *http.Request) {
+
+	var request batchRequest
+	reqBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log(r).Warn(err)
+
+		return
+	}
+
+	err = json.Unmarshal(reqBody, &request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log(r).Warn(err)
+
+		return
+	}
+
+	if request.Mode == "" {
+		request.Mode = batchModeAtomic
+	}
+
+	if request.Mode != batchModeAtomic && request.Mode != batchModeBestEffort {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log(r).Warnf("unknown batch mode %q", request.Mode)
+
+		return
+	}
+
+	params := services.BatchParams{
+		Atomic:     request.Mode == batchModeAtomic,
+		Operations: make([]services.BatchOperation, 0, len(request.Operations)),
+	}
+
+	for _, operation := range request.Operations {
+		params.Operations = append(params.Operations, services.BatchOperation{
+			Type:    operation.Op,
+			ID:      operation.ID,
+			Start:   operation.Start,
+			End:     operation.End,
+			Name:    operation.Name,
+			Project: operation.Project,
+			Tags:    operation.Tags,
+			Version: operation.Version,
+		})
+	}
+
+	results, err := h.service.Batch(r.Context(), params)
+	if err != nil && results == nil {
+		w.WriteHeader(apierror.Status(err))
+		h.log(r).Error(err)
+
+		return
+	}
+
+	status := http.StatusOK
+	response := batchResponse{
+		Mode:    request.Mode,
+		Results: make([]batchResultResponse, 0, len(results)),
+	}
+
+	failed := -1
+	for i, result := range results {
+		if result.Err != nil {
+			failed = i
+			break
+		}
+	}
+
+	for i, result := range results {
+		item := batchResultResponse{
+			Index: i,
+			Op:    request.Operations[i].Op,
+		}
+
+		switch {
+		case result.Err != nil:
+			item.Status = batchStatusFailed
+			item.Code = apierror.Status(result.Err)
+			item.ErrorCode = apierror.Code(result.Err)
+			item.Error = result.Err.Error()
+
+			var validationErr *services.ValidationError
+			if errors.As(result.Err, &validationErr) {
+				item.Fields = validationErr.Fields
+			}
+
+			var overlapErr *services.OverlapError
+			if errors.As(result.Err, &overlapErr) {
+				item.Overlaps = overlapErr.IDs
+			}
+		case result.Applied:
+			item.Status = batchStatusApplied
+			item.Code = http.StatusOK
+			if request.Operations[i].Op == services.BatchCreate {
+				item.Code = http.StatusCreated
+			}
+			if request.Operations[i].Op != services.BatchDelete {
+				tracker := fromDomain(result.Tracker)
+				item.Tracker = &tracker
+			}
+		case failed >= 0 && i > failed:
+			item.Status = batchStatusNotExecuted
+		default:
+			item.Status = batchStatusRolledBack
+		}
+
+		response.Results = append(response.Results, item)
+	}
+
+	if err != nil {
+		h.log(r).Error(err)
+		status = http.StatusInternalServerError
+		if failed >= 0 {
+			status = response.Results[failed].Code
+		}
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log(r).Error(err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	_, err = w.Write(body)
+	if err != nil {
+		h.log(r).Error(err)
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pento/code-challenge/broadcast"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/logging"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const keepAliveInterval = 15 * time.Second
+
+type EventSubscriber interface {
+	Subscribe(lastEventID uint64, filter broadcast.Filter) (*broadcast.Subscription, []models.TrackerEvent)
+}
+
+type EventHandler struct {
+	subscriber EventSubscriber
+	logger     logrus.FieldLogger
+}
+
+func NewEventHandler(subscriber EventSubscriber, logger logrus.FieldLogger) *EventHandler {
+	return &EventHandler{
+		subscriber: subscriber,
+		logger:     logger,
+	}
+}
+
+// trackerEventResponse leaves the tracker out of reset events.
+type trackerEventResponse struct {
+	Type       models.EventType     `json:"type"`
+	Tracker    *TimeTrackerResponse `json:"tracker,omitempty"`
+	OccurredAt time.Time            `json:"occurred_at"`
+}
+
+func (h EventHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log(r).Error("response writer does not support flushing")
+
+		return
+	}
+
+	var lastEventID uint64
+	var err error
+
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		lastEventID, err = strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.log(r).Warn(err)
+
+			return
+		}
+	}
+
+	filter, err := eventFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log(r).Warn(err)
+
+		return
+	}
+
+	subscription, replay := h.subscriber.Subscribe(lastEventID, filter)
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for _, event := range replay {
+		if err := writeEvent(w, event); err != nil {
+			h.log(r).Error(err)
+
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				h.log(r).Error(err)
+
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+
+			if err := writeEvent(w, event); err != nil {
+				h.log(r).Error(err)
+
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (h EventHandler) log(r *http.Request) logrus.FieldLogger {
+	return logging.FromContext(r.Context(), h.logger)
+}
+
+// eventFilter narrows the stream down to a single tracker when the tracker_id
+// query parameter is provided.
+func eventFilter(r *http.Request) (broadcast.Filter, error) {
+	if r.FormValue("tracker_id") == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseUint(r.FormValue("tracker_id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(event models.TrackerEvent) bool {
+		return event.Tracker.ID == id
+	}, nil
+}
+
+func writeEvent(w http.ResponseWriter, event models.TrackerEvent) error {
+	response := trackerEventResponse{
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+	}
+	if event.Type != models.EventReset {
+		tracker := fromDomain(event.Tracker)
+		response.Tracker = &tracker
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("%w failed to marshal event", err)
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	if err != nil {
+		return fmt.Errorf("%w failed to write event", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"pento/code-challenge/broadcast"
+	"pento/code-challenge/domain/tracker/models"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// readFrames reads n server sent event frames, keep-alive comments aside.
+func readFrames(g *WithT, reader *bufio.Reader, n int) []map[string]string {
+	frames := make([]map[string]string, 0, n)
+	frame := map[string]string{}
+
+	for len(frames) < n {
+		line, err := reader.ReadString('\n')
+		g.Expect(err).ToNot(HaveOccurred())
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if len(frame) > 0 {
+				frames = append(frames, frame)
+				frame = map[string]string{}
+			}
+		case strings.HasPrefix(line, ":"):
+		default:
+			parts := strings.SplitN(line, ": ", 2)
+			frame[parts[0]] = parts[1]
+		}
+	}
+
+	return frames
+}
+
+func Test_EventHandler_StreamEvents(t *testing.T) {
+
+	tracker := func(id uint64) models.TimeTracker {
+		return models.NewTimeTracker(id, time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC), time.Time{}, "code review")
+	}
+
+	testCases := []struct {
+		description string
+		// lastEventID picks the id sent as Last-Event-ID out of the published
+		// ones, -1 sends none and -2 an id of a previous process.
+		lastEventID int
+		query       string
+		expected    []string
+	}{
+		{
+			description: "when the client resumes from a known event",
+			lastEventID: 0,
+			expected:    []string{"updated 2", "stopped 3", "deleted 1"},
+		},
+		{
+			description: "when the stream is filtered by tracker",
+			lastEventID: 0,
+			query:       "?tracker_id=1",
+			expected:    []string{"deleted 1"},
+		},
+		{
+			description: "when the client resumes after a restart",
+			lastEventID: -2,
+			expected:    []string{"reset", "deleted 1"},
+		},
+		{
+			description: "when the client doesn't resume",
+			lastEventID: -1,
+			expected:    []string{"deleted 1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			broadcaster := broadcast.NewBroadcaster(8)
+			handler := NewEventHandler(broadcaster, logrus.New())
+			server := httptest.NewServer(http.HandlerFunc(handler.StreamEvents))
+			defer server.Close()
+
+			ids := make([]uint64, 0)
+			sub, _ := broadcaster.Subscribe(0, nil)
+			for _, event := range []models.TrackerEvent{
+				models.NewTrackerEvent(models.EventCreated, tracker(1)),
+				models.NewTrackerEvent(models.EventUpdated, tracker(2)),
+				models.NewTrackerEvent(models.EventStopped, tracker(3)),
+			} {
+				broadcaster.Publish(event)
+				ids = append(ids, (<-sub.Events()).ID)
+			}
+			sub.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+tc.query, nil)
+			g.Expect(err).ToNot(HaveOccurred())
+			switch {
+			case tc.lastEventID >= 0:
+				request.Header.Set("Last-Event-ID", strconv.FormatUint(ids[tc.lastEventID], 10))
+			case tc.lastEventID == -2:
+				request.Header.Set("Last-Event-ID", "1")
+			}
+
+			response, err := http.DefaultClient.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			defer response.Body.Close()
+
+			g.Expect(response.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(response.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+			// a live event once the replay is written
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				broadcaster.Publish(models.NewTrackerEvent(models.EventDeleted, tracker(1)))
+			}()
+
+			frames := readFrames(g, bufio.NewReader(response.Body), len(tc.expected))
+
+			actual := make([]string, 0, len(frames))
+			for _, frame := range frames {
+				g.Expect(frame).To(HaveKey("id"))
+				g.Expect(frame["data"]).To(ContainSubstring(`"type":"` + frame["event"] + `"`))
+
+				if frame["event"] == string(models.EventReset) {
+					g.Expect(frame["data"]).ToNot(ContainSubstring("tracker"))
+					actual = append(actual, frame["event"])
+
+					continue
+				}
+
+				id := strings.SplitN(strings.SplitN(frame["data"], `"id":`, 2)[1], ",", 2)[0]
+				actual = append(actual, frame["event"]+" "+id)
+			}
+
+			g.Expect(actual).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_EventHandler_StreamEvents_invalidLastEventID(t *testing.T) {
+	g := NewWithT(t)
+
+	handler := NewEventHandler(broadcast.NewBroadcaster(8), logrus.New())
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/tracker/events", nil)
+	request.Header.Set("Last-Event-ID", "latest")
+	recorder := httptest.NewRecorder()
+
+	handler.StreamEvents(recorder, request)
+
+	g.Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	body, _ := ioutil.ReadAll(recorder.Body)
+	g.Expect(body).To(BeEmpty())
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"pento/code-challenge/buildinfo"
+	"pento/code-challenge/logging"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (int, error)
+	ExpectedSchemaVersion() int
+	Stats() sql.DBStats
+}
+
+type HealthHandler struct {
+	checker    HealthChecker
+	debugToken string
+	startedAt  time.Time
+	logger     logrus.FieldLogger
+}
+
+func NewHealthHandler(checker HealthChecker, debugToken string, logger logrus.FieldLogger) *HealthHandler {
+	return &HealthHandler{
+		checker:    checker,
+		debugToken: debugToken,
+		startedAt:  time.Now(),
+		logger:     logger,
+	}
+}
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type migrationResponse struct {
+	Current  int    `json:"current"`
+	Expected int    `json:"expected"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+type poolResponse struct {
+	MaxOpenConnections int           `json:"max_open_connections"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"wait_count"`
+	WaitDuration       time.Duration `json:"wait_duration_ns"`
+	MaxIdleClosed      int64         `json:"max_idle_closed"`
+	MaxLifetimeClosed  int64         `json:"max_lifetime_closed"`
+}
+
+type statusResponse struct {
+	Build         buildinfo.Info    `json:"build"`
+	StartedAt     time.Time         `json:"started_at"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Pool          poolResponse      `json:"pool"`
+	Migration     migrationResponse `json:"migration"`
+}
+
+// Liveness only tells the process is serving requests.
+func (h HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	h.writeHealth(w, r, http.StatusOK, healthResponse{Status: "ok"})
+}
+
+// Readiness checks the database answers and runs the expected schema.
+func (h HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	status := http.StatusOK
+	response := healthResponse{
+		Status: "ok",
+		Checks: map[string]string{
+			"database": "ok",
+			"schema":   "ok",
+		},
+	}
+
+	if err := h.checker.Ping(ctx); err != nil {
+		h.log(r).Error(err)
+		status = http.StatusServiceUnavailable
+		response.Status = "unavailable"
+		response.Checks["database"] = "unreachable"
+		response.Checks["schema"] = "unknown"
+	} else if migration := h.migration(r.WithContext(ctx)); migration.Status != "ok" {
+		status = http.StatusServiceUnavailable
+		response.Status = "unavailable"
+		response.Checks["schema"] = migration.Status
+	}
+
+	h.writeHealth(w, r, status, response)
+}
+
+// Status reports build and runtime diagnostics, it requires the debug token.
+func (h HealthHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	stats := h.checker.Stats()
+
+	h.writeHealth(w, r, http.StatusOK, statusResponse{
+		Build:         buildinfo.Get(),
+		StartedAt:     h.startedAt,
+		UptimeSeconds: int64(time.Since(h.startedAt) / time.Second),
+		Pool: poolResponse{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDuration:       stats.WaitDuration,
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		},
+		Migration: h.migration(r.WithContext(ctx)),
+	})
+}
+
+func (h HealthHandler) migration(r *http.Request) migrationResponse {
+	response := migrationResponse{
+		Expected: h.checker.ExpectedSchemaVersion(),
+		Status:   "ok",
+	}
+
+	current, err := h.checker.SchemaVersion(r.Context())
+	if err != nil {
+		h.log(r).Error(err)
+		response.Status = "unknown"
+		response.Error = err.Error()
+
+		return response
+	}
+
+	response.Current = current
+	switch {
+	case current < response.Expected:
+		response.Status = "pending"
+	case current > response.Expected:
+		response.Status = "ahead"
+	}
+
+	return response
+}
+
+// authorized compares the bearer token in constant time, the endpoint stays
+// closed when no debug token is configured.
+func (h HealthHandler) authorized(r *http.Request) bool {
+	if h.debugToken == "" {
+		return false
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.debugToken)) == 1
+}
+
+func (h HealthHandler) log(r *http.Request) logrus.FieldLogger {
+	return logging.FromContext(r.Context(), h.logger)
+}
+
+func (h HealthHandler) writeHealth(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log(r).Error(err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if _, err := w.Write(response); err != nil {
+		h.log(r).Error(err)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pento/code-challenge/buildinfo"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeChecker answers the health checks with fixed values.
+type fakeChecker struct {
+	pingErr   error
+	schema    int
+	schemaErr error
+	stats     sql.DBStats
+}
+
+func (f fakeChecker) Ping(ctx context.Context) error {
+	return f.pingErr
+}
+
+func (f fakeChecker) SchemaVersion(ctx context.Context) (int, error) {
+	return f.schema, f.schemaErr
+}
+
+func (f fakeChecker) ExpectedSchemaVersion() int {
+	return 12
+}
+
+func (f fakeChecker) Stats() sql.DBStats {
+	return f.stats
+}
+
+func Test_HealthHandler_Liveness(t *testing.T) {
+	g := NewWithT(t)
+
+	handler := NewHealthHandler(fakeChecker{pingErr: errors.New("connection refused")}, "", logrus.New())
+
+	w := httptest.NewRecorder()
+	handler.Liveness(w, httptest.NewRequest("GET", "/healthz", nil))
+
+	var response healthResponse
+	g.Expect(w.Code).To(Equal(http.StatusOK), "should not depend on the database")
+	g.Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+	g.Expect(response.Status).To(Equal("ok"), "should report the process alive")
+}
+
+func Test_HealthHandler_Readiness(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		checker     fakeChecker
+		status      int
+		checks      map[string]string
+	}{
+		{
+			description: "when the database answers with the expected schema",
+			checker:     fakeChecker{schema: 12},
+			status:      http.StatusOK,
+			checks:      map[string]string{"database": "ok", "schema": "ok"},
+		},
+		{
+			description: "when the database ping fails",
+			checker:     fakeChecker{pingErr: errors.New("connection refused")},
+			status:      http.StatusServiceUnavailable,
+			checks:      map[string]string{"database": "unreachable", "schema": "unknown"},
+		},
+		{
+			description: "when migrations are pending",
+			checker:     fakeChecker{schema: 11},
+			status:      http.StatusServiceUnavailable,
+			checks:      map[string]string{"database": "ok", "schema": "pending"},
+		},
+		{
+			description: "when the schema version can't be read",
+			checker:     fakeChecker{schemaErr: errors.New("relation does not exist")},
+			status:      http.StatusServiceUnavailable,
+			checks:      map[string]string{"database": "ok", "schema": "unknown"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			handler := NewHealthHandler(tc.checker, "", logrus.New())
+
+			w := httptest.NewRecorder()
+			handler.Readiness(w, httptest.NewRequest("GET", "/readyz", nil))
+
+			var response healthResponse
+			g.Expect(w.Code).To(Equal(tc.status), "should tell whether the API can serve requests")
+			g.Expect(w.Header().Get("Cache-Control")).To(Equal("no-store"), "should not be cached")
+			g.Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			g.Expect(response.Checks).To(Equal(tc.checks), "should report each check")
+		})
+	}
+}
+
+func Test_HealthHandler_Status(t *testing.T) {
+
+	checker := fakeChecker{
+		schema: 11,
+		stats:  sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2},
+	}
+
+	testCases := []struct {
+		description   string
+		token         string
+		authorization string
+		status        int
+	}{
+		{
+			description:   "when the debug token is sent",
+			token:         "secret",
+			authorization: "Bearer secret",
+			status:        http.StatusOK,
+		},
+		{
+			description:   "when another token is sent",
+			token:         "secret",
+			authorization: "Bearer made-up",
+			status:        http.StatusUnauthorized,
+		},
+		{
+			description:   "when no debug token is configured",
+			authorization: "Bearer ",
+			status:        http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			handler := NewHealthHandler(checker, tc.token, logrus.New())
+
+			r := httptest.NewRequest("GET", "/debug/status", nil)
+			r.Header.Set("Authorization", tc.authorization)
+
+			w := httptest.NewRecorder()
+			handler.Status(w, r)
+
+			g.Expect(w.Code).To(Equal(tc.status), "should require the debug token")
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var response statusResponse
+			g.Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			g.Expect(response.Build).To(Equal(buildinfo.Get()), "should report the build")
+			g.Expect(response.Pool).To(Equal(poolResponse{
+				MaxOpenConnections: 10,
+				OpenConnections:    3,
+				InUse:              1,
+				Idle:               2,
+			}), "should report the pool statistics")
+			g.Expect(response.Migration).To(Equal(migrationResponse{
+				Current:  11,
+				Expected: 12,
+				Status:   "pending",
+			}), "should report the migration state")
+			g.Expect(response.StartedAt).ToNot(BeZero(), "should report the start time")
+		})
+	}
+}
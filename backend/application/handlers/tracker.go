@@ -3,16 +3,19 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
-	"log"
 	"net/http"
+	"pento/code-challenge/application/apierror"
 	"pento/code-challenge/domain/tracker/models"
 	"pento/code-challenge/domain/tracker/services"
+	"pento/code-challenge/logging"
 	"pento/code-challenge/utils"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
 )
 
 type TrackerService interface {
@@ -21,19 +24,25 @@ type TrackerService interface {
 	CreateTracker(ctx context.Context, params services.CreateTrackerParams) (models.TimeTracker, error)
 	UpdateTracker(ctx context.Context, params services.UpdateTrackerParams) (models.TimeTracker, error)
 	DeleteTracker(ctx context.Context, params services.DeleteTrackerParams) error
+	Batch(ctx context.Context, params services.BatchParams) ([]services.BatchResult, error)
+	ListOverlaps(ctx context.Context, params services.ListTimeTracker) ([]models.Overlap, error)
+	Heartbeat(ctx context.Context, id uint64) error
 }
 
 type TrackerHandler struct {
 	service TrackerService
+	logger  logrus.FieldLogger
 }
 
-func NewTrackerHandler(service TrackerService) *TrackerHandler {
+func NewTrackerHandler(service TrackerService, logger logrus.FieldLogger) *TrackerHandler {
 	return &TrackerHandler{
 		service: service,
+		logger:  logger,
 	}
 }
 
 type updateTimeTrackerRequest struct {
+	Start   time.Time `json:"start"`
 	End     time.Time `json:"end"`
 	Name    string    `json:"name"`
 	Version uint32    `json:"version"`
@@ -53,12 +62,37 @@ type TimeTrackerResponse struct {
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	Version   uint32     `json:"version"`
+	// Overlaps lists the sessions the tracker was stored overlapping with
+	// under the warn overlap policy.
+	Overlaps      []uint64          `json:"overlaps,omitempty"`
+	LastHeartbeat *time.Time        `json:"last_heartbeat,omitempty"`
+	AutoStopped   bool              `json:"auto_stopped"`
+	AutoStop      *AutoStopResponse `json:"auto_stop,omitempty"`
+}
+
+type AutoStopResponse struct {
+	Reason models.AutoStopReason `json:"reason"`
+	At     time.Time             `json:"at"`
 }
 
 type TimeTrackersResponse struct {
 	Trackers []TimeTrackerResponse `json:"trackers"`
 }
 
+type OverlapResponse struct {
+	Start    time.Time             `json:"start"`
+	End      *time.Time            `json:"end"`
+	Trackers []TimeTrackerResponse `json:"trackers"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	// Code tells apart the conflicts answered with 409.
+	Code     string                `json:"code,omitempty"`
+	Fields   []services.FieldError `json:"fields,omitempty"`
+	Overlaps []uint64              `json:"overlaps,omitempty"`
+}
+
 func (h TrackerHandler) GetTracker(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -66,25 +100,25 @@ func (h TrackerHandler) GetTracker(w http.ResponseWriter, r *http.Request) {
 	i, err := strconv.ParseUint(id, 10, 64)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		log.Println(err)
+		h.log(r).Warn(err)
 
 		return
 	}
 
-	Tracker, err := h.service.GetTracker(context.Background(), i)
+	Tracker, err := h.service.GetTracker(r.Context(), i)
 	if err != nil {
 		switch err {
 		default:
 			w.WriteHeader(http.StatusInternalServerError)
 		}
-		log.Println(err)
+		h.log(r).Error(err)
 		return
 	}
 
 	response, err := json.Marshal(fromDomain(Tracker))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err)
+		h.log(r).Error(err)
 
 		return
 	}
@@ -93,7 +127,7 @@ func (h TrackerHandler) GetTracker(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err)
+		h.log(r).Error(err)
 
 		return
 	}
@@ -111,20 +145,20 @@ func (h TrackerHandler) ListTrackers(w http.ResponseWriter, r *http.Request) {
 		startDate, err = utils.StrToTime(r.FormValue("start_date"))
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			log.Println(err)
+			h.log(r).Error(err)
 
 			return
 		}
 		endDate, err = utils.StrToTime(r.FormValue("end_date"))
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			log.Println(err)
+			h.log(r).Error(err)
 
 			return
 		}
 	}
 
-	trackers, err := h.service.ListTrackers(context.Background(), services.ListTimeTracker{
+	trackers, err := h.service.ListTrackers(r.Context(), services.ListTimeTracker{
 		Start: startDate,
 		End:   endDate,
 	})
@@ -133,14 +167,14 @@ func (h TrackerHandler) ListTrackers(w http.ResponseWriter, r *http.Request) {
 		default:
 			w.WriteHeader(http.StatusInternalServerError)
 		}
-		log.Println(err)
+		h.log(r).Error(err)
 		return
 	}
 
 	response, err := json.Marshal(fromDomainSlice(trackers))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err)
+		h.log(r).Error(err)
 
 		return
 	}
@@ -149,7 +183,7 @@ func (h TrackerHandler) ListTrackers(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err)
+		h.log(r).Error(err)
 
 		return
 	}
@@ -161,7 +195,7 @@ func (h TrackerHandler) CreateTracker(w http.ResponseWriter, r *http.Request) {
 	reqBody, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		log.Println(err)
+		h.log(r).Warn(err)
 
 		return
 	}
@@ -169,7 +203,7 @@ func (h TrackerHandler) CreateTracker(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(reqBody, &request)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		log.Println(err)
+		h.log(r).Warn(err)
 
 		return
 	}
@@ -179,9 +213,12 @@ func (h TrackerHandler) CreateTracker(w http.ResponseWriter, r *http.Request) {
 		Name:  request.Name,
 	}
 
-	Tracker, err := h.service.CreateTracker(context.Background(), params)
+	Tracker, err := h.service.CreateTracker(r.Context(), params)
 	if err != nil {
-		log.Println(err)
+		if h.writeDomainError(w, r, err) {
+			return
+		}
+		h.log(r).Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 
 		return
@@ -190,7 +227,7 @@ func (h TrackerHandler) CreateTracker(w http.ResponseWriter, r *http.Request) {
 	response, err := json.Marshal(fromDomain(Tracker))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err)
+		h.log(r).Error(err)
 
 		return
 	}
@@ -200,7 +237,7 @@ func (h TrackerHandler) CreateTracker(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(response)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err)
+		h.log(r).Error(err)
 
 		return
 	}
@@ -214,7 +251,7 @@ func (h TrackerHandler) UpdateTracker(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(paramID, 10, 64)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		log.Println(err)
+		h.log(r).Warn(err)
 
 		return
 	}
@@ -223,7 +260,7 @@ func (h TrackerHandler) UpdateTracker(w http.ResponseWriter, r *http.Request) {
 	reqBody, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		log.Println(err)
+		h.log(r).Warn(err)
 
 		return
 	}
@@ -231,7 +268,7 @@ func (h TrackerHandler) UpdateTracker(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(reqBody, &request)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		log.Println(err)
+		h.log(r).Warn(err)
 
 		return
 	}
@@ -239,22 +276,27 @@ func (h TrackerHandler) UpdateTracker(w http.ResponseWriter, r *http.Request) {
 	params := services.UpdateTrackerParams{
 		Version: request.Version,
 		Name:    request.Name,
+		Start:   request.Start,
 		End:     request.End,
 		ID:      id,
 	}
 
-	tracker, err := h.service.UpdateTracker(context.Background(), params)
+	tracker, err := h.service.UpdateTracker(r.Context(), params)
 	if err != nil {
+		if h.writeDomainError(w, r, err) {
+			return
+		}
+
 		switch err {
 		case services.ErrTrackerNotFound:
 			w.WriteHeader(http.StatusNotFound)
-			log.Println(err)
+			h.log(r).Warn(err)
 		case services.ErrWrongVersion:
 			w.WriteHeader(http.StatusConflict)
-			log.Println(err)
+			h.log(r).Warn(err)
 		default:
 			w.WriteHeader(http.StatusInternalServerError)
-			log.Println(err)
+			h.log(r).Error(err)
 		}
 
 		return
@@ -263,7 +305,7 @@ func (h TrackerHandler) UpdateTracker(w http.ResponseWriter, r *http.Request) {
 	response, err := json.Marshal(fromDomain(tracker))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err)
+		h.log(r).Error(err)
 
 		return
 	}
@@ -272,7 +314,7 @@ func (h TrackerHandler) UpdateTracker(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(response)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err)
+		h.log(r).Error(err)
 	}
 }
 
@@ -284,17 +326,21 @@ func (h TrackerHandler) DeleteTracker(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(paramsID, 10, 64)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		log.Println(err)
+		h.log(r).Warn(err)
 
 		return
 	}
 
-	err = h.service.DeleteTracker(context.Background(), services.DeleteTrackerParams{
+	err = h.service.DeleteTracker(r.Context(), services.DeleteTrackerParams{
 		ID: id,
 	})
 	if err != nil {
+		if h.writeDomainError(w, r, err) {
+			return
+		}
+
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err)
+		h.log(r).Error(err)
 
 		return
 	}
@@ -302,6 +348,143 @@ func (h TrackerHandler) DeleteTracker(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h TrackerHandler) ListOverlaps(w http.ResponseWriter, r *http.Request) {
+
+	var params services.ListTimeTracker
+	var err error
+
+	if r.FormValue("start_date") != "" || r.FormValue("end_date") != "" {
+		params.Start, err = utils.StrToTime(r.FormValue("start_date"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.log(r).Warn(err)
+
+			return
+		}
+		params.End, err = utils.StrToTime(r.FormValue("end_date"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.log(r).Warn(err)
+
+			return
+		}
+	}
+
+	overlaps, err := h.service.ListOverlaps(r.Context(), params)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log(r).Error(err)
+
+		return
+	}
+
+	responses := make([]OverlapResponse, 0, len(overlaps))
+	for _, overlap := range overlaps {
+		response := OverlapResponse{
+			Start:    overlap.Start,
+			Trackers: fromDomainSlice([]models.TimeTracker{overlap.First, overlap.Second}),
+		}
+		if !overlap.End.IsZero() {
+			end := overlap.End
+			response.End = &end
+		}
+
+		responses = append(responses, response)
+	}
+
+	response, err := json.Marshal(responses)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log(r).Error(err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	_, err = w.Write(response)
+	if err != nil {
+		h.log(r).Error(err)
+	}
+}
+
+func (h TrackerHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	paramID := vars["id"]
+
+	id, err := strconv.ParseUint(paramID, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log(r).Warn(err)
+
+		return
+	}
+
+	err = h.service.Heartbeat(r.Context(), id)
+	if err != nil {
+		switch err {
+		case services.ErrTrackerNotFound:
+			w.WriteHeader(http.StatusNotFound)
+			h.log(r).Warn(err)
+		case services.ErrNotRunning:
+			w.WriteHeader(http.StatusConflict)
+			h.log(r).Warn(err)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			h.log(r).Error(err)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeDomainError answers 422 with the rejected fields for validation errors,
+// 409 with the conflicting sessions for overlaps and 409 when another tracker
+// is running or the period is locked, it reports whether err was one of them.
+func (h TrackerHandler) writeDomainError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var (
+		validationErr *services.ValidationError
+		overlapErr    *services.OverlapError
+		body          = errorResponse{Error: err.Error(), Code: apierror.Code(err)}
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		body.Fields = validationErr.Fields
+	case errors.As(err, &overlapErr):
+		body.Overlaps = overlapErr.IDs
+	case errors.Is(err, services.ErrOverlap), errors.Is(err, services.ErrTrackerRunning), errors.Is(err, services.ErrPeriodLocked):
+	default:
+		return false
+	}
+
+	status := apierror.Status(err)
+
+	h.log(r).Warn(err)
+
+	response, err := json.Marshal(body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log(r).Error(err)
+
+		return true
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	if _, err := w.Write(response); err != nil {
+		h.log(r).Error(err)
+	}
+
+	return true
+}
+
+func (h TrackerHandler) log(r *http.Request) logrus.FieldLogger {
+	return logging.FromContext(r.Context(), h.logger)
+}
+
 func fromDomain(tracker models.TimeTracker) TimeTrackerResponse {
 
 	var end *time.Time = nil
@@ -310,7 +493,7 @@ func fromDomain(tracker models.TimeTracker) TimeTrackerResponse {
 		end = &tracker.End
 	}
 
-	return TimeTrackerResponse{
+	response := TimeTrackerResponse{
 		ID:        &tracker.ID,
 		Start:     &tracker.Start,
 		End:       end,
@@ -318,7 +501,22 @@ func fromDomain(tracker models.TimeTracker) TimeTrackerResponse {
 		CreatedAt: tracker.Meta.GetCreatedAt(),
 		UpdatedAt: tracker.Meta.GetUpdatedAt(),
 		Version:   tracker.Meta.GetVersion(),
+		Overlaps:  tracker.Overlapping,
 	}
+
+	if !tracker.LastHeartbeat.IsZero() {
+		response.LastHeartbeat = &tracker.LastHeartbeat
+	}
+
+	if tracker.AutoStop != nil {
+		response.AutoStopped = true
+		response.AutoStop = &AutoStopResponse{
+			Reason: tracker.AutoStop.Reason,
+			At:     tracker.AutoStop.At,
+		}
+	}
+
+	return response
 }
 
 func fromDomainSlice(Trackers []models.TimeTracker) []TimeTrackerResponse {
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const collectTimeout = 2 * time.Second
+
+type RunningTrackerCounter interface {
+	CountRunningTrackers(ctx context.Context) (int, error)
+}
+
+// runningTrackersCollector reads the number of running trackers on every
+// scrape instead of tracking it in process, so it holds across instances.
+type runningTrackersCollector struct {
+	counter RunningTrackerCounter
+	desc    *prometheus.Desc
+}
+
+func NewRunningTrackersCollector(counter RunningTrackerCounter) prometheus.Collector {
+	return runningTrackersCollector{
+		counter: counter,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "trackers", "running"),
+			"Trackers that have been started and not stopped.",
+			nil, nil,
+		),
+	}
+}
+
+func (c runningTrackersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c runningTrackersCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	running, err := c.counter.CountRunningTrackers(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(running))
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	_ "github.com/jackc/pgx/stdlib"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Metrics_Middleware(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		method      string
+		path        string
+		expected    string
+		unexpected  string
+	}{
+		{
+			description: "when a route with a path parameter is served",
+			method:      "GET",
+			path:        "/api/v1/tracker/42",
+			expected:    `tracker_http_requests_total{code="200",method="GET",route="/api/v1/tracker/{id}"} 1`,
+			unexpected:  "/api/v1/tracker/42",
+		},
+		{
+			description: "when the handler answers an error status",
+			method:      "DELETE",
+			path:        "/api/v1/tracker/42",
+			expected:    `tracker_http_requests_total{code="409",method="DELETE",route="/api/v1/tracker/{id}"} 1`,
+		},
+		{
+			description: "when no route matches",
+			method:      "GET",
+			path:        "/made/up",
+			expected:    `tracker_http_requests_total{code="404",method="GET",route="unmatched"} 1`,
+			unexpected:  "/made/up",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// sql.Open doesn't connect, the pool statistics stay empty
+			pool, err := sql.Open("pgx", "postgres://localhost/made-up")
+			g.Expect(err).ToNot(HaveOccurred())
+			defer pool.Close()
+
+			metrics := New(pool, "made-up")
+
+			router := mux.NewRouter()
+			router.Use(metrics.Middleware)
+			router.HandleFunc("/api/v1/tracker/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+			router.HandleFunc("/api/v1/tracker/{id}", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusConflict)
+			}).Methods("DELETE")
+			router.NotFoundHandler = metrics.Middleware(http.NotFoundHandler())
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tc.method, tc.path, nil))
+
+			w := httptest.NewRecorder()
+			metrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+			body, err := ioutil.ReadAll(w.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(w.Code).To(Equal(http.StatusOK), "should serve the metrics")
+			g.Expect(string(body)).To(ContainSubstring(tc.expected), "should count the request by route template and status")
+			g.Expect(string(body)).To(ContainSubstring("tracker_http_request_duration_seconds_count"), "should expose the latency histogram")
+			if tc.unexpected != "" {
+				g.Expect(string(body)).ToNot(ContainSubstring(tc.unexpected), "should not label the raw path")
+			}
+		})
+	}
+}
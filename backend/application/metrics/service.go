@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"time"
+)
+
+type TrackerService interface {
+	GetTracker(ctx context.Context, id uint64) (models.TimeTracker, error)
+	ListTrackers(ctx context.Context, params services.ListTimeTracker) ([]models.TimeTracker, error)
+	CreateTracker(ctx context.Context, params services.CreateTrackerParams) (models.TimeTracker, error)
+	UpdateTracker(ctx context.Context, params services.UpdateTrackerParams) (models.TimeTracker, error)
+	DeleteTracker(ctx context.Context, params services.DeleteTrackerParams) error
+	Batch(ctx context.Context, params services.BatchParams) ([]services.BatchResult, error)
+	ListOverlaps(ctx context.Context, params services.ListTimeTracker) ([]models.Overlap, error)
+	Heartbeat(ctx context.Context, id uint64) error
+}
+
+// InstrumentedTrackerService times every TrackerService operation and counts
+// version conflicts.
+type InstrumentedTrackerService struct {
+	next    TrackerService
+	metrics *Metrics
+}
+
+func (m *Metrics) InstrumentTrackerService(next TrackerService) InstrumentedTrackerService {
+	return InstrumentedTrackerService{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (s InstrumentedTrackerService) GetTracker(ctx context.Context, id uint64) (models.TimeTracker, error) {
+	start := time.Now()
+
+	tracker, err := s.next.GetTracker(ctx, id)
+	s.observe("get_tracker", start, err)
+
+	return tracker, err
+}
+
+func (s InstrumentedTrackerService) ListTrackers(ctx context.Context, params services.ListTimeTracker) ([]models.TimeTracker, error) {
+	start := time.Now()
+
+	trackers, err := s.next.ListTrackers(ctx, params)
+	s.observe("list_trackers", start, err)
+
+	return trackers, err
+}
+
+func (s InstrumentedTrackerService) CreateTracker(ctx context.Context, params services.CreateTrackerParams) (models.TimeTracker, error) {
+	start := time.Now()
+
+	tracker, err := s.next.CreateTracker(ctx, params)
+	s.observe("create_tracker", start, err)
+
+	return tracker, err
+}
+
+func (s InstrumentedTrackerService) UpdateTracker(ctx context.Context, params services.UpdateTrackerParams) (models.TimeTracker, error) {
+	start := time.Now()
+
+	tracker, err := s.next.UpdateTracker(ctx, params)
+	s.observe("update_tracker", start, err)
+
+	return tracker, err
+}
+
+func (s InstrumentedTrackerService) DeleteTracker(ctx context.Context, params services.DeleteTrackerParams) error {
+	start := time.Now()
+
+	err := s.next.DeleteTracker(ctx, params)
+	s.observe("delete_tracker", start, err)
+
+	return err
+}
+
+func (s InstrumentedTrackerService) Batch(ctx context.Context, params services.BatchParams) ([]services.BatchResult, error) {
+	start := time.Now()
+
+	results, err := s.next.Batch(ctx, params)
+	s.observe("batch", start, err)
+
+	for _, result := range results {
+		if errors.Is(result.Err, services.ErrWrongVersion) {
+			s.metrics.versionConflicts.Inc()
+		}
+	}
+
+	return results, err
+}
+
+func (s InstrumentedTrackerService) ListOverlaps(ctx context.Context, params services.ListTimeTracker) ([]models.Overlap, error) {
+	start := time.Now()
+
+	overlaps, err := s.next.ListOverlaps(ctx, params)
+	s.observe("list_overlaps", start, err)
+
+	return overlaps, err
+}
+
+func (s InstrumentedTrackerService) Heartbeat(ctx context.Context, id uint64) error {
+	start := time.Now()
+
+	err := s.next.Heartbeat(ctx, id)
+	s.observe("heartbeat", start, err)
+
+	return err
+}
+
+func (s InstrumentedTrackerService) observe(operation string, start time.Time, err error) {
+	if errors.Is(err, services.ErrWrongVersion) {
+		s.metrics.versionConflicts.Inc()
+	}
+
+	s.metrics.operationDuration.WithLabelValues(operation, outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, services.ErrTrackerNotFound):
+		return "not_found"
+	case errors.Is(err, services.ErrWrongVersion):
+		return "conflict"
+	case errors.Is(err, services.ErrValidation):
+		return "invalid"
+	case errors.Is(err, services.ErrOverlap):
+		return "overlap"
+	case errors.Is(err, services.ErrTrackerRunning):
+		return "already_running"
+	case errors.Is(err, services.ErrNotRunning):
+		return "not_running"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"net/http/httptest"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// conflictingService fails every update with a version conflict.
+type conflictingService struct {
+	TrackerService
+}
+
+func (s conflictingService) UpdateTracker(ctx context.Context, params services.UpdateTrackerParams) (models.TimeTracker, error) {
+	return models.TimeTracker{}, services.ErrWrongVersion
+}
+
+func Test_InstrumentedTrackerService_versionConflicts(t *testing.T) {
+	g := NewWithT(t)
+
+	pool, err := sql.Open("pgx", "postgres://localhost/made-up")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer pool.Close()
+
+	metrics := New(pool, "made-up")
+	service := metrics.InstrumentTrackerService(conflictingService{})
+
+	_, err = service.UpdateTracker(context.Background(), services.UpdateTrackerParams{ID: 1, Version: 1})
+	g.Expect(err).To(MatchError(services.ErrWrongVersion), "should pass the error on")
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := ioutil.ReadAll(w.Body)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(body)).To(ContainSubstring("tracker_service_version_conflicts_total 1"), "should count the conflict")
+	g.Expect(string(body)).To(ContainSubstring(`tracker_service_operation_duration_seconds_count{operation="update_tracker",outcome="conflict"} 1`), "should time the operation by outcome")
+}
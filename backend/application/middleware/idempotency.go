@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"pento/code-challenge/domain/idempotency/models"
+	"pento/code-challenge/logging"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	recordTimeout            = 5 * time.Second
+)
+
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, key, requestHash string) (models.Record, bool, error)
+	Complete(ctx context.Context, record models.Record) error
+	Release(ctx context.Context, key string) error
+}
+
+type Idempotency struct {
+	store  IdempotencyStore
+	logger logrus.FieldLogger
+}
+
+func NewIdempotency(store IdempotencyStore, logger logrus.FieldLogger) *Idempotency {
+	return &Idempotency{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Handler replays the stored response of a request carrying an already used
+// Idempotency-Key. Reusing a key with a different request is rejected with
+// 422 and a key whose request is still in flight with 409. Server errors and
+// panics are not stored so the client can retry with the same key.
+func (m Idempotency) Handler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			w.WriteHeader(http.StatusBadRequest)
+			m.log(r).Warn("idempotency key too long")
+
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			m.log(r).Warn(err)
+
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		record, reserved, err := m.store.Reserve(r.Context(), key, requestHash(r, body))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			m.log(r).Error(err)
+
+			return
+		}
+
+		if !reserved {
+			m.replay(w, r, record, body)
+
+			return
+		}
+
+		// a panicking handler must not keep the key reserved until it expires
+		defer func() {
+			if p := recover(); p != nil {
+				m.release(r, key)
+				panic(p)
+			}
+		}()
+
+		recorder := newResponseRecorder(w)
+		next(recorder, r)
+
+		if recorder.status >= http.StatusInternalServerError {
+			m.release(r, key)
+
+			return
+		}
+
+		// the outcome is recorded even when the client went away, that is
+		// precisely when it will retry.
+		ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+		defer cancel()
+
+		record.StatusCode = recorder.status
+		record.ContentType = recorder.Header().Get("Content-Type")
+		record.Body = recorder.body.Bytes()
+
+		if err := m.store.Complete(ctx, record); err != nil {
+			m.log(r).Error(err)
+		}
+	}
+}
+
+func (m Idempotency) release(r *http.Request, key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+
+	if err := m.store.Release(ctx, key); err != nil {
+		m.log(r).Error(err)
+	}
+}
+
+func (m Idempotency) log(r *http.Request) logrus.FieldLogger {
+	return logging.FromContext(r.Context(), m.logger)
+}
+
+func (m Idempotency) replay(w http.ResponseWriter, r *http.Request, record models.Record, body []byte) {
+	if record.RequestHash != requestHash(r, body) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		m.log(r).Warnf("idempotency key %q reused with a different request", record.Key)
+
+		return
+	}
+
+	if !record.Completed {
+		w.WriteHeader(http.StatusConflict)
+		m.log(r).Warnf("idempotency key %q is still being processed", record.Key)
+
+		return
+	}
+
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+
+	_, err := w.Write(record.Body)
+	if err != nil {
+		m.log(r).Error(err)
+	}
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(r.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+	}
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(body []byte) (int, error) {
+	r.body.Write(body)
+
+	return r.ResponseWriter.Write(body)
+}
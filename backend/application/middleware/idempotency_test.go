@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"pento/code-challenge/domain/idempotency/models"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// memoryIdempotencyStore keeps the records in memory.
+type memoryIdempotencyStore struct {
+	records map[string]models.Record
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]models.Record)}
+}
+
+func (m *memoryIdempotencyStore) Reserve(ctx context.Context, key, requestHash string) (models.Record, bool, error) {
+	if record, ok := m.records[key]; ok {
+		return record, false, nil
+	}
+
+	record := models.Record{Key: key, RequestHash: requestHash}
+	m.records[key] = record
+
+	return record, true, nil
+}
+
+func (m *memoryIdempotencyStore) Complete(ctx context.Context, record models.Record) error {
+	record.Completed = true
+	m.records[record.Key] = record
+
+	return nil
+}
+
+func (m *memoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	delete(m.records, key)
+
+	return nil
+}
+
+func idempotentRequest(key, body string) *http.Request {
+	r := httptest.NewRequest("POST", "/api/v1/tracker", strings.NewReader(body))
+	r.Header.Set(IdempotencyKeyHeader, key)
+
+	return r
+}
+
+func Test_Idempotency_Handler(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		body        string
+		inFlight    bool
+		status      int
+		replayed    bool
+		calls       int
+	}{
+		{
+			description: "when the same request is retried",
+			body:        `{"name":"a"}`,
+			status:      http.StatusCreated,
+			replayed:    true,
+			calls:       1,
+		},
+		{
+			description: "when the key is reused with a different request",
+			body:        `{"name":"b"}`,
+			status:      http.StatusUnprocessableEntity,
+			calls:       1,
+		},
+		{
+			description: "when the first request is still in flight",
+			body:        `{"name":"a"}`,
+			inFlight:    true,
+			status:      http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			calls := 0
+			store := newMemoryIdempotencyStore()
+			handler := NewIdempotency(store, logrus.New()).Handler(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id":1}`))
+			})
+
+			if tc.inFlight {
+				_, _, _ = store.Reserve(context.Background(), "key", requestHash(idempotentRequest("key", tc.body), []byte(tc.body)))
+			} else {
+				handler(httptest.NewRecorder(), idempotentRequest("key", `{"name":"a"}`))
+			}
+
+			w := httptest.NewRecorder()
+			handler(w, idempotentRequest("key", tc.body))
+
+			g.Expect(w.Code).To(Equal(tc.status), "should answer the retry")
+			g.Expect(calls).To(Equal(tc.calls), "should run the handler once at most")
+
+			if tc.replayed {
+				body, _ := ioutil.ReadAll(w.Body)
+				g.Expect(string(body)).To(Equal(`{"id":1}`), "should replay the stored body")
+				g.Expect(w.Header().Get("Content-Type")).To(Equal("application/json"), "should replay the content type")
+				g.Expect(w.Header().Get(IdempotentReplayedHeader)).To(Equal("true"), "should flag the replay")
+			}
+		})
+	}
+}
+
+func Test_Idempotency_Handler_release(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		handler     http.HandlerFunc
+		panics      bool
+	}{
+		{
+			description: "when the handler fails with a server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+		},
+		{
+			description: "when the handler panics",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				panic("made-up")
+			},
+			panics: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			store := newMemoryIdempotencyStore()
+			handler := NewIdempotency(store, logrus.New()).Handler(tc.handler)
+
+			serve := func() {
+				handler(httptest.NewRecorder(), idempotentRequest("key", `{"name":"a"}`))
+			}
+
+			if tc.panics {
+				g.Expect(serve).To(Panic(), "should let the panic through")
+			} else {
+				serve()
+			}
+
+			g.Expect(store.records).ToNot(HaveKey("key"), "should release the key for a retry")
+		})
+	}
+}
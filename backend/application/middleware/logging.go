@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"pento/code-challenge/logging"
+	"regexp"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+type Logging struct {
+	logger logrus.FieldLogger
+}
+
+func NewLogging(logger logrus.FieldLogger) *Logging {
+	return &Logging{
+		logger: logger,
+	}
+}
+
+// Middleware accepts the caller X-Request-ID or generates one, echoes it in
+// the response, attaches a logger carrying it to the request context and
+// writes one access log line per request.
+func (l Logging) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		logger := l.logger.WithField("request_id", requestID)
+		recorder := &accessRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r.WithContext(logging.WithLogger(r.Context(), logger)))
+
+		route := ""
+		if current := mux.CurrentRoute(r); current != nil {
+			route, _ = current.GetPathTemplate()
+		}
+
+		logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"route":       route,
+			"status":      recorder.status,
+			"bytes":       recorder.bytes,
+			"duration_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"remote_addr": r.RemoteAddr,
+			"user_agent":  r.UserAgent(),
+		}).Info("request completed")
+	})
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(id)
+}
+
+type accessRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *accessRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *accessRecorder) Write(body []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(body)
+	r.bytes += n
+
+	return n, err
+}
+
+func (r *accessRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pento/code-challenge/logging"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func Test_Logging_Middleware(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		requestID   string
+		propagated  bool
+	}{
+		{
+			description: "when the caller sends a request id",
+			requestID:   "caller-id.42",
+			propagated:  true,
+		},
+		{
+			description: "when the caller sends no request id",
+		},
+		{
+			description: "when the caller sends an invalid request id",
+			requestID:   "not a valid id\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			logger, hook := test.NewNullLogger()
+
+			handler := NewLogging(logger).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logging.FromContext(r.Context(), logrus.New()).Info("handled")
+				w.WriteHeader(http.StatusCreated)
+			}))
+
+			r := httptest.NewRequest("POST", "/api/v1/tracker", nil)
+			if tc.requestID != "" {
+				r.Header.Set(RequestIDHeader, tc.requestID)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			if tc.propagated {
+				g.Expect(requestID).To(Equal(tc.requestID), "should keep the caller request id")
+			} else {
+				g.Expect(requestID).To(MatchRegexp(`^[0-9a-f]{32}$`), "should generate a request id")
+			}
+
+			entries := hook.AllEntries()
+			g.Expect(entries).To(HaveLen(2), "should log from the handler and the access log")
+			g.Expect(entries[0].Message).To(Equal("handled"), "should hand the logger to the handler")
+			g.Expect(entries[0].Data["request_id"]).To(Equal(requestID), "should attach the request id to the context logger")
+			g.Expect(entries[1].Message).To(Equal("request completed"), "should write the access log")
+			g.Expect(entries[1].Data["request_id"]).To(Equal(requestID), "should attach the request id to the access log")
+			g.Expect(entries[1].Data["status"]).To(Equal(http.StatusCreated), "should log the status")
+		})
+	}
+}
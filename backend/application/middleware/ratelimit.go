@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"pento/code-challenge/logging"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+
+	RouteGroupRead   = "read"
+	RouteGroupWrite  = "write"
+	RouteGroupEvents = "events"
+)
+
+// Limit is a token bucket refilled with Requests tokens every Period and
+// holding at most Burst tokens.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitBackend keeps the buckets, implementations backed by a shared
+// store let several API instances enforce the same limits.
+type RateLimitBackend interface {
+	Take(ctx context.Context, key string, limit Limit) (RateLimitResult, error)
+}
+
+// ClientAddress finds the address of the client behind a request. Header is
+// only read on requests coming from one of the TrustedProxies, the connection
+// address is used otherwise.
+type ClientAddress struct {
+	Header         string
+	TrustedProxies []*net.IPNet
+}
+
+type RateLimiter struct {
+	backend  RateLimitBackend
+	limits   map[string]Limit
+	classify func(r *http.Request) string
+	clients  ClientAddress
+	logger   logrus.FieldLogger
+}
+
+func NewRateLimiter(backend RateLimitBackend, limits map[string]Limit, logger logrus.FieldLogger) *RateLimiter {
+	return &RateLimiter{
+		backend:  backend,
+		limits:   limits,
+		classify: routeGroup,
+		logger:   logger,
+	}
+}
+
+// WithClientAddress keys the clients on the address forwarded by trusted
+// proxies.
+func (l *RateLimiter) WithClientAddress(clients ClientAddress) *RateLimiter {
+	l.clients = clients
+
+	return l
+}
+
+// Middleware throttles requests per route group and client. Requests of a
+// group without a configured limit are not throttled, and backend failures
+// let the request through.
+func (l RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		group := l.classify(r)
+
+		limit, ok := l.limits[group]
+		if !ok {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		result, err := l.backend.Take(r.Context(), group+":"+l.clients.key(r), limit)
+		if err != nil {
+			l.log(r).Error(err)
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		w.Header().Set(RateLimitResetHeader, seconds(result.Reset))
+
+		if !result.Allowed {
+			w.Header().Set(RetryAfterHeader, seconds(result.RetryAfter))
+			w.WriteHeader(http.StatusTooManyRequests)
+			l.log(r).Warnf("rate limit exceeded for %s on %s", l.clients.key(r), group)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l RateLimiter) log(r *http.Request) logrus.FieldLogger {
+	return logging.FromContext(r.Context(), l.logger)
+}
+
+func routeGroup(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil && template == "/api/v1/tracker/events" {
+			return RouteGroupEvents
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RouteGroupRead
+	default:
+		return RouteGroupWrite
+	}
+}
+
+// key identifies the caller by its IP address. The Authorization header
+// isn't verified by the API, keying on it would hand a fresh bucket to every
+// made up value.
+func (c ClientAddress) key(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if c.Header == "" || !c.trusted(net.ParseIP(host)) {
+		return "ip:" + host
+	}
+
+	// proxies append the address they got the request from, the rightmost
+	// entry not added by a trusted proxy is the client.
+	forwarded := strings.Split(r.Header.Get(c.Header), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+
+		host = ip.String()
+		if !c.trusted(ip) {
+			break
+		}
+	}
+
+	return "ip:" + host
+}
+
+func (c ClientAddress) trusted(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
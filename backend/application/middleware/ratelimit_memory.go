@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	sweepInterval = time.Minute
+	// maxBuckets bounds the memory used by clients, the least recently used
+	// bucket is evicted beyond it.
+	maxBuckets = 100000
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time
+}
+
+// MemoryRateLimitBackend keeps token buckets in process memory, limits are
+// enforced per API instance.
+type MemoryRateLimitBackend struct {
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	lastSweep  time.Time
+	maxBuckets int
+}
+
+func NewMemoryRateLimitBackend() *MemoryRateLimitBackend {
+	return &MemoryRateLimitBackend{
+		buckets:    make(map[string]*bucket),
+		lastSweep:  time.Now(),
+		maxBuckets: maxBuckets,
+	}
+}
+
+func (b *MemoryRateLimitBackend) Take(ctx context.Context, key string, limit Limit) (RateLimitResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	rate := limit.rate()
+	burst := float64(limit.Burst)
+
+	b.sweep(now, false)
+
+	current, ok := b.buckets[key]
+	if !ok {
+		if len(b.buckets) >= b.maxBuckets {
+			b.sweep(now, true)
+		}
+		if len(b.buckets) >= b.maxBuckets {
+			b.evictIdlest()
+		}
+
+		current = &bucket{tokens: burst, last: now}
+		b.buckets[key] = current
+	}
+
+	current.tokens = math.Min(burst, current.tokens+now.Sub(current.last).Seconds()*rate)
+	current.last = now
+
+	result := RateLimitResult{
+		Limit: limit.Burst,
+	}
+
+	if current.tokens >= 1 {
+		current.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = duration((1 - current.tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(current.tokens))
+	result.Reset = duration((burst - current.tokens) / rate)
+	current.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// sweep drops the buckets that have refilled completely, they are equivalent
+// to a new bucket. It runs every sweepInterval unless forced.
+func (b *MemoryRateLimitBackend) sweep(now time.Time, force bool) {
+	if !force && now.Sub(b.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, current := range b.buckets {
+		if now.After(current.full) {
+			delete(b.buckets, key)
+		}
+	}
+
+	b.lastSweep = now
+}
+
+// evictIdlest drops the bucket left unused the longest.
+func (b *MemoryRateLimitBackend) evictIdlest() {
+	var (
+		idlest string
+		last   time.Time
+	)
+
+	for key, current := range b.buckets {
+		if idlest == "" || current.last.Before(last) {
+			idlest, last = key, current.last
+		}
+	}
+
+	delete(b.buckets, idlest)
+}
+
+func duration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
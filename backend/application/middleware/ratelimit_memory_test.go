@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_MemoryRateLimitBackend_Take(t *testing.T) {
+
+	type testInput struct {
+		limit    Limit
+		requests int
+	}
+
+	type testExpectation struct {
+		allowed   bool
+		remaining int
+	}
+
+	testCases := []struct {
+		description string
+		input       testInput
+		expected    testExpectation
+	}{
+		{
+			description: "when the bucket still has tokens",
+			input: testInput{
+				limit:    Limit{Requests: 1, Period: time.Hour, Burst: 3},
+				requests: 2,
+			},
+			expected: testExpectation{
+				allowed:   true,
+				remaining: 1,
+			},
+		},
+		{
+			description: "when the bucket is empty",
+			input: testInput{
+				limit:    Limit{Requests: 1, Period: time.Hour, Burst: 3},
+				requests: 4,
+			},
+			expected: testExpectation{
+				allowed:   false,
+				remaining: 0,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			backend := NewMemoryRateLimitBackend()
+
+			var (
+				result RateLimitResult
+				err    error
+			)
+			for i := 0; i < tc.input.requests; i++ {
+				result, err = backend.Take(context.TODO(), "key", tc.input.limit)
+				g.Expect(err).ToNot(HaveOccurred(), "should not return an error")
+			}
+
+			g.Expect(result.Allowed).To(Equal(tc.expected.allowed), "should be the same outcome")
+			g.Expect(result.Remaining).To(Equal(tc.expected.remaining), "should be the same remaining tokens")
+			g.Expect(result.Limit).To(Equal(tc.input.limit.Burst), "should report the burst as limit")
+			if !tc.expected.allowed {
+				g.Expect(result.RetryAfter).To(BeNumerically(">", 0), "should tell when to retry")
+			}
+		})
+	}
+}
+
+func Test_MemoryRateLimitBackend_Take_evictsIdlestBucket(t *testing.T) {
+	g := NewWithT(t)
+
+	limit := Limit{Requests: 1, Period: time.Hour, Burst: 1}
+
+	backend := NewMemoryRateLimitBackend()
+	backend.maxBuckets = 2
+
+	for _, key := range []string{"first", "second", "third"} {
+		result, err := backend.Take(context.TODO(), key, limit)
+		g.Expect(err).ToNot(HaveOccurred(), "should not return an error")
+		g.Expect(result.Allowed).To(BeTrue(), "should allow the first request of a client")
+	}
+
+	g.Expect(backend.buckets).To(HaveLen(2), "should not exceed the bucket limit")
+	g.Expect(backend.buckets).ToNot(HaveKey("first"), "should evict the idlest bucket")
+}
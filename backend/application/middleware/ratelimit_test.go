@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_ClientAddress_key(t *testing.T) {
+
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	forwarded := ClientAddress{Header: "X-Forwarded-For", TrustedProxies: []*net.IPNet{proxies}}
+
+	testCases := []struct {
+		description   string
+		clients       ClientAddress
+		remoteAddr    string
+		authorization string
+		forwardedFor  string
+		expected      string
+	}{
+		{
+			description: "when the request has no credentials",
+			remoteAddr:  "10.0.0.1:4242",
+			expected:    "ip:10.0.0.1",
+		},
+		{
+			description:   "when the request has credentials",
+			remoteAddr:    "10.0.0.1:4343",
+			authorization: "Bearer made-up",
+			expected:      "ip:10.0.0.1",
+		},
+		{
+			description:  "when the forwarded header isn't configured",
+			remoteAddr:   "10.0.0.1:4242",
+			forwardedFor: "203.0.113.7",
+			expected:     "ip:10.0.0.1",
+		},
+		{
+			description:  "when a trusted proxy forwards the request",
+			clients:      forwarded,
+			remoteAddr:   "10.0.0.1:4242",
+			forwardedFor: "198.51.100.1, 203.0.113.7, 10.0.0.2",
+			expected:     "ip:203.0.113.7",
+		},
+		{
+			description:  "when an untrusted peer sets the header",
+			clients:      forwarded,
+			remoteAddr:   "192.0.2.1:4242",
+			forwardedFor: "203.0.113.7",
+			expected:     "ip:192.0.2.1",
+		},
+		{
+			description: "when a trusted proxy forwards no header",
+			clients:     forwarded,
+			remoteAddr:  "10.0.0.1:4242",
+			expected:    "ip:10.0.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := httptest.NewRequest("GET", "/api/v1/tracker", nil)
+			r.RemoteAddr = tc.remoteAddr
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+			if tc.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			}
+
+			g.Expect(tc.clients.key(r)).To(Equal(tc.expected), "should key on the client address")
+		})
+	}
+}
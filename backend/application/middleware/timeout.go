@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type Timeout struct {
+	timeouts map[string]time.Duration
+	classify func(r *http.Request) string
+}
+
+func NewTimeout(timeouts map[string]time.Duration) *Timeout {
+	return &Timeout{
+		timeouts: timeouts,
+		classify: routeGroup,
+	}
+}
+
+// Middleware bounds the request context with the deadline configured for the
+// route group, groups without a positive timeout are left unbounded.
+func (t Timeout) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := t.timeouts[t.classify(r)]
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pento/code-challenge/application/apierror"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Timeout_Middleware(t *testing.T) {
+
+	// slow waits for the request context like a store query does and answers
+	// with the status of the error it gets.
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			w.WriteHeader(apierror.Status(r.Context().Err()))
+		case <-time.After(100 * time.Millisecond):
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+
+	testCases := []struct {
+		description string
+		method      string
+		path        string
+		expected    int
+	}{
+		{
+			description: "when a read outlives its deadline",
+			method:      "GET",
+			path:        "/api/v1/tracker",
+			expected:    http.StatusServiceUnavailable,
+		},
+		{
+			description: "when a write outlives its deadline",
+			method:      "POST",
+			path:        "/api/v1/tracker",
+			expected:    http.StatusServiceUnavailable,
+		},
+		{
+			description: "when the events stream outlives the deadlines",
+			method:      "GET",
+			path:        "/api/v1/tracker/events",
+			expected:    http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			router := mux.NewRouter()
+			router.Use(NewTimeout(map[string]time.Duration{
+				RouteGroupRead:  10 * time.Millisecond,
+				RouteGroupWrite: 10 * time.Millisecond,
+			}).Middleware)
+			router.HandleFunc("/api/v1/tracker/events", slow).Methods("GET")
+			router.HandleFunc("/api/v1/tracker", slow).Methods("GET", "POST")
+
+			w := httptest.NewRecorder()
+			started := time.Now()
+			router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			g.Expect(w.Code).To(Equal(tc.expected), "should answer once the handler gives up")
+			if tc.expected == http.StatusServiceUnavailable {
+				g.Expect(time.Since(started)).To(BeNumerically("<", 100*time.Millisecond), "should give up at the deadline")
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"pento/code-challenge/domain/tracker/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type TrackerStopper interface {
+	AutoStopTrackers(ctx context.Context) ([]models.TimeTracker, error)
+}
+
+// AutoStopper periodically stops the trackers left running past their
+// auto-stop threshold.
+type AutoStopper struct {
+	service  TrackerStopper
+	interval time.Duration
+	logger   logrus.FieldLogger
+}
+
+func NewAutoStopper(service TrackerStopper, interval time.Duration, logger logrus.FieldLogger) *AutoStopper {
+	return &AutoStopper{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run checks the running trackers right away and then every interval until
+// ctx is cancelled.
+func (a *AutoStopper) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		a.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *AutoStopper) runOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, a.interval)
+	defer cancel()
+
+	stopped, err := a.service.AutoStopTrackers(ctx)
+	if err != nil {
+		a.logger.WithError(err).Error("failed to auto-stop trackers")
+
+		return
+	}
+
+	if len(stopped) > 0 {
+		a.logger.WithField("stopped", len(stopped)).Info("auto-stopped trackers")
+	}
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PomodoroAdvancer interface {
+	AdvancePomodoros(ctx context.Context) error
+}
+
+// PomodoroTicker periodically moves the active pomodoros to their current
+// phase, so trackers start and stop on time without a client.
+type PomodoroTicker struct {
+	service  PomodoroAdvancer
+	interval time.Duration
+	logger   logrus.FieldLogger
+}
+
+func NewPomodoroTicker(service PomodoroAdvancer, interval time.Duration, logger logrus.FieldLogger) *PomodoroTicker {
+	return &PomodoroTicker{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run advances the pomodoros right away and then every interval until ctx is
+// cancelled.
+func (p *PomodoroTicker) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *PomodoroTicker) runOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+
+	if err := p.service.AdvancePomodoros(ctx); err != nil {
+		p.logger.WithError(err).Error("failed to advance pomodoros")
+	}
+}
@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"context"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("pento/code-challenge/domain/tracker/services")
+
+type TrackerService interface {
+	GetTracker(ctx context.Context, id uint64) (models.TimeTracker, error)
+	ListTrackers(ctx context.Context, params services.ListTimeTracker) ([]models.TimeTracker, error)
+	CreateTracker(ctx context.Context, params services.CreateTrackerParams) (models.TimeTracker, error)
+	UpdateTracker(ctx context.Context, params services.UpdateTrackerParams) (models.TimeTracker, error)
+	DeleteTracker(ctx context.Context, params services.DeleteTrackerParams) error
+	Batch(ctx context.Context, params services.BatchParams) ([]services.BatchResult, error)
+	ListOverlaps(ctx context.Context, params services.ListTimeTracker) ([]models.Overlap, error)
+	Heartbeat(ctx context.Context, id uint64) error
+}
+
+// TracedTrackerService wraps every TrackerService operation in a span.
+type TracedTrackerService struct {
+	next TrackerService
+}
+
+func NewTracedTrackerService(next TrackerService) TracedTrackerService {
+	return TracedTrackerService{
+		next: next,
+	}
+}
+
+func (s TracedTrackerService) GetTracker(ctx context.Context, id uint64) (models.TimeTracker, error) {
+	ctx, span := tracer.Start(ctx, "TrackerService.GetTracker", trace.WithAttributes(attribute.Int64("tracker.id", int64(id))))
+	defer span.End()
+
+	tracker, err := s.next.GetTracker(ctx, id)
+	recordError(span, err)
+
+	return tracker, err
+}
+
+func (s TracedTrackerService) ListTrackers(ctx context.Context, params services.ListTimeTracker) ([]models.TimeTracker, error) {
+	ctx, span := tracer.Start(ctx, "TrackerService.ListTrackers")
+	defer span.End()
+
+	trackers, err := s.next.ListTrackers(ctx, params)
+	span.SetAttributes(attribute.Int("trackers.count", len(trackers)))
+	recordError(span, err)
+
+	return trackers, err
+}
+
+func (s TracedTrackerService) CreateTracker(ctx context.Context, params services.CreateTrackerParams) (models.TimeTracker, error) {
+	ctx, span := tracer.Start(ctx, "TrackerService.CreateTracker")
+	defer span.End()
+
+	tracker, err := s.next.CreateTracker(ctx, params)
+	span.SetAttributes(attribute.Int64("tracker.id", int64(tracker.ID)))
+	recordError(span, err)
+
+	return tracker, err
+}
+
+func (s TracedTrackerService) UpdateTracker(ctx context.Context, params services.UpdateTrackerParams) (models.TimeTracker, error) {
+	ctx, span := tracer.Start(ctx, "TrackerService.UpdateTracker", trace.WithAttributes(
+		attribute.Int64("tracker.id", int64(params.ID)),
+		attribute.Int64("tracker.version", int64(params.Version)),
+	))
+	defer span.End()
+
+	tracker, err := s.next.UpdateTracker(ctx, params)
+	recordError(span, err)
+
+	return tracker, err
+}
+
+func (s TracedTrackerService) DeleteTracker(ctx context.Context, params services.DeleteTrackerParams) error {
+	ctx, span := tracer.Start(ctx, "TrackerService.DeleteTracker", trace.WithAttributes(attribute.Int64("tracker.id", int64(params.ID))))
+	defer span.End()
+
+	err := s.next.DeleteTracker(ctx, params)
+	recordError(span, err)
+
+	return err
+}
+
+func (s TracedTrackerService) Batch(ctx context.Context, params services.BatchParams) ([]services.BatchResult, error) {
+	ctx, span := tracer.Start(ctx, "TrackerService.Batch", trace.WithAttributes(
+		attribute.Int("batch.operations", len(params.Operations)),
+		attribute.Bool("batch.atomic", params.Atomic),
+	))
+	defer span.End()
+
+	results, err := s.next.Batch(ctx, params)
+	recordError(span, err)
+
+	return results, err
+}
+
+func (s TracedTrackerService) ListOverlaps(ctx context.Context, params services.ListTimeTracker) ([]models.Overlap, error) {
+	ctx, span := tracer.Start(ctx, "TrackerService.ListOverlaps")
+	defer span.End()
+
+	overlaps, err := s.next.ListOverlaps(ctx, params)
+	span.SetAttributes(attribute.Int("overlaps.count", len(overlaps)))
+	recordError(span, err)
+
+	return overlaps, err
+}
+
+func (s TracedTrackerService) Heartbeat(ctx context.Context, id uint64) error {
+	ctx, span := tracer.Start(ctx, "TrackerService.Heartbeat", trace.WithAttributes(attribute.Int64("tracker.id", int64(id))))
+	defer span.End()
+
+	err := s.next.Heartbeat(ctx, id)
+	recordError(span, err)
+
+	return err
+}
+
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
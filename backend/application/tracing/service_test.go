@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"pento/code-challenge/config"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"testing"
+
+	"github.com/gorilla/mux"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	remoteTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	remoteSpanID  = "00f067aa0ba902b7"
+)
+
+// fixedService answers GetTracker with err.
+type fixedService struct {
+	TrackerService
+	err error
+}
+
+func (s fixedService) GetTracker(ctx context.Context, id uint64) (models.TimeTracker, error) {
+	return models.TimeTracker{}, s.err
+}
+
+func Test_TracedTrackerService(t *testing.T) {
+
+	// the package tracer sticks to the first provider installed, a single
+	// recorder serves every case.
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: ExporterNone})
+	NewWithT(t).Expect(err).ToNot(HaveOccurred(), "should install the propagator")
+	defer func() { _ = shutdown(context.Background()) }()
+
+	testCases := []struct {
+		description string
+		err         error
+		status      codes.Code
+	}{
+		{
+			description: "when the operation succeeds",
+			status:      codes.Unset,
+		},
+		{
+			description: "when the operation fails",
+			err:         services.ErrTrackerNotFound,
+			status:      codes.Error,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			service := NewTracedTrackerService(fixedService{err: tc.err})
+
+			router := mux.NewRouter()
+			router.Use(otelmux.Middleware("tracker-api"))
+			router.HandleFunc("/api/v2/tracker/{id}", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = service.GetTracker(r.Context(), 1)
+			})
+
+			recorded := len(recorder.Ended())
+
+			r := httptest.NewRequest("GET", "/api/v2/tracker/1", nil)
+			r.Header.Set("traceparent", "00-"+remoteTraceID+"-"+remoteSpanID+"-01")
+			router.ServeHTTP(httptest.NewRecorder(), r)
+
+			spans := recorder.Ended()[recorded:]
+			g.Expect(spans).To(HaveLen(2), "should trace the handler and the service")
+
+			operation, handler := spans[0], spans[1]
+			g.Expect(handler.Name()).To(Equal("/api/v2/tracker/{id}"), "should name the handler span after the route")
+			g.Expect(handler.SpanKind()).To(Equal(trace.SpanKindServer))
+			g.Expect(handler.Parent().TraceID().String()).To(Equal(remoteTraceID), "should continue the caller trace")
+			g.Expect(handler.Parent().SpanID().String()).To(Equal(remoteSpanID), "should be a child of the caller span")
+			g.Expect(handler.Parent().IsRemote()).To(BeTrue(), "should extract the parent from traceparent")
+
+			g.Expect(operation.Name()).To(Equal("TrackerService.GetTracker"))
+			g.Expect(operation.SpanContext().TraceID().String()).To(Equal(remoteTraceID), "should stay in the caller trace")
+			g.Expect(operation.Parent().SpanID()).To(Equal(handler.SpanContext().SpanID()), "should nest the operation in the handler span")
+			g.Expect(operation.Status().Code).To(Equal(tc.status), "should record the outcome")
+		})
+	}
+}
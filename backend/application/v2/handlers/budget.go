@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type BudgetService interface {
+	GetBudget(ctx context.Context, id uint64) (models.Budget, error)
+	ListBudgets(ctx context.Context) ([]models.Budget, error)
+	CreateBudget(ctx context.Context, params services.BudgetParams) (models.Budget, error)
+	UpdateBudget(ctx context.Context, params services.UpdateBudgetParams) (models.Budget, error)
+	DeleteBudget(ctx context.Context, params services.DeleteBudgetParams) error
+	BurnDown(ctx context.Context, id uint64, at time.Time) (models.BurnDown, error)
+}
+
+// BudgetHandler serves the project budgets and their burn-down.
+type BudgetHandler struct {
+	responder
+	service BudgetService
+}
+
+func NewBudgetHandler(service BudgetService, logger logrus.FieldLogger) *BudgetHandler {
+	return &BudgetHandler{
+		responder: responder{logger: logger},
+		service:   service,
+	}
+}
+
+// budgetRequest takes money in minor units of the currency.
+type budgetRequest struct {
+	Project         string              `json:"project"`
+	Period          models.BudgetPeriod `json:"period"`
+	Hours           float64             `json:"hours"`
+	AmountCents     int64               `json:"amount_cents"`
+	HourlyRateCents int64               `json:"hourly_rate_cents"`
+	Currency        string              `json:"currency"`
+	Thresholds      []int               `json:"thresholds"`
+	Version         uint32              `json:"version"`
+}
+
+type BudgetResponse struct {
+	ID              uint64              `json:"id"`
+	Project         string              `json:"project"`
+	Period          models.BudgetPeriod `json:"period"`
+	Hours           float64             `json:"hours"`
+	AmountCents     int64               `json:"amount_cents"`
+	HourlyRateCents int64               `json:"hourly_rate_cents"`
+	Currency        string              `json:"currency"`
+	Thresholds      []int               `json:"thresholds"`
+	CreatedAt       string              `json:"created_at"`
+	UpdatedAt       string              `json:"updated_at"`
+	Version         uint32              `json:"version"`
+}
+
+type BudgetsResponse struct {
+	Budgets []BudgetResponse `json:"budgets"`
+}
+
+// BurnPointResponse is the budget used up to the end of Date, remaining
+// fields are left out for the budget kinds that aren't set.
+type BurnPointResponse struct {
+	Date             string `json:"date"`
+	TrackedSeconds   int64  `json:"tracked_seconds"`
+	CostCents        int64  `json:"cost_cents"`
+	RemainingSeconds *int64 `json:"remaining_seconds,omitempty"`
+	RemainingCents   *int64 `json:"remaining_cents,omitempty"`
+}
+
+type BurnDownResponse struct {
+	Budget            BudgetResponse      `json:"budget"`
+	Start             *string             `json:"start"`
+	End               *string             `json:"end"`
+	TrackedSeconds    int64               `json:"tracked_seconds"`
+	CostCents         int64               `json:"cost_cents"`
+	HoursUsedPercent  float64             `json:"hours_used_percent"`
+	AmountUsedPercent float64             `json:"amount_used_percent"`
+	RemainingSeconds  *int64              `json:"remaining_seconds,omitempty"`
+	RemainingCents    *int64              `json:"remaining_cents,omitempty"`
+	Days              []BurnPointResponse `json:"days"`
+}
+
+func (h BudgetHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	budget, err := h.service.GetBudget(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, budgetFromDomain(budget, location))
+}
+
+func (h BudgetHandler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	budgets, err := h.service.ListBudgets(r.Context())
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	response := BudgetsResponse{
+		Budgets: make([]BudgetResponse, 0, len(budgets)),
+	}
+	for _, budget := range budgets {
+		response.Budgets = append(response.Budgets, budgetFromDomain(budget, location))
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h BudgetHandler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	var request budgetRequest
+	if err := readJSON(r, &request); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	budget, err := h.service.CreateBudget(r.Context(), request.params())
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusCreated, budgetFromDomain(budget, location))
+}
+
+func (h BudgetHandler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var request budgetRequest
+	if err := readJSON(r, &request); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	budget, err := h.service.UpdateBudget(r.Context(), services.UpdateBudgetParams{
+		BudgetParams: request.params(),
+		ID:           id,
+		Version:      request.Version,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, budgetFromDomain(budget, location))
+}
+
+func (h BudgetHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	version, err := queryVersion(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	err = h.service.DeleteBudget(r.Context(), services.DeleteBudgetParams{
+		ID:      id,
+		Version: version,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BurnDown compares the budget with the time tracked in the period holding
+// the optional date, a YYYY-MM-DD day in the tz location, or the current
+// period.
+func (h BudgetHandler) BurnDown(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var at time.Time
+	if r.FormValue("date") != "" {
+		at, err = time.ParseInLocation(reportDateLayout, r.FormValue("date"), location)
+		if err != nil {
+			h.writeError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+	}
+
+	burnDown, err := h.service.BurnDown(r.Context(), id, at)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	budget := burnDown.Budget
+	response := BurnDownResponse{
+		Budget:            budgetFromDomain(budget, location),
+		TrackedSeconds:    int64(burnDown.Tracked / time.Second),
+		CostCents:         burnDown.Cost,
+		HoursUsedPercent:  burnDown.HoursUsed(),
+		AmountUsedPercent: burnDown.AmountUsed(),
+		RemainingSeconds:  remainingSeconds(budget, burnDown.Tracked),
+		RemainingCents:    remainingCents(budget, burnDown.Cost),
+		Days:              make([]BurnPointResponse, 0, len(burnDown.Days)),
+	}
+
+	if !burnDown.Start.IsZero() {
+		start, end := formatTime(burnDown.Start, location), formatTime(burnDown.End, location)
+		response.Start, response.End = &start, &end
+	}
+
+	for _, point := range burnDown.Days {
+		response.Days = append(response.Days, BurnPointResponse{
+			Date:             point.Date.Format(reportDateLayout),
+			TrackedSeconds:   int64(point.Tracked / time.Second),
+			CostCents:        point.Cost,
+			RemainingSeconds: remainingSeconds(budget, point.Tracked),
+			RemainingCents:   remainingCents(budget, point.Cost),
+		})
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (request budgetRequest) params() services.BudgetParams {
+	return services.BudgetParams{
+		Project:    request.Project,
+		Period:     request.Period,
+		Hours:      time.Duration(request.Hours * float64(time.Hour)),
+		Amount:     request.AmountCents,
+		HourlyRate: request.HourlyRateCents,
+		Currency:   request.Currency,
+		Thresholds: request.Thresholds,
+	}
+}
+
+func budgetFromDomain(budget models.Budget, location *time.Location) BudgetResponse {
+	return BudgetResponse{
+		ID:              budget.ID,
+		Project:         budget.Project,
+		Period:          budget.Period,
+		Hours:           budget.Hours.Hours(),
+		AmountCents:     budget.Amount,
+		HourlyRateCents: budget.HourlyRate,
+		Currency:        budget.Currency,
+		Thresholds:      budget.Thresholds,
+		CreatedAt:       formatTime(budget.Meta.GetCreatedAt(), location),
+		UpdatedAt:       formatTime(budget.Meta.GetUpdatedAt(), location),
+		Version:         budget.Meta.GetVersion(),
+	}
+}
+
+func remainingSeconds(budget models.Budget, tracked time.Duration) *int64 {
+	if budget.Hours <= 0 {
+		return nil
+	}
+
+	remaining := int64((budget.Hours - tracked) / time.Second)
+
+	return &remaining
+}
+
+func remainingCents(budget models.Budget, cost int64) *int64 {
+	if budget.Amount <= 0 {
+		return nil
+	}
+
+	remaining := budget.Amount - cost
+
+	return &remaining
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type GoalService interface {
+	GetGoals(ctx context.Context) (models.Goals, error)
+	UpdateGoals(ctx context.Context, params services.UpdateGoalsParams) (models.Goals, error)
+	Progress(ctx context.Context, location *time.Location) (models.GoalProgress, error)
+}
+
+// GoalHandler serves the hour goals and the progress towards them.
+type GoalHandler struct {
+	responder
+	service GoalService
+}
+
+func NewGoalHandler(service GoalService, logger logrus.FieldLogger) *GoalHandler {
+	return &GoalHandler{
+		responder: responder{logger: logger},
+		service:   service,
+	}
+}
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// goalsRequest takes the targets in hours, daily by lowercase weekday name,
+// missing days are days off.
+type goalsRequest struct {
+	Daily   map[string]float64 `json:"daily"`
+	Weekly  float64            `json:"weekly"`
+	Version uint32             `json:"version"`
+}
+
+type GoalsResponse struct {
+	Daily   map[string]float64 `json:"daily"`
+	Weekly  float64            `json:"weekly"`
+	Version uint32             `json:"version"`
+}
+
+type ProgressResponse struct {
+	TrackedSeconds   int64   `json:"tracked_seconds"`
+	TargetSeconds    int64   `json:"target_seconds"`
+	RemainingSeconds int64   `json:"remaining_seconds"`
+	Percent          float64 `json:"percent"`
+	Met              bool    `json:"met"`
+}
+
+type WeekProgressResponse struct {
+	ProgressResponse
+	Start string `json:"start"`
+}
+
+type StreakResponse struct {
+	CurrentDays int     `json:"current_days"`
+	LongestDays int     `json:"longest_days"`
+	LastMet     *string `json:"last_met"`
+}
+
+type GoalProgressResponse struct {
+	Date    string               `json:"date"`
+	Today   ProgressResponse     `json:"today"`
+	Week    WeekProgressResponse `json:"week"`
+	Running bool                 `json:"running"`
+	Streak  StreakResponse       `json:"streak"`
+	// Summary reads like "5h12 of 8h today, 31h of 40h this week".
+	Summary string `json:"summary"`
+}
+
+func (h GoalHandler) GetGoals(w http.ResponseWriter, r *http.Request) {
+	goals, err := h.service.GetGoals(r.Context())
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, goalsFromDomain(goals))
+}
+
+func (h GoalHandler) UpdateGoals(w http.ResponseWriter, r *http.Request) {
+	var request goalsRequest
+	if err := readJSON(r, &request); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	params := services.UpdateGoalsParams{
+		Weekly:  hours(request.Weekly),
+		Version: request.Version,
+	}
+
+	for name, target := range request.Daily {
+		weekday := weekdayIndex(name)
+		if weekday < 0 {
+			h.writeError(w, r, http.StatusBadRequest, fmt.Errorf("unknown weekday %q", name))
+
+			return
+		}
+
+		params.Daily[weekday] = hours(target)
+	}
+
+	goals, err := h.service.UpdateGoals(r.Context(), params)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, goalsFromDomain(goals))
+}
+
+// Progress reports the progress towards today's and this week's goals in the
+// tz location.
+func (h GoalHandler) Progress(w http.ResponseWriter, r *http.Request) {
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	progress, err := h.service.Progress(r.Context(), location)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	response := GoalProgressResponse{
+		Date:  progress.Date.Format(reportDateLayout),
+		Today: progressFromDomain(progress.Today),
+		Week: WeekProgressResponse{
+			ProgressResponse: progressFromDomain(progress.Week),
+			Start:            progress.WeekStart.Format(reportDateLayout),
+		},
+		Running: progress.Running,
+		Streak: StreakResponse{
+			CurrentDays: progress.Streak.Current,
+			LongestDays: progress.Streak.Longest,
+		},
+		Summary: fmt.Sprintf("%s of %s today, %s of %s this week",
+			formatHoursMinutes(progress.Today.Tracked), formatHoursMinutes(progress.Today.Target),
+			formatHoursMinutes(progress.Week.Tracked), formatHoursMinutes(progress.Week.Target)),
+	}
+
+	if !progress.Streak.LastMet.IsZero() {
+		lastMet := progress.Streak.LastMet.Format(reportDateLayout)
+		response.Streak.LastMet = &lastMet
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+func goalsFromDomain(goals models.Goals) GoalsResponse {
+	response := GoalsResponse{
+		Daily:   make(map[string]float64, len(weekdays)),
+		Weekly:  goals.Weekly.Hours(),
+		Version: goals.Meta.GetVersion(),
+	}
+
+	for weekday, target := range goals.Daily {
+		response.Daily[weekdays[weekday]] = target.Hours()
+	}
+
+	return response
+}
+
+func progressFromDomain(progress models.Progress) ProgressResponse {
+	return ProgressResponse{
+		TrackedSeconds:   int64(progress.Tracked / time.Second),
+		TargetSeconds:    int64(progress.Target / time.Second),
+		RemainingSeconds: int64(progress.Remaining() / time.Second),
+		Percent:          progress.Percent(),
+		Met:              progress.Met(),
+	}
+}
+
+func weekdayIndex(name string) int {
+	for index, weekday := range weekdays {
+		if strings.EqualFold(weekday, name) {
+			return index
+		}
+	}
+
+	return -1
+}
+
+func hours(value float64) time.Duration {
+	return time.Duration(value * float64(time.Hour))
+}
+
+// formatHoursMinutes renders a duration as hours and minutes, like 5h12 or 8h.
+func formatHoursMinutes(duration time.Duration) string {
+	duration = duration.Truncate(time.Minute)
+	whole, minutes := int64(duration/time.Hour), int64(duration%time.Hour/time.Minute)
+
+	if minutes == 0 {
+		return fmt.Sprintf("%dh", whole)
+	}
+
+	return fmt.Sprintf("%dh%02d", whole, minutes)
+}
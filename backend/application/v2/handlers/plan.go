@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type PlanService interface {
+	GetBlock(ctx context.Context, id uint64) (models.PlannedBlock, error)
+	ListBlocks(ctx context.Context, params services.ListBlocksParams) ([]models.PlannedBlock, error)
+	CreateBlock(ctx context.Context, params services.CreateBlockParams) (models.PlannedBlock, error)
+	UpdateBlock(ctx context.Context, params services.UpdateBlockParams) (models.PlannedBlock, error)
+	DeleteBlock(ctx context.Context, params services.DeleteBlockParams) error
+	PlanReport(ctx context.Context, params services.PlanReportParams) (models.PlanReport, error)
+}
+
+// PlanHandler serves the planned blocks and the plan versus actual report.
+type PlanHandler struct {
+	responder
+	service PlanService
+}
+
+func NewPlanHandler(service PlanService, logger logrus.FieldLogger) *PlanHandler {
+	return &PlanHandler{
+		responder: responder{logger: logger},
+		service:   service,
+	}
+}
+
+type blockRequest struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Project string    `json:"project"`
+	Note    string    `json:"note"`
+	Version uint32    `json:"version"`
+}
+
+type BlockResponse struct {
+	ID              uint64 `json:"id"`
+	Start           string `json:"start"`
+	End             string `json:"end"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	Project         string `json:"project"`
+	Note            string `json:"note"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+	Version         uint32 `json:"version"`
+}
+
+type BlocksResponse struct {
+	Blocks []BlockResponse `json:"blocks"`
+}
+
+type VarianceResponse struct {
+	Project         string `json:"project,omitempty"`
+	PlannedSeconds  int64  `json:"planned_seconds"`
+	ActualSeconds   int64  `json:"actual_seconds"`
+	MatchedSeconds  int64  `json:"matched_seconds"`
+	VarianceSeconds int64  `json:"variance_seconds"`
+}
+
+type BlockMatchResponse struct {
+	Block          BlockResponse `json:"block"`
+	MatchedSeconds int64         `json:"matched_seconds"`
+	Trackers       []uint64      `json:"trackers"`
+}
+
+type PlanDayResponse struct {
+	Date     string               `json:"date"`
+	Blocks   []BlockMatchResponse `json:"blocks"`
+	Projects []VarianceResponse   `json:"projects"`
+	Total    VarianceResponse     `json:"total"`
+}
+
+type PlanReportResponse struct {
+	Start    string             `json:"start"`
+	End      string             `json:"end"`
+	Days     []PlanDayResponse  `json:"days"`
+	Projects []VarianceResponse `json:"projects"`
+	Total    VarianceResponse   `json:"total"`
+}
+
+func (h PlanHandler) GetBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	block, err := h.service.GetBlock(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, blockFromDomain(block, location))
+}
+
+// ListBlocks lists the blocks starting between the RFC 3339 start_date and
+// end_date.
+func (h PlanHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var params services.ListBlocksParams
+
+	if r.FormValue("start_date") != "" {
+		params.Start, err = time.Parse(time.RFC3339, r.FormValue("start_date"))
+		if err != nil {
+			h.writeError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+	}
+
+	if r.FormValue("end_date") != "" {
+		params.End, err = time.Parse(time.RFC3339, r.FormValue("end_date"))
+		if err != nil {
+			h.writeError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+	}
+
+	blocks, err := h.service.ListBlocks(r.Context(), params)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	response := BlocksResponse{
+		Blocks: make([]BlockResponse, 0, len(blocks)),
+	}
+	for _, block := range blocks {
+		response.Blocks = append(response.Blocks, blockFromDomain(block, location))
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h PlanHandler) CreateBlock(w http.ResponseWriter, r *http.Request) {
+	var request blockRequest
+	if err := readJSON(r, &request); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	block, err := h.service.CreateBlock(r.Context(), services.CreateBlockParams{
+		Start:   request.Start,
+		End:     request.End,
+		Project: request.Project,
+		Note:    request.Note,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusCreated, blockFromDomain(block, location))
+}
+
+func (h PlanHandler) UpdateBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var request blockRequest
+	if err := readJSON(r, &request); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	block, err := h.service.UpdateBlock(r.Context(), services.UpdateBlockParams{
+		ID:      id,
+		Start:   request.Start,
+		End:     request.End,
+		Project: request.Project,
+		Note:    request.Note,
+		Version: request.Version,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, blockFromDomain(block, location))
+}
+
+func (h PlanHandler) DeleteBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	version, err := queryVersion(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	err = h.service.DeleteBlock(r.Context(), services.DeleteBlockParams{
+		ID:      id,
+		Version: version,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PlanReport compares the blocks planned between the start_date and end_date
+// days, both included, with the time tracked in the tz location.
+func (h PlanHandler) PlanReport(w http.ResponseWriter, r *http.Request) {
+	start, end, location, err := reportPeriod(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	report, err := h.service.PlanReport(r.Context(), services.PlanReportParams{
+		Start:    start,
+		End:      end,
+		Location: location,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	response := PlanReportResponse{
+		Start:    formatTime(report.Start, location),
+		End:      formatTime(report.End, location),
+		Days:     make([]PlanDayResponse, 0, len(report.Days)),
+		Projects: variancesFromDomain(report.Projects),
+		Total:    varianceFromDomain(report.Total),
+	}
+
+	for _, day := range report.Days {
+		dayResponse := PlanDayResponse{
+			Date:     day.Date.Format(reportDateLayout),
+			Blocks:   make([]BlockMatchResponse, 0, len(day.Blocks)),
+			Projects: variancesFromDomain(day.Projects),
+			Total:    varianceFromDomain(day.Total),
+		}
+
+		for _, match := range day.Blocks {
+			dayResponse.Blocks = append(dayResponse.Blocks, BlockMatchResponse{
+				Block:          blockFromDomain(match.Block, location),
+				MatchedSeconds: int64(match.Matched / time.Second),
+				Trackers:       match.Trackers,
+			})
+		}
+
+		response.Days = append(response.Days, dayResponse)
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+func blockFromDomain(block models.PlannedBlock, location *time.Location) BlockResponse {
+	return BlockResponse{
+		ID:              block.ID,
+		Start:           formatTime(block.Start, location),
+		End:             formatTime(block.End, location),
+		DurationSeconds: int64(block.Duration() / time.Second),
+		Project:         block.Project,
+		Note:            block.Note,
+		CreatedAt:       formatTime(block.Meta.GetCreatedAt(), location),
+		UpdatedAt:       formatTime(block.Meta.GetUpdatedAt(), location),
+		Version:         block.Meta.GetVersion(),
+	}
+}
+
+func variancesFromDomain(variances []models.PlanVariance) []VarianceResponse {
+	responses := make([]VarianceResponse, 0, len(variances))
+	for _, variance := range variances {
+		responses = append(responses, varianceFromDomain(variance))
+	}
+
+	return responses
+}
+
+func varianceFromDomain(variance models.PlanVariance) VarianceResponse {
+	return VarianceResponse{
+		Project:         variance.Project,
+		PlannedSeconds:  int64(variance.Planned / time.Second),
+		ActualSeconds:   int64(variance.Actual / time.Second),
+		MatchedSeconds:  int64(variance.Matched / time.Second),
+		VarianceSeconds: int64(variance.Variance / time.Second),
+	}
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type PomodoroService interface {
+	StartPomodoro(ctx context.Context, params services.StartPomodoroParams) (models.Pomodoro, error)
+	GetPomodoro(ctx context.Context, id uint64) (models.Pomodoro, error)
+	ListActivePomodoros(ctx context.Context) ([]models.Pomodoro, error)
+	StopPomodoro(ctx context.Context, id uint64) (models.Pomodoro, error)
+	PomodoroReport(ctx context.Context, params services.PomodoroReportParams) ([]models.PomodoroDay, error)
+}
+
+// PomodoroHandler serves the pomodoros and their daily counts.
+type PomodoroHandler struct {
+	responder
+	service PomodoroService
+	now     func() time.Time
+}
+
+func NewPomodoroHandler(service PomodoroService, logger logrus.FieldLogger) *PomodoroHandler {
+	return &PomodoroHandler{
+		responder: responder{logger: logger},
+		service:   service,
+		now:       time.Now,
+	}
+}
+
+// pomodoroRequest takes the schedule in minutes, missing fields keep the
+// configured schedule. Continuous runs rounds until stopped.
+type pomodoroRequest struct {
+	Name              string   `json:"name"`
+	Project           string   `json:"project"`
+	Tags              []string `json:"tags"`
+	FocusMinutes      float64  `json:"focus_minutes"`
+	ShortBreakMinutes float64  `json:"short_break_minutes"`
+	LongBreakMinutes  float64  `json:"long_break_minutes"`
+	LongBreakEvery    int      `json:"long_break_every"`
+	Rounds            int      `json:"rounds"`
+	Continuous        bool     `json:"continuous"`
+}
+
+type PomodoroScheduleResponse struct {
+	FocusSeconds      int64 `json:"focus_seconds"`
+	ShortBreakSeconds int64 `json:"short_break_seconds"`
+	LongBreakSeconds  int64 `json:"long_break_seconds"`
+	LongBreakEvery    int   `json:"long_break_every"`
+	Rounds            int   `json:"rounds"`
+}
+
+type PomodoroPhaseResponse struct {
+	Kind      models.PomodoroPhaseKind `json:"kind"`
+	Round     int                      `json:"round"`
+	Start     string                   `json:"start"`
+	End       string                   `json:"end"`
+	TrackerID uint64                   `json:"tracker_id,omitempty"`
+	Completed bool                     `json:"completed"`
+}
+
+type CurrentPhaseResponse struct {
+	Kind             models.PomodoroPhaseKind `json:"kind"`
+	Round            int                      `json:"round"`
+	Start            string                   `json:"start"`
+	End              string                   `json:"end"`
+	TrackerID        uint64                   `json:"tracker_id,omitempty"`
+	RemainingSeconds int64                    `json:"remaining_seconds"`
+}
+
+type PomodoroResponse struct {
+	ID        uint64                   `json:"id"`
+	Name      string                   `json:"name"`
+	Project   *ProjectResponse         `json:"project"`
+	Tags      []string                 `json:"tags"`
+	Schedule  PomodoroScheduleResponse `json:"schedule"`
+	Start     string                   `json:"start"`
+	End       *string                  `json:"end"`
+	Active    bool                     `json:"active"`
+	Completed int                      `json:"completed"`
+	// Current is the phase in progress, computed from the schedule so it is
+	// exact between two scheduler runs.
+	Current *CurrentPhaseResponse   `json:"current"`
+	Phases  []PomodoroPhaseResponse `json:"phases"`
+	Version uint32                  `json:"version"`
+}
+
+type PomodorosResponse struct {
+	Pomodoros []PomodoroResponse `json:"pomodoros"`
+}
+
+type PomodoroDayResponse struct {
+	Date         string `json:"date"`
+	Completed    int    `json:"completed"`
+	Interrupted  int    `json:"interrupted"`
+	FocusSeconds int64  `json:"focus_seconds"`
+	BreakSeconds int64  `json:"break_seconds"`
+}
+
+type PomodoroReportResponse struct {
+	Start     string                `json:"start_date"`
+	End       string                `json:"end_date"`
+	Completed int                   `json:"completed"`
+	Days      []PomodoroDayResponse `json:"days"`
+}
+
+func (h PomodoroHandler) StartPomodoro(w http.ResponseWriter, r *http.Request) {
+	var request pomodoroRequest
+	if err := readJSON(r, &request); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	pomodoro, err := h.service.StartPomodoro(r.Context(), services.StartPomodoroParams{
+		Name:    request.Name,
+		Project: request.Project,
+		Tags:    request.Tags,
+		Schedule: models.PomodoroSchedule{
+			Focus:          minutes(request.FocusMinutes),
+			ShortBreak:     minutes(request.ShortBreakMinutes),
+			LongBreak:      minutes(request.LongBreakMinutes),
+			LongBreakEvery: request.LongBreakEvery,
+			Rounds:         request.Rounds,
+		},
+		Continuous: request.Continuous,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusCreated, h.fromDomain(pomodoro, location))
+}
+
+// ListPomodoros lists the active pomodoros.
+func (h PomodoroHandler) ListPomodoros(w http.ResponseWriter, r *http.Request) {
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	pomodoros, err := h.service.ListActivePomodoros(r.Context())
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	response := PomodorosResponse{Pomodoros: make([]PomodoroResponse, 0, len(pomodoros))}
+	for _, pomodoro := range pomodoros {
+		response.Pomodoros = append(response.Pomodoros, h.fromDomain(pomodoro, location))
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h PomodoroHandler) GetPomodoro(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	pomodoro, err := h.service.GetPomodoro(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, h.fromDomain(pomodoro, location))
+}
+
+// StopPomodoro ends the pomodoro and stops the tracker of a focus phase in
+// progress.
+func (h PomodoroHandler) StopPomodoro(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	pomodoro, err := h.service.StopPomodoro(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, h.fromDomain(pomodoro, location))
+}
+
+// Report counts the pomodoros per day between the inclusive start_date and
+// end_date in the tz location.
+func (h PomodoroHandler) Report(w http.ResponseWriter, r *http.Request) {
+	start, end, location, err := reportPeriod(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	days, err := h.service.PomodoroReport(r.Context(), services.PomodoroReportParams{
+		Start:    start,
+		End:      end,
+		Location: location,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	response := PomodoroReportResponse{
+		Start: start.Format(reportDateLayout),
+		End:   end.AddDate(0, 0, -1).Format(reportDateLayout),
+		Days:  make([]PomodoroDayResponse, 0, len(days)),
+	}
+
+	for _, day := range days {
+		response.Completed += day.Completed
+		response.Days = append(response.Days, PomodoroDayResponse{
+			Date:         day.Date.Format(reportDateLayout),
+			Completed:    day.Completed,
+			Interrupted:  day.Interrupted,
+			FocusSeconds: int64(day.Focus / time.Second),
+			BreakSeconds: int64(day.Break / time.Second),
+		})
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h PomodoroHandler) fromDomain(pomodoro models.Pomodoro, location *time.Location) PomodoroResponse {
+	response := PomodoroResponse{
+		ID:   pomodoro.ID,
+		Name: pomodoro.Name,
+		Tags: pomodoro.Tags,
+		Schedule: PomodoroScheduleResponse{
+			FocusSeconds:      int64(pomodoro.Schedule.Focus / time.Second),
+			ShortBreakSeconds: int64(pomodoro.Schedule.ShortBreak / time.Second),
+			LongBreakSeconds:  int64(pomodoro.Schedule.LongBreak / time.Second),
+			LongBreakEvery:    pomodoro.Schedule.LongBreakEvery,
+			Rounds:            pomodoro.Schedule.Rounds,
+		},
+		Start:   formatTime(pomodoro.Start, location),
+		Active:  pomodoro.IsActive(),
+		Phases:  make([]PomodoroPhaseResponse, 0, len(pomodoro.Phases)),
+		Version: pomodoro.Meta.GetVersion(),
+	}
+
+	if pomodoro.Project != "" {
+		response.Project = &ProjectResponse{Name: pomodoro.Project}
+	}
+
+	if response.Tags == nil {
+		response.Tags = make([]string, 0)
+	}
+
+	if !pomodoro.End.IsZero() {
+		end := formatTime(pomodoro.End, location)
+		response.End = &end
+	}
+
+	for _, phase := range pomodoro.Phases {
+		if phase.Kind == models.PhaseFocus && phase.Completed {
+			response.Completed++
+		}
+
+		response.Phases = append(response.Phases, PomodoroPhaseResponse{
+			Kind:      phase.Kind,
+			Round:     phase.Round,
+			Start:     formatTime(phase.Start, location),
+			End:       formatTime(phase.End, location),
+			TrackerID: phase.TrackerID,
+			Completed: phase.Completed,
+		})
+	}
+
+	now := h.now()
+	if current, ok := pomodoro.Current(now); ok {
+		response.Current = &CurrentPhaseResponse{
+			Kind:             current.Kind,
+			Round:            current.Round,
+			Start:            formatTime(current.Start, location),
+			End:              formatTime(current.End, location),
+			TrackerID:        current.TrackerID,
+			RemainingSeconds: int64(current.End.Sub(now).Round(time.Second) / time.Second),
+		}
+	}
+
+	return response
+}
+
+func minutes(value float64) time.Duration {
+	return time.Duration(value * float64(time.Minute))
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	reportDateLayout = "2006-01-02"
+	maxReportDays    = 366
+)
+
+type ReportService interface {
+	Report(ctx context.Context, params services.ReportParams) (models.Report, error)
+}
+
+// ReportHandler serves time reports as JSON, or as CSV exports with
+// format=csv.
+type ReportHandler struct {
+	responder
+	service ReportService
+}
+
+func NewReportHandler(service ReportService, logger logrus.FieldLogger) *ReportHandler {
+	return &ReportHandler{
+		responder: responder{logger: logger},
+		service:   service,
+	}
+}
+
+type RoundingResponse struct {
+	Mode             models.RoundingMode        `json:"mode"`
+	IncrementSeconds int64                      `json:"increment_seconds"`
+	Aggregation      models.RoundingAggregation `json:"aggregation"`
+}
+
+type ReportTotalResponse struct {
+	Project        string `json:"project,omitempty"`
+	RawSeconds     int64  `json:"raw_seconds"`
+	RoundedSeconds int64  `json:"rounded_seconds"`
+}
+
+type ReportEntryResponse struct {
+	TrackerID      uint64  `json:"tracker_id"`
+	Name           string  `json:"name"`
+	Project        string  `json:"project"`
+	Start          string  `json:"start"`
+	End            *string `json:"end"`
+	RawSeconds     int64   `json:"raw_seconds"`
+	RoundedSeconds int64   `json:"rounded_seconds"`
+}
+
+type ReportDayResponse struct {
+	Date     string                `json:"date"`
+	Entries  []ReportEntryResponse `json:"entries"`
+	Projects []ReportTotalResponse `json:"projects"`
+	Total    ReportTotalResponse   `json:"total"`
+}
+
+type ReportResponse struct {
+	Start    string                `json:"start"`
+	End      string                `json:"end"`
+	Rounding RoundingResponse      `json:"rounding"`
+	Days     []ReportDayResponse   `json:"days"`
+	Projects []ReportTotalResponse `json:"projects"`
+	Total    ReportTotalResponse   `json:"total"`
+}
+
+// TimeReport reports the trackers started between the start_date and
+// end_date days, both included, in the tz location. The rounding, increment
+// and aggregation parameters override the configured rounding policy.
+func (h ReportHandler) TimeReport(w http.ResponseWriter, r *http.Request) {
+	params, err := reportParams(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	report, err := h.service.Report(r.Context(), params)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	switch r.FormValue("format") {
+	case "", "json":
+		h.writeJSON(w, r, http.StatusOK, reportFromDomain(report, params.Location))
+	case "csv":
+		h.writeCSV(w, r, report, params.Location)
+	default:
+		h.writeError(w, r, http.StatusBadRequest, fmt.Errorf("unknown format %q", r.FormValue("format")))
+	}
+}
+
+func reportParams(r *http.Request) (services.ReportParams, error) {
+	start, end, location, err := reportPeriod(r)
+	if err != nil {
+		return services.ReportParams{}, err
+	}
+
+	params := services.ReportParams{
+		Start:    start,
+		End:      end,
+		Location: location,
+		Rounding: models.Rounding{
+			Mode:        models.RoundingMode(r.FormValue("rounding")),
+			Aggregation: models.RoundingAggregation(r.FormValue("aggregation")),
+		},
+	}
+
+	if r.FormValue("increment") != "" {
+		params.Rounding.Increment, err = time.ParseDuration(r.FormValue("increment"))
+		if err != nil {
+			return services.ReportParams{}, fmt.Errorf("%w invalid increment", err)
+		}
+	}
+
+	return params, nil
+}
+
+// reportPeriod reads the start_date and end_date days, both included, in the
+// tz location.
+func reportPeriod(r *http.Request) (time.Time, time.Time, *time.Location, error) {
+	location, err := requestLocation(r)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, err
+	}
+
+	start, err := time.ParseInLocation(reportDateLayout, r.FormValue("start_date"), location)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("%w invalid start_date", err)
+	}
+
+	end, err := time.ParseInLocation(reportDateLayout, r.FormValue("end_date"), location)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("%w invalid end_date", err)
+	}
+
+	end = end.AddDate(0, 0, 1)
+	if !end.After(start) || end.After(start.AddDate(0, 0, maxReportDays)) {
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("report period must span 1 to %d days", maxReportDays)
+	}
+
+	return start, end, location, nil
+}
+
+func reportFromDomain(report models.Report, location *time.Location) ReportResponse {
+	response := ReportResponse{
+		Start: formatTime(report.Start, location),
+		End:   formatTime(report.End, location),
+		Rounding: RoundingResponse{
+			Mode:             report.Rounding.Mode,
+			IncrementSeconds: int64(report.Rounding.Increment / time.Second),
+			Aggregation:      report.Rounding.Aggregation,
+		},
+		Days:     make([]ReportDayResponse, 0, len(report.Days)),
+		Projects: totalsFromDomain(report.Projects),
+		Total:    totalFromDomain(report.Total),
+	}
+
+	for _, day := range report.Days {
+		dayResponse := ReportDayResponse{
+			Date:     day.Date.Format(reportDateLayout),
+			Entries:  make([]ReportEntryResponse, 0, len(day.Entries)),
+			Projects: totalsFromDomain(day.Projects),
+			Total:    totalFromDomain(day.Total),
+		}
+
+		for _, entry := range day.Entries {
+			entryResponse := ReportEntryResponse{
+				TrackerID:      entry.Tracker.ID,
+				Name:           entry.Tracker.Name,
+				Project:        entry.Tracker.Project,
+				Start:          formatTime(entry.Tracker.Start, location),
+				RawSeconds:     int64(entry.Raw / time.Second),
+				RoundedSeconds: int64(entry.Rounded / time.Second),
+			}
+
+			if !entry.Tracker.End.IsZero() {
+				end := formatTime(entry.Tracker.End, location)
+				entryResponse.End = &end
+			}
+
+			dayResponse.Entries = append(dayResponse.Entries, entryResponse)
+		}
+
+		response.Days = append(response.Days, dayResponse)
+	}
+
+	return response
+}
+
+func totalsFromDomain(totals []models.ReportTotal) []ReportTotalResponse {
+	responses := make([]ReportTotalResponse, 0, len(totals))
+	for _, total := range totals {
+		responses = append(responses, totalFromDomain(total))
+	}
+
+	return responses
+}
+
+func totalFromDomain(total models.ReportTotal) ReportTotalResponse {
+	return ReportTotalResponse{
+		Project:        total.Project,
+		RawSeconds:     int64(total.Raw / time.Second),
+		RoundedSeconds: int64(total.Rounded / time.Second),
+	}
+}
+
+// writeCSV exports a row per rounded unit: every entry, or every project of
+// every day when rounding applies per day. The rounding column echoes the
+// applied policy.
+func (h ReportHandler) writeCSV(w http.ResponseWriter, r *http.Request, report models.Report, location *time.Location) {
+	rounding := formatRounding(report.Rounding)
+	rows := [][]string{{"date", "tracker_id", "name", "project", "start", "end", "raw_hours", "rounded_hours", "rounding"}}
+
+	for _, day := range report.Days {
+		date := day.Date.Format(reportDateLayout)
+
+		if report.Rounding.Aggregation == models.RoundPerDay {
+			for _, project := range day.Projects {
+				rows = append(rows, []string{date, "", "", project.Project, "", "", formatHours(project.Raw), formatHours(project.Rounded), rounding})
+			}
+
+			continue
+		}
+
+		for _, entry := range day.Entries {
+			end := ""
+			if !entry.Tracker.End.IsZero() {
+				end = formatTime(entry.Tracker.End, location)
+			}
+
+			rows = append(rows, []string{
+				date,
+				strconv.FormatUint(entry.Tracker.ID, 10),
+				entry.Tracker.Name,
+				entry.Tracker.Project,
+				formatTime(entry.Tracker.Start, location),
+				end,
+				formatHours(entry.Raw),
+				formatHours(entry.Rounded),
+				rounding,
+			})
+		}
+	}
+
+	filename := fmt.Sprintf("report-%s-%s.csv", report.Start.Format(reportDateLayout), report.End.AddDate(0, 0, -1).Format(reportDateLayout))
+
+	w.Header().Set("Content-Type", "text/csv; charset=UTF-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	if err := csv.NewWriter(w).WriteAll(rows); err != nil {
+		h.log(r).WithError(err).Warn("failed to write response")
+	}
+}
+
+func formatRounding(rounding models.Rounding) string {
+	if rounding.Mode == models.RoundNone {
+		return string(models.RoundNone)
+	}
+
+	increment := rounding.Increment.String()
+	if rounding.Increment%time.Minute == 0 {
+		increment = fmt.Sprintf("%dm", rounding.Increment/time.Minute)
+	}
+
+	return fmt.Sprintf("%s %s per %s", rounding.Mode, increment, rounding.Aggregation)
+}
+
+func formatHours(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Hours(), 'f', 2, 64)
+}
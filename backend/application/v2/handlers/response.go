@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/services"
+	"pento/code-challenge/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// responder writes the JSON responses and errors shared by the v2 handlers.
+type responder struct {
+	logger logrus.FieldLogger
+}
+
+func (h responder) log(r *http.Request) logrus.FieldLogger {
+	return logging.FromContext(r.Context(), h.logger)
+}
+
+func (h responder) writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log(r).WithError(err).Error("failed to encode response")
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	if _, err := w.Write(response); err != nil {
+		h.log(r).WithError(err).Warn("failed to write response")
+	}
+}
+
+func (h responder) writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	entry := h.log(r).WithError(err).WithField("status", status)
+	if status >= http.StatusInternalServerError {
+		entry.Error("request failed")
+	} else {
+		entry.Warn("request rejected")
+	}
+
+	response := errorResponse{Error: http.StatusText(status)}
+	if status < http.StatusInternalServerError {
+		response.Error = err.Error()
+		response.Code = apierror.Code(err)
+	}
+
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		response.Fields = validationErr.Fields
+	}
+
+	var overlapErr *services.OverlapError
+	if errors.As(err, &overlapErr) {
+		response.Overlaps = overlapErr.IDs
+	}
+
+	h.writeJSON(w, r, status, response)
+}
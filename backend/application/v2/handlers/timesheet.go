@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type TimesheetService interface {
+	GetTimesheet(ctx context.Context, day time.Time) (models.Timesheet, error)
+	ListTimesheets(ctx context.Context, status models.TimesheetStatus) ([]models.Timesheet, error)
+	Transition(ctx context.Context, params services.TimesheetActionParams) (models.Timesheet, error)
+}
+
+// TimesheetHandler serves the weekly timesheets and their submission and
+// approval.
+type TimesheetHandler struct {
+	responder
+	service TimesheetService
+	now     func() time.Time
+}
+
+func NewTimesheetHandler(service TimesheetService, logger logrus.FieldLogger) *TimesheetHandler {
+	return &TimesheetHandler{
+		responder: responder{logger: logger},
+		service:   service,
+		now:       time.Now,
+	}
+}
+
+type timesheetActionRequest struct {
+	Author  string `json:"author"`
+	Comment string `json:"comment"`
+	Version uint32 `json:"version"`
+}
+
+type TimesheetDayResponse struct {
+	Date           string `json:"date"`
+	TrackedSeconds int64  `json:"tracked_seconds"`
+}
+
+type TimesheetCommentResponse struct {
+	Action  models.TimesheetAction `json:"action"`
+	Author  string                 `json:"author"`
+	Comment string                 `json:"comment"`
+	At      string                 `json:"at"`
+}
+
+type TimesheetResponse struct {
+	Week     string                     `json:"week"`
+	Start    string                     `json:"start"`
+	End      string                     `json:"end"`
+	Status   models.TimesheetStatus     `json:"status"`
+	Locked   bool                       `json:"locked"`
+	Comments []TimesheetCommentResponse `json:"comments"`
+	Version  uint32                     `json:"version"`
+}
+
+// TimesheetDetailResponse adds the tracked time to a single timesheet.
+type TimesheetDetailResponse struct {
+	TimesheetResponse
+	TotalSeconds int64                  `json:"total_seconds"`
+	Days         []TimesheetDayResponse `json:"days"`
+	Trackers     []TrackerResponse      `json:"trackers"`
+}
+
+type TimesheetsResponse struct {
+	Timesheets []TimesheetResponse `json:"timesheets"`
+}
+
+// ListTimesheets lists the timesheets acted upon, filtered by the optional
+// status.
+func (h TimesheetHandler) ListTimesheets(w http.ResponseWriter, r *http.Request) {
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	timesheets, err := h.service.ListTimesheets(r.Context(), models.TimesheetStatus(r.FormValue("status")))
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	response := TimesheetsResponse{
+		Timesheets: make([]TimesheetResponse, 0, len(timesheets)),
+	}
+	for _, timesheet := range timesheets {
+		response.Timesheets = append(response.Timesheets, h.fromDomain(timesheet, location))
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+// GetTimesheet returns the timesheet of the week of the {week} date, with
+// its trackers and the time tracked per day.
+func (h TimesheetHandler) GetTimesheet(w http.ResponseWriter, r *http.Request) {
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	day, err := time.Parse(reportDateLayout, mux.Vars(r)["week"])
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	timesheet, err := h.service.GetTimesheet(r.Context(), day)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, h.detailFromDomain(timesheet, location))
+}
+
+// Transition submits, approves, rejects or reopens the timesheet of the
+// week of the {week} date, as named by {action}.
+func (h TimesheetHandler) Transition(w http.ResponseWriter, r *http.Request) {
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	day, err := time.Parse(reportDateLayout, vars["week"])
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var request timesheetActionRequest
+	if err := readJSON(r, &request); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	timesheet, err := h.service.Transition(r.Context(), services.TimesheetActionParams{
+		Week:    day,
+		Action:  models.TimesheetAction(vars["action"]),
+		Author:  request.Author,
+		Comment: request.Comment,
+		Version: request.Version,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, h.detailFromDomain(timesheet, location))
+}
+
+func (h TimesheetHandler) fromDomain(timesheet models.Timesheet, location *time.Location) TimesheetResponse {
+	response := TimesheetResponse{
+		Week:     timesheet.Start.Format(reportDateLayout),
+		Start:    formatTime(timesheet.Start, location),
+		End:      formatTime(timesheet.End, location),
+		Status:   timesheet.Status,
+		Locked:   timesheet.Locked(),
+		Comments: make([]TimesheetCommentResponse, 0, len(timesheet.Comments)),
+		Version:  timesheet.Meta.GetVersion(),
+	}
+
+	for _, comment := range timesheet.Comments {
+		response.Comments = append(response.Comments, TimesheetCommentResponse{
+			Action:  comment.Action,
+			Author:  comment.Author,
+			Comment: comment.Body,
+			At:      formatTime(comment.At, location),
+		})
+	}
+
+	return response
+}
+
+func (h TimesheetHandler) detailFromDomain(timesheet models.Timesheet, location *time.Location) TimesheetDetailResponse {
+	response := TimesheetDetailResponse{
+		TimesheetResponse: h.fromDomain(timesheet, location),
+		TotalSeconds:      int64(timesheet.Total / time.Second),
+		Days:              make([]TimesheetDayResponse, 0, len(timesheet.Days)),
+		Trackers:          make([]TrackerResponse, 0, len(timesheet.Trackers)),
+	}
+
+	for _, day := range timesheet.Days {
+		response.Days = append(response.Days, TimesheetDayResponse{
+			Date:           day.Date.Format(reportDateLayout),
+			TrackedSeconds: int64(day.Tracked / time.Second),
+		})
+	}
+
+	now := h.now()
+	for _, tracker := range timesheet.Trackers {
+		response.Trackers = append(response.Trackers, trackerFromDomain(tracker, location, now))
+	}
+
+	return response
+}
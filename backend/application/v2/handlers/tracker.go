@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type TrackerService interface {
+	GetTracker(ctx context.Context, id uint64) (models.TimeTracker, error)
+	ListTrackers(ctx context.Context, params services.ListTimeTracker) ([]models.TimeTracker, error)
+	CreateTracker(ctx context.Context, params services.CreateTrackerParams) (models.TimeTracker, error)
+	UpdateTracker(ctx context.Context, params services.UpdateTrackerParams) (models.TimeTracker, error)
+	DeleteTracker(ctx context.Context, params services.DeleteTrackerParams) error
+}
+
+// TrackerHandler serves the v2 tracker representation, which adds computed
+// durations, project and tags to the v1 one.
+type TrackerHandler struct {
+	responder
+	service TrackerService
+	now     func() time.Time
+}
+
+func NewTrackerHandler(service TrackerService, logger logrus.FieldLogger) *TrackerHandler {
+	return &TrackerHandler{
+		responder: responder{logger: logger},
+		service:   service,
+		now:       time.Now,
+	}
+}
+
+type createTrackerRequest struct {
+	Start   time.Time `json:"start"`
+	Name    string    `json:"name"`
+	Project string    `json:"project"`
+	Tags    []string  `json:"tags"`
+}
+
+type updateTrackerRequest struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Name    string    `json:"name"`
+	Project string    `json:"project"`
+	Tags    []string  `json:"tags"`
+	Version uint32    `json:"version"`
+}
+
+type ProjectResponse struct {
+	Name string `json:"name"`
+}
+
+type TrackerResponse struct {
+	ID              uint64           `json:"id"`
+	Name            string           `json:"name"`
+	Start           string           `json:"start"`
+	End             *string          `json:"end"`
+	Running         bool             `json:"running"`
+	DurationSeconds int64            `json:"duration_seconds"`
+	Project         *ProjectResponse `json:"project"`
+	Tags            []string         `json:"tags"`
+	CreatedAt       string           `json:"created_at"`
+	UpdatedAt       string           `json:"updated_at"`
+	Version         uint32           `json:"version"`
+	// Overlaps lists the sessions the tracker was stored overlapping with
+	// under the warn overlap policy.
+	Overlaps      []uint64          `json:"overlaps,omitempty"`
+	LastHeartbeat *string           `json:"last_heartbeat,omitempty"`
+	AutoStopped   bool              `json:"auto_stopped"`
+	AutoStop      *AutoStopResponse `json:"auto_stop,omitempty"`
+}
+
+type AutoStopResponse struct {
+	Reason models.AutoStopReason `json:"reason"`
+	At     string                `json:"at"`
+}
+
+type TrackersResponse struct {
+	Trackers []TrackerResponse `json:"trackers"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	// Code tells apart the conflicts answered with 409.
+	Code     string                `json:"code,omitempty"`
+	Fields   []services.FieldError `json:"fields,omitempty"`
+	Overlaps []uint64              `json:"overlaps,omitempty"`
+}
+
+func (h TrackerHandler) GetTracker(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	tracker, err := h.service.GetTracker(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, h.fromDomain(tracker, location))
+}
+
+func (h TrackerHandler) ListTrackers(w http.ResponseWriter, r *http.Request) {
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var params services.ListTimeTracker
+
+	if r.FormValue("start_date") != "" || r.FormValue("end_date") != "" {
+		params.Start, err = time.Parse(time.RFC3339, r.FormValue("start_date"))
+		if err != nil {
+			h.writeError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		params.End, err = time.Parse(time.RFC3339, r.FormValue("end_date"))
+		if err != nil {
+			h.writeError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+	}
+
+	trackers, err := h.service.ListTrackers(r.Context(), params)
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	response := TrackersResponse{
+		Trackers: make([]TrackerResponse, 0, len(trackers)),
+	}
+	for _, tracker := range trackers {
+		response.Trackers = append(response.Trackers, h.fromDomain(tracker, location))
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h TrackerHandler) CreateTracker(w http.ResponseWriter, r *http.Request) {
+	var request createTrackerRequest
+	if err := readJSON(r, &request); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	tracker, err := h.service.CreateTracker(r.Context(), services.CreateTrackerParams{
+		Start:   request.Start,
+		Name:    request.Name,
+		Project: request.Project,
+		Tags:    request.Tags,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusCreated, h.fromDomain(tracker, location))
+}
+
+func (h TrackerHandler) UpdateTracker(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var request updateTrackerRequest
+	if err := readJSON(r, &request); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	location, err := requestLocation(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	tracker, err := h.service.UpdateTracker(r.Context(), services.UpdateTrackerParams{
+		ID:      id,
+		Start:   request.Start,
+		End:     request.End,
+		Name:    request.Name,
+		Project: request.Project,
+		Tags:    request.Tags,
+		Version: request.Version,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, h.fromDomain(tracker, location))
+}
+
+func (h TrackerHandler) DeleteTracker(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	version, err := queryVersion(r)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, err)
+
+		return
+	}
+
+	err = h.service.DeleteTracker(r.Context(), services.DeleteTrackerParams{
+		ID:      id,
+		Version: version,
+	})
+	if err != nil {
+		h.writeError(w, r, apierror.Status(err), err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h TrackerHandler) fromDomain(tracker models.TimeTracker, location *time.Location) TrackerResponse {
+	return trackerFromDomain(tracker, location, h.now())
+}
+
+// trackerFromDomain renders a tracker, running trackers are measured up to
+// now.
+func trackerFromDomain(tracker models.TimeTracker, location *time.Location, now time.Time) TrackerResponse {
+	response := TrackerResponse{
+		ID:              tracker.ID,
+		Name:            tracker.Name,
+		Start:           formatTime(tracker.Start, location),
+		Running:         tracker.IsRunning(),
+		DurationSeconds: int64(tracker.Duration(now) / time.Second),
+		Tags:            tracker.Tags,
+		CreatedAt:       formatTime(tracker.Meta.GetCreatedAt(), location),
+		UpdatedAt:       formatTime(tracker.Meta.GetUpdatedAt(), location),
+		Version:         tracker.Meta.GetVersion(),
+		Overlaps:        tracker.Overlapping,
+	}
+
+	if !tracker.End.IsZero() {
+		end := formatTime(tracker.End, location)
+		response.End = &end
+	}
+
+	if tracker.Project != "" {
+		response.Project = &ProjectResponse{Name: tracker.Project}
+	}
+
+	if response.Tags == nil {
+		response.Tags = make([]string, 0)
+	}
+
+	if !tracker.LastHeartbeat.IsZero() {
+		heartbeat := formatTime(tracker.LastHeartbeat, location)
+		response.LastHeartbeat = &heartbeat
+	}
+
+	if tracker.AutoStop != nil {
+		response.AutoStopped = true
+		response.AutoStop = &AutoStopResponse{
+			Reason: tracker.AutoStop.Reason,
+			At:     formatTime(tracker.AutoStop.At, location),
+		}
+	}
+
+	return response
+}
+
+func formatTime(t time.Time, location *time.Location) string {
+	return t.In(location).Format(time.RFC3339)
+}
+
+// requestLocation reads the optional tz query parameter, timestamps are
+// rendered in UTC otherwise.
+func requestLocation(r *http.Request) (*time.Location, error) {
+	tz := r.FormValue("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w invalid tz", err)
+	}
+
+	return location, nil
+}
+
+func queryVersion(r *http.Request) (uint32, error) {
+	if r.FormValue("version") == "" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseUint(r.FormValue("version"), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(version), nil
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
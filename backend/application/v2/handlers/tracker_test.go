@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pento/code-challenge/application/apierror"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// trackerService serves a fixed set of trackers and records the created
+// ones, updates fail with updateErr.
+type trackerService struct {
+	trackers  map[uint64]models.TimeTracker
+	created   []services.CreateTrackerParams
+	updateErr error
+}
+
+func (s *trackerService) GetTracker(_ context.Context, id uint64) (models.TimeTracker, error) {
+	tracker, ok := s.trackers[id]
+	if !ok {
+		return models.TimeTracker{}, services.ErrTrackerNotFound
+	}
+
+	return tracker, nil
+}
+
+func (s *trackerService) ListTrackers(context.Context, services.ListTimeTracker) ([]models.TimeTracker, error) {
+	trackers := make([]models.TimeTracker, 0, len(s.trackers))
+	for _, tracker := range s.trackers {
+		trackers = append(trackers, tracker)
+	}
+
+	return trackers, nil
+}
+
+func (s *trackerService) CreateTracker(_ context.Context, params services.CreateTrackerParams) (models.TimeTracker, error) {
+	s.created = append(s.created, params)
+
+	tracker := models.NewTimeTracker(uint64(len(s.trackers)+1), params.Start, time.Time{}, params.Name)
+	tracker.Project = params.Project
+	tracker.Tags = params.Tags
+
+	return tracker, nil
+}
+
+func (s *trackerService) UpdateTracker(context.Context, services.UpdateTrackerParams) (models.TimeTracker, error) {
+	return models.TimeTracker{}, s.updateErr
+}
+
+func (s *trackerService) DeleteTracker(context.Context, services.DeleteTrackerParams) error {
+	return nil
+}
+
+func newTrackerRouter(service TrackerService, now time.Time) *mux.Router {
+	handler := NewTrackerHandler(service, logrus.New())
+	handler.now = func() time.Time { return now }
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v2/tracker/{id}", handler.GetTracker).Methods("GET")
+	router.HandleFunc("/api/v2/tracker", handler.CreateTracker).Methods("POST")
+	router.HandleFunc("/api/v2/tracker/{id}", handler.UpdateTracker).Methods("PUT")
+
+	return router
+}
+
+func Test_TrackerHandler_GetTracker(t *testing.T) {
+
+	start := time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC)
+	now := start.Add(90 * time.Minute)
+
+	stopped := models.NewTimeTracker(1, start, start.Add(time.Hour), "code review")
+	stopped.Project = "pento"
+	stopped.Tags = []string{"review"}
+
+	running := models.NewTimeTracker(2, start, time.Time{}, "standup")
+
+	type testExpectation struct {
+		status int
+		body   map[string]interface{}
+	}
+
+	testCases := []struct {
+		description string
+		path        string
+		expected    testExpectation
+	}{
+		{
+			description: "when the tracker is stopped",
+			path:        "/api/v2/tracker/1?tz=Europe/Lisbon",
+			expected: testExpectation{
+				status: http.StatusOK,
+				body: map[string]interface{}{
+					"id":               float64(1),
+					"start":            "2021-05-03T10:00:00+01:00",
+					"end":              "2021-05-03T11:00:00+01:00",
+					"running":          false,
+					"duration_seconds": float64(3600),
+					"project":          map[string]interface{}{"name": "pento"},
+					"tags":             []interface{}{"review"},
+				},
+			},
+		},
+		{
+			description: "when the tracker is running",
+			path:        "/api/v2/tracker/2",
+			expected: testExpectation{
+				status: http.StatusOK,
+				body: map[string]interface{}{
+					"id":               float64(2),
+					"start":            "2021-05-03T09:00:00Z",
+					"end":              nil,
+					"running":          true,
+					"duration_seconds": float64(5400),
+					"project":          nil,
+					"tags":             []interface{}{},
+				},
+			},
+		},
+		{
+			description: "when the tracker doesn't exist",
+			path:        "/api/v2/tracker/3",
+			expected: testExpectation{
+				status: http.StatusNotFound,
+				body:   map[string]interface{}{"error": services.ErrTrackerNotFound.Error()},
+			},
+		},
+		{
+			description: "when the time zone is unknown",
+			path:        "/api/v2/tracker/1?tz=Mars/Olympus",
+			expected: testExpectation{
+				status: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			router := newTrackerRouter(&trackerService{
+				trackers: map[uint64]models.TimeTracker{1: stopped, 2: running},
+			}, now)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			g.Expect(recorder.Code).To(Equal(tc.expected.status), "should be the same status")
+
+			var body map[string]interface{}
+			g.Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed(), "should answer JSON")
+			for key, value := range tc.expected.body {
+				g.Expect(body).To(HaveKey(key), "should render "+key)
+				if value == nil {
+					g.Expect(body[key]).To(BeNil(), "should render "+key+" as null")
+
+					continue
+				}
+				g.Expect(body[key]).To(Equal(value), "should render "+key)
+			}
+		})
+	}
+}
+
+func Test_TrackerHandler_CreateTracker(t *testing.T) {
+	g := NewWithT(t)
+
+	service := &trackerService{}
+	router := newTrackerRouter(service, time.Date(2021, 5, 3, 10, 0, 0, 0, time.UTC))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v2/tracker", strings.NewReader(
+		`{"start":"2021-05-03T09:00:00Z","name":"code review","project":"pento","tags":["review"]}`,
+	)))
+
+	g.Expect(recorder.Code).To(Equal(http.StatusCreated), "should create the tracker")
+	g.Expect(service.created).To(Equal([]services.CreateTrackerParams{{
+		Start:   time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC),
+		Name:    "code review",
+		Project: "pento",
+		Tags:    []string{"review"},
+	}}), "should pass the project and tags on")
+
+	var body TrackerResponse
+	g.Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed(), "should answer JSON")
+	g.Expect(body.Running).To(BeTrue(), "should be running")
+	g.Expect(body.DurationSeconds).To(Equal(int64(3600)), "should measure up to now")
+	g.Expect(body.Project).To(Equal(&ProjectResponse{Name: "pento"}), "should nest the project")
+}
+
+func Test_TrackerHandler_UpdateTracker_conflict(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		err         error
+		expected    string
+	}{
+		{
+			description: "when the version is outdated",
+			err:         fmt.Errorf("%w failed to store tracker", services.ErrWrongVersion),
+			expected:    apierror.CodeWrongVersion,
+		},
+		{
+			description: "when the period is locked",
+			err:         fmt.Errorf("%w: week of 2021-05-03", services.ErrPeriodLocked),
+			expected:    apierror.CodePeriodLocked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			router := newTrackerRouter(&trackerService{updateErr: tc.err}, time.Now())
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/api/v2/tracker/1", strings.NewReader(`{"version":1}`)))
+
+			g.Expect(recorder.Code).To(Equal(http.StatusConflict), "should answer a conflict")
+
+			var body errorResponse
+			g.Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed(), "should answer JSON")
+			g.Expect(body.Code).To(Equal(tc.expected), "should tell the conflicts apart")
+		})
+	}
+}
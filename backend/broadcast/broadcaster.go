@@ -0,0 +1,172 @@
+package broadcast
+
+import (
+	"sync"
+	"time"
+
+	"pento/code-challenge/domain/tracker/models"
+)
+
+const subscriptionBuffer = 32
+
+// Filter decides whether an event is delivered to a subscription.
+type Filter func(event models.TrackerEvent) bool
+
+// Broadcaster fans tracker events out to in-process subscribers and keeps a
+// bounded history so reconnecting clients can resume from a known event id.
+// Ids start from the boot time in microseconds, so they keep growing across
+// restarts and ids of a previous process are recognized.
+type Broadcaster struct {
+	mu          sync.Mutex
+	epoch       uint64
+	lastID      uint64
+	history     []models.TrackerEvent
+	historySize int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+type Subscription struct {
+	events      chan models.TrackerEvent
+	filter      Filter
+	broadcaster *Broadcaster
+	once        sync.Once
+}
+
+func NewBroadcaster(historySize int) *Broadcaster {
+	epoch := uint64(time.Now().UnixNano() / int64(time.Microsecond))
+
+	return &Broadcaster{
+		epoch:       epoch,
+		lastID:      epoch,
+		history:     make([]models.TrackerEvent, 0, historySize),
+		historySize: historySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the next event id and delivers the event to every matching
+// subscriber. Subscribers that can't keep up are dropped, they are expected to
+// reconnect and resume with the last event id they have seen. It returns the
+// assigned id.
+func (b *Broadcaster) Publish(event models.TrackerEvent) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+
+	if b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			b.history = b.history[1:]
+		}
+		b.history = append(b.history, event)
+	}
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+
+	return event.ID
+}
+
+// Subscribe registers a new subscription and returns the events published
+// after lastEventID that are still in the history. The replay starts with a
+// reset event when some of them are gone: lastEventID is from a previous
+// process, unknown or older than the history.
+func (b *Broadcaster) Subscribe(lastEventID uint64, filter Filter) (*Subscription, []models.TrackerEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		events:      make(chan models.TrackerEvent, subscriptionBuffer),
+		filter:      filter,
+		broadcaster: b,
+	}
+	if b.closed {
+		close(sub.events)
+
+		return sub, make([]models.TrackerEvent, 0)
+	}
+	b.subscribers[sub] = struct{}{}
+
+	replay := make([]models.TrackerEvent, 0)
+	if lastEventID == 0 {
+		return sub, replay
+	}
+
+	if b.missed(lastEventID) {
+		replay = append(replay, models.TrackerEvent{ID: b.lastID, Type: models.EventReset, OccurredAt: time.Now()})
+
+		return sub, replay
+	}
+
+	for _, event := range b.history {
+		if event.ID <= lastEventID {
+			continue
+		}
+		if filter != nil && !filter(event) {
+			continue
+		}
+		replay = append(replay, event)
+	}
+
+	return sub, replay
+}
+
+// Close ends every subscription so streaming clients disconnect, it is meant
+// to be called when the server shuts down.
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+}
+
+// missed reports whether events published after lastEventID were lost.
+func (b *Broadcaster) missed(lastEventID uint64) bool {
+	if lastEventID < b.epoch || lastEventID > b.lastID {
+		return true
+	}
+
+	if lastEventID == b.lastID {
+		return false
+	}
+
+	return len(b.history) == 0 || b.history[0].ID > lastEventID+1
+}
+
+func (b *Broadcaster) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+// Events returns the channel of live events, it is closed when the
+// subscription is closed or dropped.
+func (s *Subscription) Events() <-chan models.TrackerEvent {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broadcaster.mu.Lock()
+		defer s.broadcaster.mu.Unlock()
+
+		s.broadcaster.remove(s)
+	})
+}
@@ -0,0 +1,145 @@
+package broadcast
+
+import (
+	"testing"
+
+	"pento/code-challenge/domain/tracker/models"
+
+	. "github.com/onsi/gomega"
+)
+
+func publishN(b *Broadcaster, n int) {
+	for i := 0; i < n; i++ {
+		b.Publish(models.NewTrackerEvent(models.EventCreated, models.TimeTracker{ID: uint64(i + 1)}))
+	}
+}
+
+func Test_Broadcaster_Publish(t *testing.T) {
+	g := NewWithT(t)
+
+	b := NewBroadcaster(4)
+	sub, replay := b.Subscribe(0, nil)
+	defer sub.Close()
+
+	g.Expect(replay).To(BeEmpty())
+
+	publishN(b, 2)
+
+	first := <-sub.Events()
+	second := <-sub.Events()
+	g.Expect(first.ID).To(BeNumerically(">", b.epoch))
+	g.Expect(second.ID).To(Equal(first.ID + 1))
+	g.Expect(second.Tracker.ID).To(Equal(uint64(2)))
+
+	id := b.Publish(models.NewTrackerEvent(models.EventStopped, models.TimeTracker{ID: 2}))
+	g.Expect((<-sub.Events()).ID).To(Equal(id))
+}
+
+func Test_Broadcaster_Subscribe(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		// lastEventID is relative to the epoch of the broadcaster, -1 is an id
+		// of a previous process.
+		lastEventID int
+		filter      Filter
+		expected    []models.EventType
+		trackers    []uint64
+	}{
+		{
+			description: "when the client is up to date",
+			lastEventID: 6,
+			expected:    []models.EventType{},
+		},
+		{
+			description: "when the missed events are in the history",
+			lastEventID: 4,
+			expected:    []models.EventType{models.EventCreated, models.EventCreated},
+			trackers:    []uint64{5, 6},
+		},
+		{
+			description: "when the missed events are filtered",
+			lastEventID: 3,
+			filter:      func(event models.TrackerEvent) bool { return event.Tracker.ID == 5 },
+			expected:    []models.EventType{models.EventCreated},
+			trackers:    []uint64{5},
+		},
+		{
+			description: "when the oldest missed event left the history",
+			lastEventID: 1,
+			expected:    []models.EventType{models.EventReset},
+		},
+		{
+			description: "when the id is from a previous process",
+			lastEventID: -1,
+			expected:    []models.EventType{models.EventReset},
+		},
+		{
+			description: "when the id wasn't issued yet",
+			lastEventID: 10,
+			expected:    []models.EventType{models.EventReset},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			b := NewBroadcaster(4)
+			publishN(b, 6)
+
+			sub, replay := b.Subscribe(uint64(int64(b.epoch)+int64(tc.lastEventID)), tc.filter)
+			defer sub.Close()
+
+			types := make([]models.EventType, 0, len(replay))
+			trackers := make([]uint64, 0, len(replay))
+			for _, event := range replay {
+				types = append(types, event.Type)
+				if event.Type != models.EventReset {
+					trackers = append(trackers, event.Tracker.ID)
+				}
+			}
+
+			g.Expect(types).To(Equal(tc.expected))
+			if tc.trackers != nil {
+				g.Expect(trackers).To(Equal(tc.trackers))
+			}
+			if len(replay) > 0 && replay[0].Type == models.EventReset {
+				g.Expect(replay[0].ID).To(Equal(b.lastID))
+			}
+		})
+	}
+}
+
+func Test_Broadcaster_dropsSlowSubscribers(t *testing.T) {
+	g := NewWithT(t)
+
+	b := NewBroadcaster(0)
+	sub, _ := b.Subscribe(0, nil)
+
+	publishN(b, subscriptionBuffer+1)
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+
+	g.Expect(received).To(Equal(subscriptionBuffer))
+	sub.Close()
+}
+
+func Test_Broadcaster_Close(t *testing.T) {
+	g := NewWithT(t)
+
+	b := NewBroadcaster(4)
+	sub, _ := b.Subscribe(0, nil)
+
+	b.Close()
+
+	_, open := <-sub.Events()
+	g.Expect(open).To(BeFalse())
+
+	late, _ := b.Subscribe(0, nil)
+	_, open = <-late.Events()
+	g.Expect(open).To(BeFalse())
+}
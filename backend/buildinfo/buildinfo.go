@@ -0,0 +1,27 @@
+package buildinfo
+
+import "runtime"
+
+// Version, Commit and Date are set at build time with
+// -ldflags "-X pento/code-challenge/buildinfo.Version=..."
+var (
+	Version = "dev"
+	Commit  = "unknown"
+	Date    = "unknown"
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+}
+
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+}
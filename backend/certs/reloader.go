@@ -0,0 +1,182 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Reloader keeps the serving certificate and the client CA bundle in sync
+// with their files, so rotated certificates are picked up without a
+// restart.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	logger       logrus.FieldLogger
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	modTimes    map[string]time.Time
+}
+
+// NewReloader loads the files once, an empty clientCAFile disables client
+// certificate authentication.
+func NewReloader(certFile, keyFile, clientCAFile string, logger logrus.FieldLogger) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		logger:       logger,
+		modTimes:     make(map[string]time.Time),
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload reads the files again. A broken pair leaves the current
+// certificate in place.
+func (r *Reloader) Reload() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("%w failed to load certificate", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		bundle, err := ioutil.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("%w failed to read client CA bundle", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(bundle) {
+			return errors.New("client CA bundle holds no certificate")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+
+	return nil
+}
+
+// Watch reloads the files every interval once one of them changed, until
+// ctx is done.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+
+			if err := r.Reload(); err != nil {
+				r.logger.WithError(err).Error("failed to reload TLS certificates, keeping the current ones")
+
+				continue
+			}
+
+			r.logger.Info("reloaded TLS certificates")
+		}
+	}
+}
+
+// TLSConfig returns a copy of base serving the current certificate and,
+// when a client CA bundle is configured, authenticating clients against the
+// current bundle with clientAuth.
+func (r *Reloader) TLSConfig(base *tls.Config, clientAuth tls.ClientAuthType) *tls.Config {
+	config := base.Clone()
+	config.GetCertificate = r.getCertificate
+
+	if r.clientCAFile == "" {
+		return config
+	}
+
+	config.ClientAuth = clientAuth
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		perConnection := config.Clone()
+		perConnection.GetConfigForClient = nil
+
+		r.mu.RLock()
+		perConnection.ClientCAs = r.clientCAs
+		r.mu.RUnlock()
+
+		return perConnection, nil
+	}
+
+	return config
+}
+
+func (r *Reloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.certificate, nil
+}
+
+func (r *Reloader) changed() bool {
+	modTimes, err := r.stat()
+	if err != nil {
+		// a file missing in the middle of a rotation, try again later
+		r.logger.WithError(err).Warn("failed to check TLS certificates")
+
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Reloader) stat() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+
+	for _, file := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("%w failed to stat %s", err, file)
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
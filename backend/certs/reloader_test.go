@@ -0,0 +1,228 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+type testCertificate struct {
+	certPEM []byte
+	keyPEM  []byte
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+}
+
+// newTestCertificate issues a certificate for commonName, self signed when
+// issuer is nil.
+func newTestCertificate(t *testing.T, commonName string, isCA bool, issuer *testCertificate) testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+
+	parent, signer := template, key
+	if issuer != nil {
+		parent, signer = issuer.cert, issuer.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testCertificate{
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		cert:    cert,
+		key:     key,
+	}
+}
+
+func writeFile(t *testing.T, path string, content []byte, modTime time.Time) {
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Reloader_Watch(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		rotate      func(t *testing.T, certFile, keyFile string)
+		expectedCN  string
+	}{
+		{
+			description: "when the certificate is rotated",
+			rotate: func(t *testing.T, certFile, keyFile string) {
+				rotated := newTestCertificate(t, "rotated", false, nil)
+				later := time.Now().Add(time.Minute)
+				writeFile(t, keyFile, rotated.keyPEM, later)
+				writeFile(t, certFile, rotated.certPEM, later)
+			},
+			expectedCN: "rotated",
+		},
+		{
+			description: "when the rotated certificate is broken",
+			rotate: func(t *testing.T, certFile, keyFile string) {
+				writeFile(t, certFile, []byte("not a certificate"), time.Now().Add(time.Minute))
+			},
+			expectedCN: "initial",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir := t.TempDir()
+			certFile := filepath.Join(dir, "tls.crt")
+			keyFile := filepath.Join(dir, "tls.key")
+
+			initial := newTestCertificate(t, "initial", false, nil)
+			writeFile(t, certFile, initial.certPEM, time.Now())
+			writeFile(t, keyFile, initial.keyPEM, time.Now())
+
+			reloader, err := NewReloader(certFile, keyFile, "", logrus.New())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go reloader.Watch(ctx, 10*time.Millisecond)
+
+			tc.rotate(t, certFile, keyFile)
+
+			config := reloader.TLSConfig(&tls.Config{}, tls.NoClientCert)
+			commonName := func() string {
+				certificate, err := config.GetCertificate(nil)
+				g.Expect(err).ToNot(HaveOccurred())
+
+				parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+				g.Expect(err).ToNot(HaveOccurred())
+
+				return parsed.Subject.CommonName
+			}
+
+			g.Eventually(commonName).Should(Equal(tc.expectedCN))
+			g.Consistently(commonName, 100*time.Millisecond).Should(Equal(tc.expectedCN))
+		})
+	}
+}
+
+func Test_Reloader_ClientAuthentication(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		clientCA    bool
+		expectedErr bool
+	}{
+		{
+			description: "when the client presents a certificate signed by the CA",
+			clientCA:    true,
+		},
+		{
+			description: "when the client presents no certificate",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir := t.TempDir()
+			certFile := filepath.Join(dir, "tls.crt")
+			keyFile := filepath.Join(dir, "tls.key")
+			caFile := filepath.Join(dir, "ca.crt")
+
+			ca := newTestCertificate(t, "ca", true, nil)
+			serverCert := newTestCertificate(t, "server", false, &ca)
+			writeFile(t, certFile, serverCert.certPEM, time.Now())
+			writeFile(t, keyFile, serverCert.keyPEM, time.Now())
+			writeFile(t, caFile, ca.certPEM, time.Now())
+
+			reloader, err := NewReloader(certFile, keyFile, caFile, logrus.New())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(r.Proto))
+			}))
+			server.TLS = reloader.TLSConfig(&tls.Config{
+				MinVersion: tls.VersionTLS12,
+				NextProtos: []string{"h2", "http/1.1"},
+			}, tls.RequireAndVerifyClientCert)
+			server.EnableHTTP2 = true
+			server.StartTLS()
+			defer server.Close()
+
+			roots := x509.NewCertPool()
+			roots.AddCert(ca.cert)
+			clientTLS := &tls.Config{RootCAs: roots}
+
+			if tc.clientCA {
+				clientCert := newTestCertificate(t, "client", false, &ca)
+				pair, err := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+				g.Expect(err).ToNot(HaveOccurred())
+				clientTLS.Certificates = []tls.Certificate{pair}
+			}
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS, ForceAttemptHTTP2: true}}
+
+			resp, err := client.Get(server.URL)
+			if tc.expectedErr {
+				g.Expect(err).To(HaveOccurred())
+
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(body)).To(Equal("HTTP/2.0"))
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const batchPath = "/api/v1/tracker/batch"
+
+type BatchOperationType string
+
+const (
+	BatchCreate BatchOperationType = "create"
+	BatchUpdate BatchOperationType = "update"
+	BatchDelete BatchOperationType = "delete"
+)
+
+const (
+	BatchStatusApplied     = "applied"
+	BatchStatusFailed      = "failed"
+	BatchStatusRolledBack  = "rolled_back"
+	BatchStatusNotExecuted = "not_executed"
+)
+
+type BatchOperation struct {
+	Op      BatchOperationType `json:"op"`
+	ID      uint64             `json:"id,omitempty"`
+	Start   time.Time          `json:"start"`
+	End     time.Time          `json:"end"`
+	Name    string             `json:"name,omitempty"`
+	Project string             `json:"project,omitempty"`
+	Tags    []string           `json:"tags"`
+	Version uint32             `json:"version,omitempty"`
+}
+
+type BatchParams struct {
+	Operations []BatchOperation
+	// BestEffort applies every operation on its own instead of rolling the
+	// whole batch back on the first failure.
+	BestEffort     bool
+	IdempotencyKey string
+}
+
+// BatchItem is the outcome of one operation. Trackers come from the v1
+// representation, which has no project, tags or duration of running trackers.
+type BatchItem struct {
+	Index      int
+	Op         BatchOperationType
+	Status     string
+	StatusCode int
+	Tracker    *Tracker
+	Error      string
+	// ErrorCode tells apart the conflicts, like wrong_version.
+	ErrorCode string
+}
+
+type BatchResult struct {
+	Mode  string
+	Items []BatchItem
+	// Replayed is set when the server answered from its idempotency store.
+	Replayed bool
+}
+
+type batchRequest struct {
+	Mode       string           `json:"mode"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+type batchItemResponse struct {
+	Index     int                `json:"index"`
+	Op        BatchOperationType `json:"op"`
+	Status    string             `json:"status"`
+	Code      int                `json:"code"`
+	ErrorCode string             `json:"error_code"`
+	Tracker   *v1TrackerResponse `json:"tracker"`
+	Error     string             `json:"error"`
+}
+
+type batchResponse struct {
+	Mode    string              `json:"mode"`
+	Results []batchItemResponse `json:"results"`
+}
+
+type v1TrackerResponse struct {
+	ID        uint64     `json:"id"`
+	Start     time.Time  `json:"start"`
+	End       *time.Time `json:"end"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Version   uint32     `json:"version"`
+}
+
+// Batch runs the operations in one request. A failing atomic batch returns
+// both the per item result and an APIError with the status of the failed
+// item.
+func (c *Client) Batch(ctx context.Context, params BatchParams) (BatchResult, error) {
+	body := batchRequest{
+		Mode:       "atomic",
+		Operations: params.Operations,
+	}
+	if params.BestEffort {
+		body.Mode = "best_effort"
+	}
+
+	resp, err := c.send(ctx, request{
+		method:         http.MethodPost,
+		path:           batchPath,
+		body:           body,
+		idempotencyKey: params.IdempotencyKey,
+	})
+	if err != nil {
+		return BatchResult{}, err
+	}
+	defer resp.Body.Close()
+
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return BatchResult{}, fmt.Errorf("%w failed to read response", err)
+	}
+
+	var apiErr *APIError
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr = newAPIError(resp, payload)
+	}
+
+	// requests rejected before running carry no results
+	var decoded batchResponse
+	err = json.Unmarshal(payload, &decoded)
+	if apiErr != nil && (err != nil || decoded.Results == nil) {
+		return BatchResult{}, apiErr
+	}
+	if err != nil {
+		return BatchResult{}, fmt.Errorf("%w failed to decode batch response", err)
+	}
+
+	result := BatchResult{
+		Mode:     decoded.Mode,
+		Items:    make([]BatchItem, 0, len(decoded.Results)),
+		Replayed: resp.Header.Get(idempotentReplayHeader) == "true",
+	}
+	for _, item := range decoded.Results {
+		converted := BatchItem{
+			Index:      item.Index,
+			Op:         item.Op,
+			Status:     item.Status,
+			StatusCode: item.Code,
+			Error:      item.Error,
+			ErrorCode:  item.ErrorCode,
+		}
+		if apiErr != nil && item.Status == BatchStatusFailed && apiErr.Code == "" {
+			apiErr.Code = item.ErrorCode
+		}
+		if item.Tracker != nil {
+			tracker := item.Tracker.toTracker()
+			converted.Tracker = &tracker
+		}
+		result.Items = append(result.Items, converted)
+	}
+
+	if apiErr != nil {
+		return result, apiErr
+	}
+
+	return result, nil
+}
+
+func (r v1TrackerResponse) toTracker() Tracker {
+	tracker := Tracker{
+		ID:        r.ID,
+		Name:      r.Name,
+		Start:     r.Start,
+		Running:   r.End == nil,
+		Tags:      make([]string, 0),
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+		Version:   r.Version,
+	}
+
+	if r.End != nil {
+		tracker.End = *r.End
+		tracker.Duration = tracker.End.Sub(tracker.Start).Truncate(time.Second)
+	}
+
+	return tracker
+}
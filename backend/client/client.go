@@ -0,0 +1,266 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout          = 30 * time.Second
+	defaultConflictRetries  = 3
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotentReplayHeader  = "Idempotent-Replayed"
+	requestIDResponseHeader = "X-Request-ID"
+)
+
+// The codes the API sets on errors to tell apart the conflicts answered with
+// 409.
+const (
+	codeWrongVersion   = "wrong_version"
+	codeOverlap        = "overlap"
+	codeTrackerRunning = "tracker_running"
+	codeNotRunning     = "not_running"
+	codePeriodLocked   = "period_locked"
+)
+
+// The errors APIError matches with errors.Is, they mirror the ones of the
+// tracker service.
+var (
+	ErrTrackerNotFound = errors.New("tracker not found")
+	ErrWrongVersion    = errors.New("wrong version provided")
+	ErrOverlap         = errors.New("tracker overlaps another session")
+	ErrTrackerRunning  = errors.New("another tracker is already running")
+	ErrPeriodLocked    = errors.New("period is locked by an approved timesheet")
+	ErrInvalidRequest  = errors.New("invalid request")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrUnavailable     = errors.New("service unavailable")
+)
+
+// Client talks to the tracker REST API. It is safe for concurrent use.
+type Client struct {
+	baseURL         *url.URL
+	token           string
+	httpClient      *http.Client
+	conflictRetries int
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient replaces the default http.Client, which times out after 30s.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken sends token as a bearer token with every request.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithConflictRetries sets how many times ModifyTracker and StopTracker
+// reload a tracker and try again after a version conflict, 3 by default.
+func WithConflictRetries(retries int) Option {
+	return func(c *Client) {
+		c.conflictRetries = retries
+	}
+}
+
+// New creates a client for the API served at baseURL, e.g.
+// http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("%w invalid API url", err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("API url %q must be http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:         parsed,
+		httpClient:      &http.Client{Timeout: defaultTimeout},
+		conflictRetries: defaultConflictRetries,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// APIError is returned for every non 2xx response.
+type APIError struct {
+	StatusCode int
+	Message    string
+	// Code tells apart the conflicts, like wrong_version or period_locked.
+	Code string
+	// RequestID is the X-Request-ID the server logged the request with.
+	RequestID string
+	// RetryAfter is set on rate limited responses.
+	RetryAfter time.Duration
+	// Overlaps lists the sessions a rejected tracker overlaps.
+	Overlaps []uint64
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("tracker API responded %d: %s", e.StatusCode, e.Message)
+}
+
+// Is matches the status code against the package errors, conflicts are told
+// apart by their code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrTrackerNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrWrongVersion:
+		return e.isConflict(codeWrongVersion)
+	case ErrOverlap:
+		return e.isConflict(codeOverlap) || e.StatusCode == http.StatusConflict && len(e.Overlaps) > 0
+	case ErrTrackerRunning:
+		return e.isConflict(codeTrackerRunning)
+	case ErrNotRunning:
+		return e.isConflict(codeNotRunning)
+	case ErrPeriodLocked:
+		return e.isConflict(codePeriodLocked)
+	case ErrInvalidRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable
+	default:
+		return false
+	}
+}
+
+func (e *APIError) isConflict(code string) bool {
+	return e.StatusCode == http.StatusConflict && e.Code == code
+}
+
+type errorResponse struct {
+	Error    string   `json:"error"`
+	Code     string   `json:"code"`
+	Overlaps []uint64 `json:"overlaps"`
+}
+
+// request describes a call, a nil body sends no payload and a nil result
+// discards the response.
+type request struct {
+	method         string
+	path           string
+	query          url.Values
+	body           interface{}
+	idempotencyKey string
+	accept         string
+	lastEventID    uint64
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, v interface{}) error {
+	return c.call(ctx, request{method: method, path: path, query: query, body: body}, v)
+}
+
+func (c *Client) call(ctx context.Context, r request, v interface{}) error {
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w failed to read response", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(resp, payload)
+	}
+
+	if v == nil || len(payload) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("%w failed to decode response", err)
+	}
+
+	return nil
+}
+
+// send issues the request and hands back the response unread, the caller
+// closes its body.
+func (c *Client) send(ctx context.Context, r request) (*http.Response, error) {
+	endpoint := *c.baseURL
+	endpoint.Path += r.path
+	endpoint.RawQuery = r.query.Encode()
+
+	var reader io.Reader = http.NoBody
+	if r.body != nil {
+		encoded, err := json.Marshal(r.body)
+		if err != nil {
+			return nil, fmt.Errorf("%w failed to encode request", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, endpoint.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to build request", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if r.accept != "" {
+		req.Header.Set("Accept", r.accept)
+	}
+	if r.body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.idempotencyKey != "" {
+		req.Header.Set(idempotencyKeyHeader, r.idempotencyKey)
+	}
+	if r.lastEventID != 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(r.lastEventID, 10))
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+func newAPIError(resp *http.Response, payload []byte) *APIError {
+	var body errorResponse
+	if err := json.Unmarshal(payload, &body); err != nil || body.Error == "" {
+		body.Error = strings.TrimSpace(string(payload))
+	}
+	if body.Error == "" {
+		body.Error = http.StatusText(resp.StatusCode)
+	}
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    body.Error,
+		Code:       body.Code,
+		RequestID:  resp.Header.Get(requestIDResponseHeader),
+		Overlaps:   body.Overlaps,
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return apiErr
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func Test_APIError_Is(t *testing.T) {
+
+	type testExpectation struct {
+		target     error
+		retryAfter time.Duration
+	}
+
+	testCases := []struct {
+		description string
+		status      int
+		body        string
+		headers     map[string]string
+		expected    testExpectation
+	}{
+		{
+			description: "when the tracker is missing",
+			status:      http.StatusNotFound,
+			body:        `{"error": "tracker not found"}`,
+			expected:    testExpectation{target: ErrTrackerNotFound},
+		},
+		{
+			description: "when the version is stale",
+			status:      http.StatusConflict,
+			body:        `{"error": "wrong version provided", "code": "wrong_version"}`,
+			expected:    testExpectation{target: ErrWrongVersion},
+		},
+		{
+			description: "when the period is locked",
+			status:      http.StatusConflict,
+			body:        `{"error": "period is locked by an approved timesheet: week of 2021-05-03", "code": "period_locked"}`,
+			expected:    testExpectation{target: ErrPeriodLocked},
+		},
+		{
+			description: "when another tracker is running",
+			status:      http.StatusConflict,
+			body:        `{"error": "another tracker is already running", "code": "tracker_running"}`,
+			expected:    testExpectation{target: ErrTrackerRunning},
+		},
+		{
+			description: "when the tracker overlaps other sessions",
+			status:      http.StatusConflict,
+			body:        `{"error": "tracker overlaps another session: overlaps trackers 3", "code": "overlap", "overlaps": [3]}`,
+			expected:    testExpectation{target: ErrOverlap},
+		},
+		{
+			description: "when the request is invalid",
+			status:      http.StatusBadRequest,
+			expected:    testExpectation{target: ErrInvalidRequest},
+		},
+		{
+			description: "when the client is rate limited",
+			status:      http.StatusTooManyRequests,
+			headers:     map[string]string{"Retry-After": "7"},
+			expected:    testExpectation{target: ErrRateLimited, retryAfter: 7 * time.Second},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tc.headers {
+					w.Header().Set(key, value)
+				}
+				w.Header().Set("X-Request-ID", "req-1")
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+
+			_, err := c.GetTracker(context.Background(), 1)
+
+			g.Expect(errors.Is(err, tc.expected.target)).To(BeTrue())
+			g.Expect(errors.Is(err, ErrWrongVersion)).To(Equal(tc.expected.target == ErrWrongVersion), "should only retry version conflicts")
+
+			var apiErr *APIError
+			g.Expect(errors.As(err, &apiErr)).To(BeTrue())
+			g.Expect(apiErr.StatusCode).To(Equal(tc.status))
+			g.Expect(apiErr.RequestID).To(Equal("req-1"))
+			g.Expect(apiErr.RetryAfter).To(Equal(tc.expected.retryAfter))
+		})
+	}
+}
+
+func Test_Client_Readiness(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Path).To(Equal("/readyz"))
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status": "unavailable", "checks": {"database": "connection refused"}}`))
+	}))
+
+	health, err := c.Readiness(context.Background())
+
+	g.Expect(errors.Is(err, ErrUnavailable)).To(BeTrue())
+	g.Expect(health.Checks).To(HaveKeyWithValue("database", "connection refused"))
+}
+
+func Test_Client_Token(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.Header.Get("Authorization")).To(Equal("Bearer secret"))
+
+		w.Write([]byte(`{"build": {"version": "1.2.0"}, "uptime_seconds": 12}`))
+	}), WithToken("secret"))
+
+	status, err := c.Status(context.Background())
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status.Build.Version).To(Equal("1.2.0"))
+	g.Expect(status.UptimeSeconds).To(Equal(int64(12)))
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const eventsPath = "/api/v1/tracker/events"
+
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventStopped EventType = "stopped"
+	EventDeleted EventType = "deleted"
+	// EventReset is sent instead of the replay when the events after
+	// LastEventID are gone, trackers have to be reloaded.
+	EventReset EventType = "reset"
+)
+
+// Event is a tracker change, Tracker only carries the id of deleted
+// trackers and is empty for reset events.
+type Event struct {
+	ID         uint64
+	Type       EventType
+	Tracker    Tracker
+	OccurredAt time.Time
+}
+
+type EventsParams struct {
+	// TrackerID limits the stream to one tracker when set.
+	TrackerID uint64
+	// LastEventID resumes a stream, the server replays the events after it
+	// that are still in its history.
+	LastEventID uint64
+}
+
+// EventStream reads the server sent events of the tracker stream. The
+// stream runs until the context is done, Close is called or the server
+// ends it.
+type EventStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	current Event
+	err     error
+}
+
+type eventResponse struct {
+	Type       EventType         `json:"type"`
+	Tracker    v1TrackerResponse `json:"tracker"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// Events opens the event stream. The client HTTP timeout applies to the
+// whole stream, use WithHTTPClient with a client without timeout for long
+// lived streams.
+func (c *Client) Events(ctx context.Context, params EventsParams) (*EventStream, error) {
+	query := url.Values{}
+	if params.TrackerID != 0 {
+		query.Set("tracker_id", strconv.FormatUint(params.TrackerID, 10))
+	}
+
+	resp, err := c.send(ctx, request{
+		method:      http.MethodGet,
+		path:        eventsPath,
+		query:       query,
+		accept:      "text/event-stream",
+		lastEventID: params.LastEventID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		payload, _ := ioutil.ReadAll(resp.Body)
+
+		return nil, newAPIError(resp, payload)
+	}
+
+	return &EventStream{
+		body:    resp.Body,
+		scanner: bufio.NewScanner(resp.Body),
+	}, nil
+}
+
+// Next blocks until the next event arrives, it returns false once the stream
+// ended.
+func (s *EventStream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	var (
+		id        uint64
+		eventType string
+		data      strings.Builder
+	)
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+
+		if line == "" {
+			if data.Len() == 0 {
+				continue
+			}
+
+			event, err := decodeEvent(id, eventType, data.String())
+			if err != nil {
+				s.err = err
+
+				return false
+			}
+			s.current = event
+
+			return true
+		}
+
+		// comments such as keep-alives
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+
+		switch field {
+		case "id":
+			id, _ = strconv.ParseUint(value, 10, 64)
+		case "event":
+			eventType = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+	}
+
+	s.err = s.scanner.Err()
+
+	return false
+}
+
+func (s *EventStream) Event() Event {
+	return s.current
+}
+
+// Err returns the error that ended the stream, nil when the server closed it.
+func (s *EventStream) Err() error {
+	return s.err
+}
+
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
+
+func decodeEvent(id uint64, eventType, data string) (Event, error) {
+	var response eventResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return Event{}, fmt.Errorf("%w failed to decode event %d", err, id)
+	}
+
+	event := Event{
+		ID:         id,
+		Type:       response.Type,
+		Tracker:    response.Tracker.toTracker(),
+		OccurredAt: response.OccurredAt,
+	}
+	if event.Type == "" {
+		event.Type = EventType(eventType)
+	}
+
+	return event, nil
+}
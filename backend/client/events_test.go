@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_Client_Events(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Path).To(Equal(eventsPath))
+		g.Expect(r.URL.Query().Get("tracker_id")).To(Equal("4"))
+		g.Expect(r.Header.Get("Last-Event-ID")).To(Equal("10"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 11\nevent: created\n"+
+			`data: {"type": "created", "tracker": {"id": 4, "name": "review", "start": "2021-05-03T09:00:00Z", "end": null, "version": 1}, "occurred_at": "2021-05-03T09:00:01Z"}`+"\n\n")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "id: 12\nevent: stopped\n"+
+			`data: {"type": "stopped", "tracker": {"id": 4, "name": "review", "start": "2021-05-03T09:00:00Z", "end": "2021-05-03T10:00:00Z", "version": 2}, "occurred_at": "2021-05-03T10:00:01Z"}`+"\n\n")
+	}))
+
+	stream, err := c.Events(context.Background(), EventsParams{TrackerID: 4, LastEventID: 10})
+	g.Expect(err).ToNot(HaveOccurred())
+	defer stream.Close()
+
+	events := make([]Event, 0)
+	for stream.Next() {
+		events = append(events, stream.Event())
+	}
+
+	g.Expect(stream.Err()).ToNot(HaveOccurred())
+	g.Expect(events).To(HaveLen(2))
+
+	g.Expect(events[0].ID).To(Equal(uint64(11)))
+	g.Expect(events[0].Type).To(Equal(EventCreated))
+	g.Expect(events[0].Tracker.Running).To(BeTrue())
+
+	g.Expect(events[1].ID).To(Equal(uint64(12)))
+	g.Expect(events[1].Type).To(Equal(EventStopped))
+	g.Expect(events[1].Tracker.Running).To(BeFalse())
+	g.Expect(events[1].Tracker.Version).To(Equal(uint32(2)))
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+}
+
+type PoolStats struct {
+	MaxOpenConnections int           `json:"max_open_connections"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"wait_count"`
+	WaitDuration       time.Duration `json:"wait_duration_ns"`
+	MaxIdleClosed      int64         `json:"max_idle_closed"`
+	MaxLifetimeClosed  int64         `json:"max_lifetime_closed"`
+}
+
+type Migration struct {
+	Current  int    `json:"current"`
+	Expected int    `json:"expected"`
+	Status   string `json:"status"`
+	Error    string `json:"error"`
+}
+
+type Status struct {
+	Build         BuildInfo `json:"build"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+	Pool          PoolStats `json:"pool"`
+	Migration     Migration `json:"migration"`
+}
+
+// Liveness calls /healthz.
+func (c *Client) Liveness(ctx context.Context) (Health, error) {
+	return c.health(ctx, "/healthz")
+}
+
+// Readiness calls /readyz, an unready server returns the failing checks
+// along with an error matching ErrUnavailable.
+func (c *Client) Readiness(ctx context.Context) (Health, error) {
+	return c.health(ctx, "/readyz")
+}
+
+// Status calls /debug/status, which needs the client token to match the
+// server debug token.
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	var status Status
+	if err := c.do(ctx, http.MethodGet, "/debug/status", nil, nil, &status); err != nil {
+		return Status{}, err
+	}
+
+	return status, nil
+}
+
+// Metrics returns the Prometheus exposition served at /metrics.
+func (c *Client) Metrics(ctx context.Context) (string, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: "/metrics", accept: "text/plain"})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w failed to read metrics", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp, payload)
+	}
+
+	return string(payload), nil
+}
+
+func (c *Client) health(ctx context.Context, path string) (Health, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: path})
+	if err != nil {
+		return Health{}, err
+	}
+	defer resp.Body.Close()
+
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Health{}, fmt.Errorf("%w failed to read health", err)
+	}
+
+	var health Health
+	decodeErr := json.Unmarshal(payload, &health)
+
+	if resp.StatusCode != http.StatusOK {
+		return health, newAPIError(resp, payload)
+	}
+	if decodeErr != nil {
+		return Health{}, fmt.Errorf("%w failed to decode health", decodeErr)
+	}
+
+	return health, nil
+}
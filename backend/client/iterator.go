@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultWindow = 7 * 24 * time.Hour
+
+type IterateParams struct {
+	// Start and End bound the trackers by start time, the iterator returns
+	// every tracker in a single page when both are zero.
+	Start time.Time
+	End   time.Time
+	// Window is the time span fetched per page, a week by default.
+	Window time.Duration
+}
+
+// TrackerIterator walks the trackers of a time range page by page, only
+// fetching the next page once the current one is consumed:
+//
+//	it := c.IterateTrackers(ctx, client.IterateParams{Start: from, End: to})
+//	for it.Next() {
+//		tracker := it.Tracker()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type TrackerIterator struct {
+	ctx    context.Context
+	client *Client
+	params IterateParams
+
+	cursor  time.Time
+	page    []Tracker
+	current Tracker
+	done    bool
+	err     error
+}
+
+func (c *Client) IterateTrackers(ctx context.Context, params IterateParams) *TrackerIterator {
+	it := &TrackerIterator{
+		ctx:    ctx,
+		client: c,
+		params: params,
+		cursor: params.Start,
+	}
+
+	if params.Window <= 0 {
+		it.params.Window = defaultWindow
+	}
+
+	if params.Start.IsZero() != params.End.IsZero() {
+		it.err = errors.New("iterating trackers needs both a start and an end")
+	} else if params.End.Before(params.Start) {
+		it.err = errors.New("iterating trackers needs a start before the end")
+	}
+
+	return it
+}
+
+// Next advances to the next tracker, it returns false once the range is
+// exhausted or a page failed to load.
+func (it *TrackerIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.err != nil || it.done {
+			return false
+		}
+
+		it.page, it.err = it.fetch()
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+
+	return true
+}
+
+func (it *TrackerIterator) Tracker() Tracker {
+	return it.current
+}
+
+func (it *TrackerIterator) Err() error {
+	return it.err
+}
+
+func (it *TrackerIterator) fetch() ([]Tracker, error) {
+	if it.params.Start.IsZero() {
+		it.done = true
+
+		return it.client.ListTrackers(it.ctx, ListParams{})
+	}
+
+	start := it.cursor
+	end := start.Add(it.params.Window)
+	last := !end.Before(it.params.End)
+	if last {
+		end = it.params.End
+		it.done = true
+	}
+	it.cursor = end
+
+	trackers, err := it.client.ListTrackers(it.ctx, ListParams{Start: start, End: end})
+	if err != nil {
+		return nil, err
+	}
+
+	if last {
+		return trackers, nil
+	}
+
+	// the server filter includes both bounds, a tracker started right on the
+	// boundary belongs to the next page.
+	page := trackers[:0]
+	for _, tracker := range trackers {
+		if tracker.Start.Before(end) {
+			page = append(page, tracker)
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const trackerPath = "/api/v2/tracker"
+
+var ErrNotRunning = errors.New("tracker is not running")
+
+// Tracker is a time tracker as served by the v2 API.
+type Tracker struct {
+	ID        uint64
+	Name      string
+	Start     time.Time
+	End       time.Time
+	Running   bool
+	Duration  time.Duration
+	Project   string
+	Tags      []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   uint32
+	// Overlaps lists the sessions the tracker was stored overlapping with
+	// when the server warns about overlaps.
+	Overlaps []uint64
+	// AutoStopReason is set when the server stopped a tracker left running,
+	// max_duration or end_of_day.
+	AutoStopReason string
+}
+
+type ListParams struct {
+	// Start and End filter trackers started in between, both are required
+	// when one is set.
+	Start time.Time
+	End   time.Time
+}
+
+type CreateParams struct {
+	Start   time.Time
+	Name    string
+	Project string
+	Tags    []string
+	// IdempotencyKey makes retries of the same create safe, the server
+	// replays the first response for the key.
+	IdempotencyKey string
+}
+
+// UpdateParams only change the fields that are set, Version must match the
+// current version of the tracker.
+type UpdateParams struct {
+	Start   time.Time
+	End     time.Time
+	Name    string
+	Project string
+	Tags    []string
+	Version uint32
+}
+
+type projectResponse struct {
+	Name string `json:"name"`
+}
+
+type trackerResponse struct {
+	ID              uint64            `json:"id"`
+	Name            string            `json:"name"`
+	Start           string            `json:"start"`
+	End             *string           `json:"end"`
+	Running         bool              `json:"running"`
+	DurationSeconds int64             `json:"duration_seconds"`
+	Project         *projectResponse  `json:"project"`
+	Tags            []string          `json:"tags"`
+	CreatedAt       string            `json:"created_at"`
+	UpdatedAt       string            `json:"updated_at"`
+	Version         uint32            `json:"version"`
+	Overlaps        []uint64          `json:"overlaps"`
+	AutoStop        *autoStopResponse `json:"auto_stop"`
+}
+
+type autoStopResponse struct {
+	Reason string `json:"reason"`
+}
+
+type trackersResponse struct {
+	Trackers []trackerResponse `json:"trackers"`
+}
+
+type createRequest struct {
+	Start   time.Time `json:"start"`
+	Name    string    `json:"name"`
+	Project string    `json:"project,omitempty"`
+	Tags    []string  `json:"tags,omitempty"`
+}
+
+type updateRequest struct {
+	Start   *time.Time `json:"start,omitempty"`
+	End     *time.Time `json:"end,omitempty"`
+	Name    string     `json:"name,omitempty"`
+	Project string     `json:"project,omitempty"`
+	Tags    []string   `json:"tags,omitempty"`
+	Version uint32     `json:"version"`
+}
+
+func (c *Client) GetTracker(ctx context.Context, id uint64) (Tracker, error) {
+	var response trackerResponse
+	if err := c.do(ctx, http.MethodGet, trackerURL(id), nil, nil, &response); err != nil {
+		return Tracker{}, err
+	}
+
+	return response.toTracker()
+}
+
+func (c *Client) ListTrackers(ctx context.Context, params ListParams) ([]Tracker, error) {
+	query := url.Values{}
+	if !params.Start.IsZero() || !params.End.IsZero() {
+		query.Set("start_date", params.Start.Format(time.RFC3339))
+		query.Set("end_date", params.End.Format(time.RFC3339))
+	}
+
+	var response trackersResponse
+	if err := c.do(ctx, http.MethodGet, trackerPath, query, nil, &response); err != nil {
+		return nil, err
+	}
+
+	trackers := make([]Tracker, 0, len(response.Trackers))
+	for _, item := range response.Trackers {
+		tracker, err := item.toTracker()
+		if err != nil {
+			return nil, err
+		}
+		trackers = append(trackers, tracker)
+	}
+
+	return trackers, nil
+}
+
+func (c *Client) CreateTracker(ctx context.Context, params CreateParams) (Tracker, error) {
+	body := createRequest{
+		Start:   params.Start,
+		Name:    params.Name,
+		Project: params.Project,
+		Tags:    params.Tags,
+	}
+
+	var response trackerResponse
+	err := c.call(ctx, request{
+		method:         http.MethodPost,
+		path:           trackerPath,
+		body:           body,
+		idempotencyKey: params.IdempotencyKey,
+	}, &response)
+	if err != nil {
+		return Tracker{}, err
+	}
+
+	return response.toTracker()
+}
+
+// UpdateTracker updates the tracker if params.Version is still the current
+// one, ErrWrongVersion is returned otherwise.
+func (c *Client) UpdateTracker(ctx context.Context, id uint64, params UpdateParams) (Tracker, error) {
+	body := updateRequest{
+		Name:    params.Name,
+		Project: params.Project,
+		Tags:    params.Tags,
+		Version: params.Version,
+	}
+	if !params.Start.IsZero() {
+		body.Start = &params.Start
+	}
+	if !params.End.IsZero() {
+		body.End = &params.End
+	}
+
+	var response trackerResponse
+	if err := c.do(ctx, http.MethodPut, trackerURL(id), nil, body, &response); err != nil {
+		return Tracker{}, err
+	}
+
+	return response.toTracker()
+}
+
+// DeleteTracker deletes the tracker, a zero version skips the version check.
+func (c *Client) DeleteTracker(ctx context.Context, id uint64, version uint32) error {
+	query := url.Values{}
+	if version != 0 {
+		query.Set("version", strconv.FormatUint(uint64(version), 10))
+	}
+
+	return c.do(ctx, http.MethodDelete, trackerURL(id), query, nil, nil)
+}
+
+// ModifyTracker loads the tracker, applies the changes returned by fn and
+// stores them with the version it loaded. On a version conflict the tracker
+// is reloaded and fn called again, up to the configured conflict retries.
+func (c *Client) ModifyTracker(ctx context.Context, id uint64, fn func(tracker Tracker) (UpdateParams, error)) (Tracker, error) {
+	for attempt := 0; ; attempt++ {
+		tracker, err := c.GetTracker(ctx, id)
+		if err != nil {
+			return Tracker{}, err
+		}
+
+		params, err := fn(tracker)
+		if err != nil {
+			return Tracker{}, err
+		}
+		params.Version = tracker.Version
+
+		updated, err := c.UpdateTracker(ctx, id, params)
+		if errors.Is(err, ErrWrongVersion) && attempt < c.conflictRetries {
+			continue
+		}
+
+		return updated, err
+	}
+}
+
+// StopTracker ends a running tracker at end, ErrNotRunning is returned when
+// it was already stopped.
+func (c *Client) StopTracker(ctx context.Context, id uint64, end time.Time) (Tracker, error) {
+	return c.ModifyTracker(ctx, id, func(tracker Tracker) (UpdateParams, error) {
+		if !tracker.Running {
+			return UpdateParams{}, ErrNotRunning
+		}
+
+		return UpdateParams{End: end}, nil
+	})
+}
+
+func trackerURL(id uint64) string {
+	return trackerPath + "/" + strconv.FormatUint(id, 10)
+}
+
+func (r trackerResponse) toTracker() (Tracker, error) {
+	tracker := Tracker{
+		ID:       r.ID,
+		Name:     r.Name,
+		Running:  r.Running,
+		Duration: time.Duration(r.DurationSeconds) * time.Second,
+		Tags:     r.Tags,
+		Version:  r.Version,
+		Overlaps: r.Overlaps,
+	}
+
+	if r.AutoStop != nil {
+		tracker.AutoStopReason = r.AutoStop.Reason
+	}
+
+	if r.Project != nil {
+		tracker.Project = r.Project.Name
+	}
+	if tracker.Tags == nil {
+		tracker.Tags = make([]string, 0)
+	}
+
+	var err error
+	if tracker.Start, err = parseTime(r.Start); err != nil {
+		return Tracker{}, err
+	}
+	if tracker.CreatedAt, err = parseTime(r.CreatedAt); err != nil {
+		return Tracker{}, err
+	}
+	if tracker.UpdatedAt, err = parseTime(r.UpdatedAt); err != nil {
+		return Tracker{}, err
+	}
+	if r.End != nil {
+		if tracker.End, err = parseTime(*r.End); err != nil {
+			return Tracker{}, err
+		}
+	}
+
+	return tracker, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w invalid timestamp", err)
+	}
+
+	return parsed, nil
+}
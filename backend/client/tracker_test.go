@@ -0,0 +1,337 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// fakeTrackerAPI serves the v2 tracker routes from memory.
+type fakeTrackerAPI struct {
+	mu       sync.Mutex
+	trackers map[uint64]*trackerResponse
+	// conflicts answers that many updates with 409 after bumping the version,
+	// as a concurrent writer would.
+	conflicts int
+	// locked answers updates with 409 as a locked period would.
+	locked  bool
+	updates int
+	lists   []string
+}
+
+func newFakeTrackerAPI(trackers ...trackerResponse) *fakeTrackerAPI {
+	api := &fakeTrackerAPI{trackers: make(map[uint64]*trackerResponse)}
+	for i := range trackers {
+		api.trackers[trackers[i].ID] = &trackers[i]
+	}
+
+	return api
+}
+
+func (f *fakeTrackerAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == trackerPath {
+		f.list(w, r)
+
+		return
+	}
+
+	var id uint64
+	fmt.Sscanf(strings.TrimPrefix(r.URL.Path, trackerPath+"/"), "%d", &id)
+
+	tracker, ok := f.trackers[id]
+	if !ok {
+		writeTestJSON(w, http.StatusNotFound, errorResponse{Error: "tracker not found"})
+
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeTestJSON(w, http.StatusOK, tracker)
+	case http.MethodPut:
+		f.updates++
+
+		var request updateRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		if f.locked {
+			writeTestJSON(w, http.StatusConflict, errorResponse{Error: "period is locked by an approved timesheet", Code: codePeriodLocked})
+
+			return
+		}
+		if f.conflicts > 0 {
+			f.conflicts--
+			tracker.Version++
+		}
+		if request.Version != tracker.Version {
+			writeTestJSON(w, http.StatusConflict, errorResponse{Error: "wrong version provided", Code: codeWrongVersion})
+
+			return
+		}
+
+		if request.End != nil {
+			end := request.End.UTC().Format(time.RFC3339)
+			tracker.End = &end
+			tracker.Running = false
+		}
+		tracker.Version++
+
+		writeTestJSON(w, http.StatusOK, tracker)
+	}
+}
+
+func (f *fakeTrackerAPI) list(w http.ResponseWriter, r *http.Request) {
+	f.lists = append(f.lists, r.URL.RawQuery)
+
+	start, _ := time.Parse(time.RFC3339, r.FormValue("start_date"))
+	end, _ := time.Parse(time.RFC3339, r.FormValue("end_date"))
+
+	response := trackersResponse{Trackers: make([]trackerResponse, 0)}
+	for id := uint64(1); id <= uint64(len(f.trackers)); id++ {
+		tracker := f.trackers[id]
+		started, _ := time.Parse(time.RFC3339, tracker.Start)
+
+		if !start.IsZero() && (started.Before(start) || started.After(end)) {
+			continue
+		}
+		response.Trackers = append(response.Trackers, *tracker)
+	}
+
+	writeTestJSON(w, http.StatusOK, response)
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func testTracker(id uint64, start string) trackerResponse {
+	return trackerResponse{
+		ID:        id,
+		Name:      fmt.Sprintf("tracker %d", id),
+		Start:     start,
+		Running:   true,
+		Tags:      []string{},
+		CreatedAt: start,
+		UpdatedAt: start,
+		Version:   1,
+	}
+}
+
+func Test_Client_StopTracker(t *testing.T) {
+
+	type testExpectation struct {
+		err     error
+		updates int
+	}
+
+	testCases := []struct {
+		description string
+		conflicts   int
+		locked      bool
+		stopped     bool
+		expected    testExpectation
+	}{
+		{
+			description: "when there is no conflict",
+			expected:    testExpectation{updates: 1},
+		},
+		{
+			description: "when a concurrent update wins once",
+			conflicts:   1,
+			expected:    testExpectation{updates: 2},
+		},
+		{
+			description: "when conflicts outlast the retries",
+			conflicts:   10,
+			expected:    testExpectation{err: ErrWrongVersion, updates: 4},
+		},
+		{
+			description: "when the period is locked",
+			locked:      true,
+			expected:    testExpectation{err: ErrPeriodLocked, updates: 1},
+		},
+		{
+			description: "when the tracker is already stopped",
+			stopped:     true,
+			expected:    testExpectation{err: ErrNotRunning},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			tracker := testTracker(1, "2021-05-03T09:00:00Z")
+			if tc.stopped {
+				end := "2021-05-03T10:00:00Z"
+				tracker.End = &end
+				tracker.Running = false
+			}
+
+			api := newFakeTrackerAPI(tracker)
+			api.conflicts = tc.conflicts
+			api.locked = tc.locked
+			c := newTestClient(t, api)
+
+			end := time.Date(2021, 5, 3, 11, 0, 0, 0, time.UTC)
+			result, err := c.StopTracker(context.Background(), 1, end)
+
+			g.Expect(api.updates).To(Equal(tc.expected.updates))
+
+			if tc.expected.err != nil {
+				g.Expect(errors.Is(err, tc.expected.err)).To(BeTrue())
+
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result.Running).To(BeFalse())
+			g.Expect(result.End).To(Equal(end))
+		})
+	}
+}
+
+func Test_Client_CreateTracker(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.Method).To(Equal(http.MethodPost))
+		g.Expect(r.Header.Get("Idempotency-Key")).To(Equal("create-1"))
+
+		var request createRequest
+		g.Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+
+		response := testTracker(7, request.Start.Format(time.RFC3339))
+		response.Name = request.Name
+		response.Project = &projectResponse{Name: request.Project}
+		response.Tags = request.Tags
+		writeTestJSON(w, http.StatusCreated, response)
+	}))
+
+	tracker, err := c.CreateTracker(context.Background(), CreateParams{
+		Start:          time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC),
+		Name:           "code review",
+		Project:        "pento",
+		Tags:           []string{"review"},
+		IdempotencyKey: "create-1",
+	})
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tracker.ID).To(Equal(uint64(7)))
+	g.Expect(tracker.Name).To(Equal("code review"))
+	g.Expect(tracker.Project).To(Equal("pento"))
+	g.Expect(tracker.Tags).To(Equal([]string{"review"}))
+	g.Expect(tracker.Running).To(BeTrue())
+}
+
+func Test_TrackerIterator(t *testing.T) {
+
+	type testExpectation struct {
+		ids   []uint64
+		pages int
+	}
+
+	testCases := []struct {
+		description string
+		params      IterateParams
+		expected    testExpectation
+	}{
+		{
+			description: "when no range is given",
+			params:      IterateParams{},
+			expected:    testExpectation{ids: []uint64{1, 2, 3, 4}, pages: 1},
+		},
+		{
+			description: "when the range spans several windows",
+			params: IterateParams{
+				Start:  time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2021, 5, 4, 0, 0, 0, 0, time.UTC),
+				Window: 24 * time.Hour,
+			},
+			expected: testExpectation{ids: []uint64{1, 2, 3, 4}, pages: 3},
+		},
+		{
+			description: "when the range covers part of the trackers",
+			params: IterateParams{
+				Start:  time.Date(2021, 5, 2, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2021, 5, 3, 0, 0, 0, 0, time.UTC),
+				Window: 12 * time.Hour,
+			},
+			// the tracker started on the range end is included, as the server
+			// filter includes both bounds
+			expected: testExpectation{ids: []uint64{2, 3, 4}, pages: 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			api := newFakeTrackerAPI(
+				testTracker(1, "2021-05-01T09:00:00Z"),
+				// on the boundary of the first and second daily window
+				testTracker(2, "2021-05-02T00:00:00Z"),
+				testTracker(3, "2021-05-02T15:00:00Z"),
+				testTracker(4, "2021-05-03T00:00:00Z"),
+			)
+			c := newTestClient(t, api)
+
+			it := c.IterateTrackers(context.Background(), tc.params)
+
+			ids := make([]uint64, 0)
+			for it.Next() {
+				ids = append(ids, it.Tracker().ID)
+			}
+
+			g.Expect(it.Err()).ToNot(HaveOccurred())
+			g.Expect(ids).To(Equal(tc.expected.ids))
+			g.Expect(api.lists).To(HaveLen(tc.expected.pages))
+		})
+	}
+}
+
+func Test_Client_Batch(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Path).To(Equal(batchPath))
+
+		var request batchRequest
+		g.Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+		g.Expect(request.Mode).To(Equal("atomic"))
+		g.Expect(request.Operations).To(HaveLen(2))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"mode": "atomic", "results": [
+			{"index": 0, "op": "update", "status": "rolled_back", "code": 0},
+			{"index": 1, "op": "delete", "status": "failed", "code": 409, "error_code": "wrong_version", "error": "wrong version provided"}
+		]}`))
+	}))
+
+	result, err := c.Batch(context.Background(), BatchParams{
+		Operations: []BatchOperation{
+			{Op: BatchUpdate, ID: 1, Name: "review", Version: 2},
+			{Op: BatchDelete, ID: 3, Version: 1},
+		},
+	})
+
+	g.Expect(errors.Is(err, ErrWrongVersion)).To(BeTrue())
+	g.Expect(result.Items).To(HaveLen(2))
+	g.Expect(result.Items[0].Status).To(Equal(BatchStatusRolledBack))
+	g.Expect(result.Items[1].Status).To(Equal(BatchStatusFailed))
+	g.Expect(result.Items[1].StatusCode).To(Equal(http.StatusConflict))
+}
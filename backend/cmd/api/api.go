@@ -1,7 +1,11 @@
 package api
 
 import (
+	"fmt"
+	"os"
 	api "pento/code-challenge/application"
+	"pento/code-challenge/config"
+	"pento/code-challenge/logging"
 
 	"github.com/spf13/cobra"
 )
@@ -14,13 +18,39 @@ func Command() *cobra.Command {
 		RunE:  Run(),
 	}
 
+	config.RegisterFlags(cmd.Flags())
+
 	return cmd
 }
 
 func Run() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		api.SetupAPI()
+		cfg, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		// from here on errors are runtime failures, not usage mistakes
+		cmd.SilenceUsage = true
+
+		printConfig, err := cmd.Flags().GetBool("print-config")
+		if err != nil {
+			return err
+		}
+
+		if printConfig {
+			fmt.Fprint(cmd.OutOrStdout(), cfg)
+
+			return nil
+		}
+
+		logger, err := logging.New(os.Stderr, cfg.Logging.Format, cfg.Logging.Level)
+		if err != nil {
+			return err
+		}
+
+		logger.WithField("config", cfg.String()).Info("effective configuration")
 
-		return nil
+		return api.SetupAPI(cfg, logger)
 	}
 }
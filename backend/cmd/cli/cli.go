@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pento/code-challenge/client"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultAPIURL = "http://localhost:8080"
+
+	envAPIURL   = "TRACKER_API_URL"
+	envAPIToken = "TRACKER_API_TOKEN"
+)
+
+// Settings is what the client config file holds.
+type Settings struct {
+	APIURL string `yaml:"api_url"`
+	Token  string `yaml:"token"`
+}
+
+// Commands creates the client commands, which talk to a running tracker API.
+func Commands() []*cobra.Command {
+	commands := []*cobra.Command{
+		configureCommand(),
+		startCommand(),
+		stopCommand(),
+		statusCommand(),
+		listCommand(),
+	}
+
+	for _, cmd := range commands {
+		registerFlags(cmd.Flags())
+	}
+
+	return commands
+}
+
+func registerFlags(flags *pflag.FlagSet) {
+	flags.String("client-config", "", "path to the client config file (default $XDG_CONFIG_HOME/tracker/client.yaml)")
+	flags.String("api-url", "", "tracker API url, overrides the config file and "+envAPIURL)
+	flags.String("token", "", "API token, overrides the config file and "+envAPIToken)
+	flags.StringP("output", "o", outputTable, "output format: table or json")
+}
+
+func configureCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "configure",
+		Short: "Save the API url and token used by the client commands",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := settingsPath(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			settings, err := loadSettings(path)
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("api-url") {
+				settings.APIURL, _ = cmd.Flags().GetString("api-url")
+			}
+			if cmd.Flags().Changed("token") {
+				settings.Token, _ = cmd.Flags().GetString("token")
+			}
+
+			if _, err := client.New(settings.APIURL); err != nil {
+				return err
+			}
+
+			if err := saveSettings(path, settings); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "saved %s\n", path)
+
+			return nil
+		},
+	}
+}
+
+// newClient builds a client from, in increasing order of precedence, the
+// config file, the environment and the flags.
+func newClient(flags *pflag.FlagSet) (*client.Client, error) {
+	// reject a bad output format before anything is changed on the server
+	if format, _ := flags.GetString("output"); format != outputTable && format != outputJSON {
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+
+	path, err := settingsPath(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := loadSettings(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if value := os.Getenv(envAPIURL); value != "" {
+		settings.APIURL = value
+	}
+	if value := os.Getenv(envAPIToken); value != "" {
+		settings.Token = value
+	}
+	if flags.Changed("api-url") {
+		settings.APIURL, _ = flags.GetString("api-url")
+	}
+	if flags.Changed("token") {
+		settings.Token, _ = flags.GetString("token")
+	}
+
+	return client.New(settings.APIURL, client.WithToken(settings.Token))
+}
+
+func settingsPath(flags *pflag.FlagSet) (string, error) {
+	if path, _ := flags.GetString("client-config"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("%w failed to locate the config directory", err)
+	}
+
+	return filepath.Join(dir, "tracker", "client.yaml"), nil
+}
+
+// loadSettings reads the config file, a missing file yields the defaults.
+func loadSettings(path string) (Settings, error) {
+	settings := Settings{APIURL: defaultAPIURL}
+
+	content, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return Settings{}, fmt.Errorf("%w failed to read %s", err, path)
+	}
+
+	if err := yaml.Unmarshal(content, &settings); err != nil {
+		return Settings{}, fmt.Errorf("%w failed to parse %s", err, path)
+	}
+
+	return settings, nil
+}
+
+// saveSettings writes the config file readable by its owner only since it
+// holds the token.
+func saveSettings(path string, settings Settings) error {
+	content, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("%w failed to create %s", err, filepath.Dir(path))
+	}
+
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("%w failed to write %s", err, path)
+	}
+
+	return nil
+}
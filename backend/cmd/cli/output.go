@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"pento/code-challenge/client"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+type trackerOutput struct {
+	ID              uint64     `json:"id"`
+	Name            string     `json:"name"`
+	Project         string     `json:"project,omitempty"`
+	Tags            []string   `json:"tags"`
+	Start           time.Time  `json:"start"`
+	End             *time.Time `json:"end"`
+	Running         bool       `json:"running"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	Version         uint32     `json:"version"`
+}
+
+func outputFormat(cmd *cobra.Command) string {
+	format, _ := cmd.Flags().GetString("output")
+
+	return format
+}
+
+func render(cmd *cobra.Command, trackers []client.Tracker) error {
+	switch outputFormat(cmd) {
+	case outputJSON:
+		return renderJSON(cmd, trackers)
+	case outputTable:
+		return renderTable(cmd, trackers)
+	default:
+		return fmt.Errorf("unknown output format %q", outputFormat(cmd))
+	}
+}
+
+func renderJSON(cmd *cobra.Command, trackers []client.Tracker) error {
+	output := make([]trackerOutput, 0, len(trackers))
+	for _, tracker := range trackers {
+		item := trackerOutput{
+			ID:              tracker.ID,
+			Name:            tracker.Name,
+			Project:         tracker.Project,
+			Tags:            tracker.Tags,
+			Start:           tracker.Start.Local(),
+			Running:         tracker.Running,
+			DurationSeconds: int64(tracker.Duration / time.Second),
+			Version:         tracker.Version,
+		}
+		if !tracker.End.IsZero() {
+			end := tracker.End.Local()
+			item.End = &end
+		}
+		output = append(output, item)
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(output)
+}
+
+func renderTable(cmd *cobra.Command, trackers []client.Tracker) error {
+	writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "ID\tNAME\tPROJECT\tTAGS\tSTART\tEND\tDURATION")
+
+	var total time.Duration
+	for _, tracker := range trackers {
+		end := "running"
+		if !tracker.End.IsZero() {
+			end = tracker.End.Local().Format("2006-01-02 15:04")
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			tracker.ID,
+			tracker.Name,
+			dash(tracker.Project),
+			dash(strings.Join(tracker.Tags, ",")),
+			tracker.Start.Local().Format("2006-01-02 15:04"),
+			end,
+			tracker.Duration,
+		)
+		total += tracker.Duration
+	}
+
+	if len(trackers) > 1 {
+		fmt.Fprintf(writer, "\t\t\t\t\tTOTAL\t%s\n", total)
+	}
+
+	return writer.Flush()
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"pento/code-challenge/client"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var errNothingRunning = errors.New("no tracker is running")
+
+func startCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start NAME",
+		Short: "Start a tracker now",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			project, _ := cmd.Flags().GetString("project")
+			tags, _ := cmd.Flags().GetStringSlice("tag")
+
+			tracker, err := c.CreateTracker(cmd.Context(), client.CreateParams{
+				Start:   time.Now(),
+				Name:    strings.Join(args, " "),
+				Project: project,
+				Tags:    tags,
+			})
+			if err != nil {
+				return err
+			}
+
+			return render(cmd, []client.Tracker{tracker})
+		},
+	}
+
+	cmd.Flags().String("project", "", "project of the tracker")
+	cmd.Flags().StringSlice("tag", nil, "tag of the tracker, can be repeated")
+
+	return cmd
+}
+
+func stopCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running trackers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			running, err := runningTrackers(cmd, c)
+			if err != nil {
+				return err
+			}
+			if len(running) == 0 {
+				return errNothingRunning
+			}
+
+			now := time.Now()
+			stopped := make([]client.Tracker, 0, len(running))
+			for _, tracker := range running {
+				result, err := c.StopTracker(cmd.Context(), tracker.ID, now)
+				if err != nil {
+					return fmt.Errorf("%w failed to stop tracker %d", err, tracker.ID)
+				}
+				stopped = append(stopped, result)
+			}
+
+			return render(cmd, stopped)
+		},
+	}
+}
+
+func statusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the running trackers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			running, err := runningTrackers(cmd, c)
+			if err != nil {
+				return err
+			}
+
+			if len(running) == 0 && outputFormat(cmd) == outputTable {
+				fmt.Fprintln(cmd.OutOrStdout(), errNothingRunning)
+
+				return nil
+			}
+
+			return render(cmd, running)
+		},
+	}
+}
+
+func listCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List trackers",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			week, _ := cmd.Flags().GetBool("week")
+			today, _ := cmd.Flags().GetBool("today")
+			if week && today {
+				return errors.New("--week and --today are mutually exclusive")
+			}
+
+			var params client.ListParams
+			switch {
+			case week:
+				params.Start, params.End = weekRange(time.Now())
+			case today:
+				params.Start, params.End = dayRange(time.Now())
+			}
+
+			trackers, err := c.ListTrackers(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+
+			return render(cmd, trackers)
+		},
+	}
+
+	cmd.Flags().Bool("week", false, "only trackers started this week")
+	cmd.Flags().Bool("today", false, "only trackers started today")
+
+	return cmd
+}
+
+func runningTrackers(cmd *cobra.Command, c *client.Client) ([]client.Tracker, error) {
+	trackers, err := c.ListTrackers(cmd.Context(), client.ListParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	running := make([]client.Tracker, 0)
+	for _, tracker := range trackers {
+		if tracker.Running {
+			running = append(running, tracker)
+		}
+	}
+
+	return running, nil
+}
+
+func dayRange(now time.Time) (time.Time, time.Time) {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	return start, start.AddDate(0, 0, 1)
+}
+
+// weekRange returns the local week, starting on Monday, that contains now.
+func weekRange(now time.Time) (time.Time, time.Time) {
+	start, _ := dayRange(now)
+	offset := (int(start.Weekday()) + 6) % 7
+	start = start.AddDate(0, 0, -offset)
+
+	return start, start.AddDate(0, 0, 7)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"pento/code-challenge/cmd/api"
+	"pento/code-challenge/cmd/cli"
 
 	"github.com/spf13/cobra"
 )
@@ -10,6 +11,7 @@ import (
 func main() {
 	rootCmd := &cobra.Command{Use: "users [SERVICE]"}
 	rootCmd.AddCommand(api.Command())
+	rootCmd.AddCommand(cli.Commands()...)
 
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatalf("failed to execute %s", err)
@@ -0,0 +1,673 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	envPrefix = "TRACKER"
+	redacted  = "[REDACTED]"
+)
+
+var sslModes = map[string]bool{
+	"disable":     true,
+	"allow":       true,
+	"prefer":      true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+type Config struct {
+	Database   DatabaseConfig             `mapstructure:"database" yaml:"database"`
+	HTTP       HTTPConfig                 `mapstructure:"http" yaml:"http"`
+	RateLimits map[string]RateLimitConfig `mapstructure:"rate_limits" yaml:"rate_limits"`
+	Debug      DebugConfig                `mapstructure:"debug" yaml:"debug"`
+	Tracing    TracingConfig              `mapstructure:"tracing" yaml:"tracing"`
+	Logging    LoggingConfig              `mapstructure:"logging" yaml:"logging"`
+	TLS        TLSConfig                  `mapstructure:"tls" yaml:"tls"`
+	Frontend   FrontendConfig             `mapstructure:"frontend" yaml:"frontend"`
+	Validation ValidationConfig           `mapstructure:"validation" yaml:"validation"`
+	Running    RunningConfig              `mapstructure:"running" yaml:"running"`
+	Rounding   RoundingConfig             `mapstructure:"rounding" yaml:"rounding"`
+	Budgets    BudgetsConfig              `mapstructure:"budgets" yaml:"budgets"`
+	Pomodoro   PomodoroConfig             `mapstructure:"pomodoro" yaml:"pomodoro"`
+	Timesheets TimesheetsConfig           `mapstructure:"timesheets" yaml:"timesheets"`
+}
+
+type DatabaseConfig struct {
+	Host            string        `mapstructure:"host" yaml:"host"`
+	Port            int           `mapstructure:"port" yaml:"port"`
+	User            string        `mapstructure:"user" yaml:"user"`
+	Password        string        `mapstructure:"password" yaml:"password"`
+	Name            string        `mapstructure:"name" yaml:"name"`
+	SSLMode         string        `mapstructure:"sslmode" yaml:"sslmode"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns" yaml:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns" yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" yaml:"conn_max_lifetime"`
+	// ConnectTimeout bounds the retries of the initial connection, which back
+	// off from ConnectRetryInterval up to ConnectRetryMaxInterval.
+	ConnectTimeout          time.Duration `mapstructure:"connect_timeout" yaml:"connect_timeout"`
+	ConnectRetryInterval    time.Duration `mapstructure:"connect_retry_interval" yaml:"connect_retry_interval"`
+	ConnectRetryMaxInterval time.Duration `mapstructure:"connect_retry_max_interval" yaml:"connect_retry_max_interval"`
+}
+
+type HTTPConfig struct {
+	ListenAddress     string        `mapstructure:"listen_address" yaml:"listen_address"`
+	CORSOrigins       []string      `mapstructure:"cors_origins" yaml:"cors_origins"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout" yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout" yaml:"read_timeout"`
+	// WriteTimeout is disabled by default, it would cut the event stream.
+	WriteTimeout    time.Duration `mapstructure:"write_timeout" yaml:"write_timeout"`
+	IdleTimeout     time.Duration `mapstructure:"idle_timeout" yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" yaml:"shutdown_timeout"`
+	// RouteTimeouts bound the request context per route group.
+	RouteTimeouts map[string]time.Duration `mapstructure:"route_timeouts" yaml:"route_timeouts"`
+	// ClientIPHeader names the header a reverse proxy sets to the client
+	// address, like X-Forwarded-For. It is only read on requests coming from
+	// TrustedProxies, clients are keyed on the connection address otherwise.
+	ClientIPHeader string `mapstructure:"client_ip_header" yaml:"client_ip_header"`
+	// TrustedProxies lists the CIDR ranges of the reverse proxies.
+	TrustedProxies []string `mapstructure:"trusted_proxies" yaml:"trusted_proxies"`
+}
+
+type DebugConfig struct {
+	// Token guards /debug/status, the endpoint is disabled when empty.
+	Token string `mapstructure:"token" yaml:"token"`
+}
+
+type TracingConfig struct {
+	// Exporter is one of none, stdout or otlp.
+	Exporter     string  `mapstructure:"exporter" yaml:"exporter"`
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint" yaml:"otlp_endpoint"`
+	OTLPInsecure bool    `mapstructure:"otlp_insecure" yaml:"otlp_insecure"`
+	ServiceName  string  `mapstructure:"service_name" yaml:"service_name"`
+	SampleRatio  float64 `mapstructure:"sample_ratio" yaml:"sample_ratio"`
+}
+
+// TLSConfig enables HTTPS, and HTTP/2, when both CertFile and KeyFile are set.
+type TLSConfig struct {
+	CertFile string `mapstructure:"cert_file" yaml:"cert_file"`
+	KeyFile  string `mapstructure:"key_file" yaml:"key_file"`
+	// MinVersion is 1.2 or 1.3.
+	MinVersion string `mapstructure:"min_version" yaml:"min_version"`
+	// ClientCAFile enables client certificate authentication against the CA
+	// bundle, ClientAuth is either require or optional.
+	ClientCAFile string `mapstructure:"client_ca_file" yaml:"client_ca_file"`
+	ClientAuth   string `mapstructure:"client_auth" yaml:"client_auth"`
+	// ReloadInterval is how often the files are checked for rotation.
+	ReloadInterval time.Duration `mapstructure:"reload_interval" yaml:"reload_interval"`
+	// RedirectAddress starts a plain HTTP listener redirecting to HTTPS,
+	// disabled when empty.
+	RedirectAddress string `mapstructure:"redirect_address" yaml:"redirect_address"`
+}
+
+// Enabled reports whether the API is served over TLS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+type ValidationConfig struct {
+	// FutureTolerance is how far ahead of the server clock tracker times may
+	// be.
+	FutureTolerance  time.Duration `mapstructure:"future_tolerance" yaml:"future_tolerance"`
+	MaxSessionLength time.Duration `mapstructure:"max_session_length" yaml:"max_session_length"`
+	MaxNameLength    int           `mapstructure:"max_name_length" yaml:"max_name_length"`
+	// OverlapPolicy is reject, warn or trim.
+	OverlapPolicy string `mapstructure:"overlap_policy" yaml:"overlap_policy"`
+}
+
+type RunningConfig struct {
+	// Single allows a single running tracker, enforced by the database.
+	Single bool `mapstructure:"single" yaml:"single"`
+	// AutoStop stops the running tracker when a new one starts.
+	AutoStop bool `mapstructure:"auto_stop" yaml:"auto_stop"`
+	// MaxDuration and EndOfDay, as HH:MM in Location, stop trackers left
+	// running, zero and empty disable them.
+	MaxDuration   time.Duration `mapstructure:"max_duration" yaml:"max_duration"`
+	EndOfDay      string        `mapstructure:"end_of_day" yaml:"end_of_day"`
+	Location      string        `mapstructure:"location" yaml:"location"`
+	CheckInterval time.Duration `mapstructure:"check_interval" yaml:"check_interval"`
+}
+
+// RoundingConfig is the rounding applied to reports unless a request
+// overrides it.
+type RoundingConfig struct {
+	// Mode is none, nearest, up or down.
+	Mode      string        `mapstructure:"mode" yaml:"mode"`
+	Increment time.Duration `mapstructure:"increment" yaml:"increment"`
+	// Aggregation rounds every entry, or the time per day and project.
+	Aggregation string `mapstructure:"aggregation" yaml:"aggregation"`
+}
+
+type BudgetsConfig struct {
+	// Thresholds are the default alert percentages of new budgets.
+	Thresholds []int `mapstructure:"thresholds" yaml:"thresholds"`
+	// Location is where monthly budgets start over.
+	Location string `mapstructure:"location" yaml:"location"`
+	// WebhookURL receives budget alerts as JSON posts, they are only logged
+	// when empty.
+	WebhookURL     string        `mapstructure:"webhook_url" yaml:"webhook_url"`
+	WebhookTimeout time.Duration `mapstructure:"webhook_timeout" yaml:"webhook_timeout"`
+}
+
+// PomodoroConfig is the schedule of pomodoros started without one, Rounds
+// zero runs until stopped. Phases are advanced every CheckInterval.
+type PomodoroConfig struct {
+	Focus          time.Duration `mapstructure:"focus" yaml:"focus"`
+	ShortBreak     time.Duration `mapstructure:"short_break" yaml:"short_break"`
+	LongBreak      time.Duration `mapstructure:"long_break" yaml:"long_break"`
+	LongBreakEvery int           `mapstructure:"long_break_every" yaml:"long_break_every"`
+	Rounds         int           `mapstructure:"rounds" yaml:"rounds"`
+	CheckInterval  time.Duration `mapstructure:"check_interval" yaml:"check_interval"`
+}
+
+type TimesheetsConfig struct {
+	// Location is where timesheet weeks start, on Monday at midnight.
+	Location string `mapstructure:"location" yaml:"location"`
+	// Lock rejects changes to trackers inside approved timesheets.
+	Lock bool `mapstructure:"lock" yaml:"lock"`
+}
+
+// EndOfDayOffset returns EndOfDay as an offset from midnight, midnight
+// itself is 24h and zero means no end of day.
+func (r RunningConfig) EndOfDayOffset() (time.Duration, error) {
+	if r.EndOfDay == "" {
+		return 0, nil
+	}
+
+	clock, err := time.Parse("15:04", r.EndOfDay)
+	if err != nil {
+		return 0, err
+	}
+
+	offset := time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+	if offset == 0 {
+		offset = 24 * time.Hour
+	}
+
+	return offset, nil
+}
+
+type FrontendConfig struct {
+	// Enabled serves the embedded frontend bundle next to the API.
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// APIBaseURL is handed to the frontend at serve time.
+	APIBaseURL string `mapstructure:"api_base_url" yaml:"api_base_url"`
+}
+
+type LoggingConfig struct {
+	// Format is one of json or logfmt.
+	Format string `mapstructure:"format" yaml:"format"`
+	Level  string `mapstructure:"level" yaml:"level"`
+}
+
+type RateLimitConfig struct {
+	Requests int           `mapstructure:"requests" yaml:"requests"`
+	Period   time.Duration `mapstructure:"period" yaml:"period"`
+	Burst    int           `mapstructure:"burst" yaml:"burst"`
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("database.host", "localhost")
+	v.SetDefault("database.port", 5434)
+	v.SetDefault("database.user", "postgres")
+	v.SetDefault("database.password", "postgres")
+	v.SetDefault("database.name", "postgres")
+	v.SetDefault("database.sslmode", "disable")
+	v.SetDefault("database.max_open_conns", 10)
+	v.SetDefault("database.max_idle_conns", 5)
+	v.SetDefault("database.conn_max_lifetime", 30*time.Minute)
+	v.SetDefault("database.connect_timeout", time.Minute)
+	v.SetDefault("database.connect_retry_interval", 500*time.Millisecond)
+	v.SetDefault("database.connect_retry_max_interval", 10*time.Second)
+
+	v.SetDefault("http.listen_address", ":8080")
+	v.SetDefault("http.cors_origins", []string{"*"})
+	v.SetDefault("http.read_header_timeout", 5*time.Second)
+	v.SetDefault("http.read_timeout", 15*time.Second)
+	v.SetDefault("http.write_timeout", time.Duration(0))
+	v.SetDefault("http.idle_timeout", 60*time.Second)
+	v.SetDefault("http.shutdown_timeout", 15*time.Second)
+	v.SetDefault("http.route_timeouts", map[string]interface{}{
+		"read":  10 * time.Second,
+		"write": 15 * time.Second,
+	})
+	v.SetDefault("http.client_ip_header", "")
+	v.SetDefault("http.trusted_proxies", []string{})
+
+	v.SetDefault("debug.token", "")
+
+	v.SetDefault("tracing.exporter", "none")
+	v.SetDefault("tracing.otlp_endpoint", "localhost:4318")
+	v.SetDefault("tracing.otlp_insecure", true)
+	v.SetDefault("tracing.service_name", "tracker-api")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+
+	v.SetDefault("logging.format", "json")
+	v.SetDefault("logging.level", "info")
+
+	v.SetDefault("tls.cert_file", "")
+	v.SetDefault("tls.key_file", "")
+	v.SetDefault("tls.min_version", "1.2")
+	v.SetDefault("tls.client_ca_file", "")
+	v.SetDefault("tls.client_auth", "require")
+	v.SetDefault("tls.reload_interval", time.Minute)
+	v.SetDefault("tls.redirect_address", "")
+
+	v.SetDefault("validation.future_tolerance", 5*time.Minute)
+	v.SetDefault("validation.max_session_length", 24*time.Hour)
+	v.SetDefault("validation.max_name_length", 255)
+	v.SetDefault("validation.overlap_policy", "reject")
+
+	v.SetDefault("running.single", false)
+	v.SetDefault("running.auto_stop", false)
+	v.SetDefault("running.max_duration", time.Duration(0))
+	v.SetDefault("running.end_of_day", "")
+	v.SetDefault("running.location", "UTC")
+	v.SetDefault("running.check_interval", time.Minute)
+
+	v.SetDefault("rounding.mode", "none")
+	v.SetDefault("rounding.increment", 15*time.Minute)
+	v.SetDefault("rounding.aggregation", "entry")
+
+	v.SetDefault("budgets.thresholds", []int{80, 100})
+	v.SetDefault("budgets.location", "UTC")
+	v.SetDefault("budgets.webhook_url", "")
+	v.SetDefault("budgets.webhook_timeout", 5*time.Second)
+
+	v.SetDefault("pomodoro.focus", 25*time.Minute)
+	v.SetDefault("pomodoro.short_break", 5*time.Minute)
+	v.SetDefault("pomodoro.long_break", 15*time.Minute)
+	v.SetDefault("pomodoro.long_break_every", 4)
+	v.SetDefault("pomodoro.rounds", 4)
+	v.SetDefault("pomodoro.check_interval", 5*time.Second)
+
+	v.SetDefault("timesheets.location", "UTC")
+	v.SetDefault("timesheets.lock", true)
+
+	v.SetDefault("frontend.enabled", true)
+	v.SetDefault("frontend.api_base_url", "/api/v1")
+
+	v.SetDefault("rate_limits", map[string]interface{}{
+		"read":   map[string]interface{}{"requests": 300, "period": time.Minute, "burst": 60},
+		"write":  map[string]interface{}{"requests": 60, "period": time.Minute, "burst": 20},
+		"events": map[string]interface{}{"requests": 10, "period": time.Minute, "burst": 5},
+	})
+}
+
+// flagKeys maps command line flags to configuration keys.
+var flagKeys = map[string]string{
+	"database-host":              "database.host",
+	"database-port":              "database.port",
+	"database-user":              "database.user",
+	"database-password":          "database.password",
+	"database-name":              "database.name",
+	"database-sslmode":           "database.sslmode",
+	"database-max-open-conns":    "database.max_open_conns",
+	"database-max-idle-conns":    "database.max_idle_conns",
+	"database-conn-max-lifetime": "database.conn_max_lifetime",
+	"database-connect-timeout":   "database.connect_timeout",
+	"listen-address":             "http.listen_address",
+	"cors-origins":               "http.cors_origins",
+	"read-header-timeout":        "http.read_header_timeout",
+	"read-timeout":               "http.read_timeout",
+	"write-timeout":              "http.write_timeout",
+	"idle-timeout":               "http.idle_timeout",
+	"shutdown-timeout":           "http.shutdown_timeout",
+	"client-ip-header":           "http.client_ip_header",
+	"trusted-proxies":            "http.trusted_proxies",
+	"tracing-exporter":           "tracing.exporter",
+	"tracing-otlp-endpoint":      "tracing.otlp_endpoint",
+	"tls-cert-file":              "tls.cert_file",
+	"tls-key-file":               "tls.key_file",
+	"tls-min-version":            "tls.min_version",
+	"tls-client-ca-file":         "tls.client_ca_file",
+	"redirect-address":           "tls.redirect_address",
+	"frontend":                   "frontend.enabled",
+	"frontend-api-base-url":      "frontend.api_base_url",
+	"log-format":                 "logging.format",
+	"log-level":                  "logging.level",
+	"overlap-policy":             "validation.overlap_policy",
+	"single-running":             "running.single",
+	"auto-stop":                  "running.auto_stop",
+	"max-running":                "running.max_duration",
+	"end-of-day":                 "running.end_of_day",
+	"rounding":                   "rounding.mode",
+	"rounding-increment":         "rounding.increment",
+	"budget-webhook-url":         "budgets.webhook_url",
+}
+
+// RegisterFlags adds the configuration flags to the command flag set.
+func RegisterFlags(flags *pflag.FlagSet) {
+	flags.String("config", "", "path to a YAML or TOML configuration file")
+	flags.Bool("print-config", false, "print the effective configuration and exit")
+
+	flags.String("database-host", "", "PostgreSQL host")
+	flags.Int("database-port", 0, "PostgreSQL port")
+	flags.String("database-user", "", "PostgreSQL user")
+	flags.String("database-password", "", "PostgreSQL password")
+	flags.String("database-name", "", "PostgreSQL database name")
+	flags.String("database-sslmode", "", "PostgreSQL sslmode")
+	flags.Int("database-max-open-conns", 0, "maximum number of open database connections")
+	flags.Int("database-max-idle-conns", 0, "maximum number of idle database connections")
+	flags.Duration("database-conn-max-lifetime", 0, "maximum lifetime of a database connection")
+	flags.Duration("database-connect-timeout", 0, "how long to retry the initial database connection")
+	flags.String("listen-address", "", "HTTP listen address")
+	flags.StringSlice("cors-origins", nil, "allowed CORS origins")
+	flags.Duration("read-header-timeout", 0, "HTTP read header timeout")
+	flags.Duration("read-timeout", 0, "HTTP read timeout")
+	flags.Duration("write-timeout", 0, "HTTP write timeout")
+	flags.Duration("idle-timeout", 0, "HTTP idle timeout")
+	flags.Duration("shutdown-timeout", 0, "time given to in-flight requests on shutdown")
+	flags.String("client-ip-header", "", "header carrying the client address set by trusted proxies")
+	flags.StringSlice("trusted-proxies", nil, "CIDR ranges of the reverse proxies setting --client-ip-header")
+	flags.String("tracing-exporter", "", "trace exporter: none, stdout or otlp")
+	flags.String("tracing-otlp-endpoint", "", "OTLP/HTTP collector endpoint")
+	flags.String("tls-cert-file", "", "TLS certificate file, enables HTTPS with --tls-key-file")
+	flags.String("tls-key-file", "", "TLS private key file")
+	flags.String("tls-min-version", "", "minimum TLS version: 1.2 or 1.3")
+	flags.String("tls-client-ca-file", "", "CA bundle used to authenticate client certificates")
+	flags.String("redirect-address", "", "address of a plain HTTP listener redirecting to HTTPS")
+	flags.Bool("frontend", true, "serve the embedded frontend, --frontend=false to disable")
+	flags.String("frontend-api-base-url", "", "API base URL handed to the frontend")
+	flags.String("log-format", "", "log format: json or logfmt")
+	flags.String("log-level", "", "log level: trace, debug, info, warn or error")
+	flags.String("overlap-policy", "", "what to do with overlapping sessions: reject, warn or trim")
+	flags.Bool("single-running", false, "allow a single running tracker")
+	flags.Bool("auto-stop", false, "stop the running tracker when a new one starts")
+	flags.Duration("max-running", 0, "stop trackers running longer than this")
+	flags.String("end-of-day", "", "stop running trackers at this time of day, as HH:MM")
+	flags.String("rounding", "", "report rounding: none, nearest, up or down")
+	flags.Duration("rounding-increment", 0, "report rounding increment, like 6m or 15m")
+	flags.String("budget-webhook-url", "", "URL receiving budget alerts")
+}
+
+// Load merges, in increasing order of precedence, the defaults, the
+// configuration file, TRACKER_* environment variables and the flags that were
+// explicitly set, and validates the result.
+func Load(flags *pflag.FlagSet) (Config, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	for flag, key := range flagKeys {
+		if f := flags.Lookup(flag); f != nil && f.Changed {
+			if err := v.BindPFlag(key, f); err != nil {
+				return Config{}, fmt.Errorf("%w failed to bind flag %s", err, flag)
+			}
+		}
+	}
+
+	path, err := flags.GetString("config")
+	if err != nil {
+		return Config{}, err
+	}
+	if path == "" {
+		path = v.GetString("config")
+	}
+
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return Config{}, fmt.Errorf("%w failed to read config file %s", err, path)
+		}
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return Config{}, fmt.Errorf("%w failed to decode config", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (c Config) Validate() error {
+	problems := make([]string, 0)
+
+	if c.Database.Host == "" {
+		problems = append(problems, "database.host is required")
+	}
+	if c.Database.Port <= 0 || c.Database.Port > 65535 {
+		problems = append(problems, "database.port must be between 1 and 65535")
+	}
+	if c.Database.User == "" {
+		problems = append(problems, "database.user is required")
+	}
+	if c.Database.Name == "" {
+		problems = append(problems, "database.name is required")
+	}
+	if !sslModes[c.Database.SSLMode] {
+		problems = append(problems, fmt.Sprintf("database.sslmode %q is not supported", c.Database.SSLMode))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		problems = append(problems, "database pool sizes can't be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems = append(problems, "database.max_idle_conns can't exceed database.max_open_conns")
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		problems = append(problems, "database.conn_max_lifetime can't be negative")
+	}
+	if c.Database.ConnectTimeout <= 0 || c.Database.ConnectRetryInterval <= 0 || c.Database.ConnectRetryMaxInterval <= 0 {
+		problems = append(problems, "database connect timeout and retry intervals must be positive")
+	}
+
+	if _, _, err := net.SplitHostPort(c.HTTP.ListenAddress); err != nil {
+		problems = append(problems, fmt.Sprintf("http.listen_address %q is invalid", c.HTTP.ListenAddress))
+	}
+	if len(c.HTTP.CORSOrigins) == 0 {
+		problems = append(problems, "http.cors_origins needs at least one origin")
+	}
+	if c.HTTP.ReadHeaderTimeout < 0 || c.HTTP.ReadTimeout < 0 || c.HTTP.WriteTimeout < 0 || c.HTTP.IdleTimeout < 0 {
+		problems = append(problems, "http timeouts can't be negative")
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		problems = append(problems, "http.shutdown_timeout must be positive")
+	}
+	for group, timeout := range c.HTTP.RouteTimeouts {
+		if timeout < 0 {
+			problems = append(problems, fmt.Sprintf("http.route_timeouts.%s can't be negative", group))
+		}
+	}
+	if c.HTTP.ClientIPHeader != "" && len(c.HTTP.TrustedProxies) == 0 {
+		problems = append(problems, "http.client_ip_header needs http.trusted_proxies")
+	}
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			problems = append(problems, fmt.Sprintf("http.trusted_proxies %q is not a CIDR range", proxy))
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if c.Tracing.OTLPEndpoint == "" {
+			problems = append(problems, "tracing.otlp_endpoint is required with the otlp exporter")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter %q is not supported", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, "tracing.sample_ratio must be between 0 and 1")
+	}
+
+	if c.TLS.Enabled() {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			problems = append(problems, "tls.cert_file and tls.key_file must be set together")
+		}
+		if c.TLS.MinVersion != "1.2" && c.TLS.MinVersion != "1.3" {
+			problems = append(problems, fmt.Sprintf("tls.min_version %q is not supported", c.TLS.MinVersion))
+		}
+		if c.TLS.ClientAuth != "require" && c.TLS.ClientAuth != "optional" {
+			problems = append(problems, fmt.Sprintf("tls.client_auth %q is not supported", c.TLS.ClientAuth))
+		}
+		if c.TLS.ReloadInterval <= 0 {
+			problems = append(problems, "tls.reload_interval must be positive")
+		}
+		if c.TLS.RedirectAddress != "" {
+			if _, _, err := net.SplitHostPort(c.TLS.RedirectAddress); err != nil {
+				problems = append(problems, fmt.Sprintf("tls.redirect_address %q is invalid", c.TLS.RedirectAddress))
+			}
+		}
+	} else if c.TLS.ClientCAFile != "" || c.TLS.RedirectAddress != "" {
+		problems = append(problems, "tls.client_ca_file and tls.redirect_address need tls.cert_file and tls.key_file")
+	}
+
+	if c.Validation.FutureTolerance < 0 {
+		problems = append(problems, "validation.future_tolerance can't be negative")
+	}
+	if c.Validation.MaxSessionLength <= 0 || c.Validation.MaxNameLength <= 0 {
+		problems = append(problems, "validation.max_session_length and validation.max_name_length must be positive")
+	}
+	switch c.Validation.OverlapPolicy {
+	case "reject", "warn", "trim":
+	default:
+		problems = append(problems, fmt.Sprintf("validation.overlap_policy %q is not supported", c.Validation.OverlapPolicy))
+	}
+
+	if c.Running.MaxDuration < 0 {
+		problems = append(problems, "running.max_duration can't be negative")
+	}
+	if _, err := c.Running.EndOfDayOffset(); err != nil {
+		problems = append(problems, fmt.Sprintf("running.end_of_day %q is not a HH:MM time", c.Running.EndOfDay))
+	}
+	if _, err := time.LoadLocation(c.Running.Location); err != nil {
+		problems = append(problems, fmt.Sprintf("running.location %q is not a known time zone", c.Running.Location))
+	}
+	if c.Running.CheckInterval <= 0 {
+		problems = append(problems, "running.check_interval must be positive")
+	}
+
+	switch c.Rounding.Mode {
+	case "none", "nearest", "up", "down":
+	default:
+		problems = append(problems, fmt.Sprintf("rounding.mode %q is not supported", c.Rounding.Mode))
+	}
+	if c.Rounding.Increment < time.Minute || c.Rounding.Increment > 24*time.Hour {
+		problems = append(problems, "rounding.increment must be between 1m and 24h")
+	}
+	switch c.Rounding.Aggregation {
+	case "entry", "day":
+	default:
+		problems = append(problems, fmt.Sprintf("rounding.aggregation %q is not supported", c.Rounding.Aggregation))
+	}
+
+	for _, threshold := range c.Budgets.Thresholds {
+		if threshold <= 0 || threshold > 1000 {
+			problems = append(problems, "budgets.thresholds must be percentages between 1 and 1000")
+
+			break
+		}
+	}
+	if _, err := time.LoadLocation(c.Budgets.Location); err != nil {
+		problems = append(problems, fmt.Sprintf("budgets.location %q is not a known time zone", c.Budgets.Location))
+	}
+	if c.Budgets.WebhookURL != "" {
+		if u, err := url.Parse(c.Budgets.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, "budgets.webhook_url must be an http or https URL")
+		}
+	}
+	if c.Budgets.WebhookTimeout <= 0 {
+		problems = append(problems, "budgets.webhook_timeout must be positive")
+	}
+
+	if c.Pomodoro.Focus < time.Minute || c.Pomodoro.Focus > 4*time.Hour {
+		problems = append(problems, "pomodoro.focus must be between 1m and 4h")
+	}
+	if c.Pomodoro.ShortBreak < 0 || c.Pomodoro.LongBreak < 0 {
+		problems = append(problems, "pomodoro breaks can't be negative")
+	}
+	if c.Pomodoro.LongBreakEvery < 0 || c.Pomodoro.Rounds < 0 {
+		problems = append(problems, "pomodoro.long_break_every and pomodoro.rounds can't be negative")
+	}
+	if c.Pomodoro.CheckInterval <= 0 {
+		problems = append(problems, "pomodoro.check_interval must be positive")
+	}
+
+	if _, err := time.LoadLocation(c.Timesheets.Location); err != nil {
+		problems = append(problems, fmt.Sprintf("timesheets.location %q is not a known time zone", c.Timesheets.Location))
+	}
+
+	if c.Frontend.Enabled && c.Frontend.APIBaseURL == "" {
+		problems = append(problems, "frontend.api_base_url is required to serve the frontend")
+	}
+
+	switch c.Logging.Format {
+	case "json", "logfmt":
+	default:
+		problems = append(problems, fmt.Sprintf("logging.format %q is not supported", c.Logging.Format))
+	}
+	if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+		problems = append(problems, fmt.Sprintf("logging.level %q is not supported", c.Logging.Level))
+	}
+
+	for group, limit := range c.RateLimits {
+		if limit.Requests <= 0 || limit.Period <= 0 || limit.Burst <= 0 {
+			problems = append(problems, fmt.Sprintf("rate_limits.%s needs positive requests, period and burst", group))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// DSN builds the PostgreSQL connection URL.
+func (d DatabaseConfig) DSN() string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:     "/" + d.Name,
+		RawQuery: url.Values{"sslmode": []string{d.SSLMode}}.Encode(),
+	}
+
+	return dsn.String()
+}
+
+// Redacted returns a copy of the configuration safe to print.
+func (c Config) Redacted() Config {
+	if c.Database.Password != "" {
+		c.Database.Password = redacted
+	}
+	if c.Debug.Token != "" {
+		c.Debug.Token = redacted
+	}
+	if c.Budgets.WebhookURL != "" {
+		// webhook URLs often embed their credentials.
+		c.Budgets.WebhookURL = redacted
+	}
+
+	return c
+}
+
+// String renders the redacted configuration as YAML.
+func (c Config) String() string {
+	out, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("failed to render config: %s", err)
+	}
+
+	return string(out)
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Record is a stored response for a request sent with an Idempotency-Key.
+type Record struct {
+	Key         string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	Completed   bool
+	CreatedAt   time.Time
+}
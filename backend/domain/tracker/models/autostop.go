@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type AutoStopReason string
+
+const (
+	AutoStopMaxDuration AutoStopReason = "max_duration"
+	AutoStopEndOfDay    AutoStopReason = "end_of_day"
+)
+
+// AutoStop records the scheduler stopping a tracker left running, At is when
+// it did so while the tracker End is when the session was stopped at.
+type AutoStop struct {
+	Reason AutoStopReason
+	At     time.Time
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"pento/code-challenge/domain"
+	"sort"
+	"time"
+)
+
+type BudgetPeriod string
+
+const (
+	// BudgetTotal budgets the whole life of the project.
+	BudgetTotal BudgetPeriod = "total"
+	// BudgetMonthly starts the budget over every calendar month.
+	BudgetMonthly BudgetPeriod = "monthly"
+)
+
+type BudgetKind string
+
+const (
+	BudgetHours BudgetKind = "hours"
+	BudgetMoney BudgetKind = "money"
+)
+
+// Budget caps the time, the money or both spent on a project. Money is in
+// minor units of Currency, the tracked time is charged at HourlyRate.
+type Budget struct {
+	ID         uint64
+	Project    string
+	Period     BudgetPeriod
+	Hours      time.Duration
+	Amount     int64
+	HourlyRate int64
+	Currency   string
+	// Thresholds are the percentages of the budget raising an alert.
+	Thresholds []int
+	Meta       domain.Meta
+}
+
+func NewBudget(id uint64, project string, period BudgetPeriod) Budget {
+	return Budget{
+		ID:      id,
+		Project: project,
+		Period:  period,
+		Meta:    domain.NewMeta(),
+	}
+}
+
+func (b Budget) IsZero() bool {
+	return b.ID == 0 && b.Project == ""
+}
+
+// PeriodAt returns the period of the budget containing at, a total budget
+// has zero bounds.
+func (b Budget) PeriodAt(at time.Time, location *time.Location) (time.Time, time.Time) {
+	if b.Period != BudgetMonthly {
+		return time.Time{}, time.Time{}
+	}
+
+	at = at.In(location)
+	start := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, location)
+
+	return start, start.AddDate(0, 1, 0)
+}
+
+// Cost charges duration at the hourly rate, by the second so long sessions
+// don't overflow.
+func (b Budget) Cost(duration time.Duration) int64 {
+	return int64(duration/time.Second) * b.HourlyRate / int64(time.Hour/time.Second)
+}
+
+// BurnPoint is the budget used up to the end of Date.
+type BurnPoint struct {
+	Date    time.Time
+	Tracked time.Duration
+	Cost    int64
+}
+
+// BurnDown compares a budget with the time tracked on its project in a
+// period, running trackers are measured up to now.
+type BurnDown struct {
+	Budget  Budget
+	Start   time.Time
+	End     time.Time
+	Tracked time.Duration
+	Cost    int64
+	Days    []BurnPoint
+}
+
+// BudgetAlert is raised the first time the use of a budget reaches one of its
+// thresholds in a period.
+type BudgetAlert struct {
+	Budget      Budget
+	Kind        BudgetKind
+	Threshold   int
+	Used        float64
+	PeriodStart time.Time
+}
+
+// BuildBurnDown sums the trackers of the budget project per day, trackers
+// crossing the period bounds are cut at them and trackers crossing midnight
+// count on both days.
+func BuildBurnDown(budget Budget, trackers []TimeTracker, start, end time.Time, location *time.Location, now time.Time) BurnDown {
+	burnDown := BurnDown{
+		Budget: budget,
+		Start:  start,
+		End:    end,
+		Days:   make([]BurnPoint, 0),
+	}
+
+	project := make([]TimeTracker, 0, len(trackers))
+	for _, tracker := range trackers {
+		if tracker.Project == budget.Project {
+			project = append(project, tracker)
+		}
+	}
+
+	daily := DailyTotals(project, start, end, location, now)
+
+	dates := make([]time.Time, 0, len(daily))
+	for date := range daily {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	for _, date := range dates {
+		burnDown.Tracked += daily[date]
+		burnDown.Days = append(burnDown.Days, BurnPoint{
+			Date:    date,
+			Tracked: burnDown.Tracked,
+			Cost:    budget.Cost(burnDown.Tracked),
+		})
+	}
+
+	burnDown.Cost = budget.Cost(burnDown.Tracked)
+
+	return burnDown
+}
+
+// HoursUsed is the percentage of the hour budget used, zero without one.
+func (b BurnDown) HoursUsed() float64 {
+	if b.Budget.Hours <= 0 {
+		return 0
+	}
+
+	return float64(b.Tracked) / float64(b.Budget.Hours) * 100
+}
+
+// AmountUsed is the percentage of the money budget used, zero without one.
+func (b BurnDown) AmountUsed() float64 {
+	if b.Budget.Amount <= 0 {
+		return 0
+	}
+
+	return float64(b.Cost) / float64(b.Budget.Amount) * 100
+}
+
+// Reached lists the thresholds the budget use has reached.
+func (b BurnDown) Reached() []BudgetAlert {
+	alerts := make([]BudgetAlert, 0)
+
+	for _, usage := range []struct {
+		kind BudgetKind
+		used float64
+	}{
+		{BudgetHours, b.HoursUsed()},
+		{BudgetMoney, b.AmountUsed()},
+	} {
+		for _, threshold := range b.Budget.Thresholds {
+			if usage.used > 0 && usage.used >= float64(threshold) {
+				alerts = append(alerts, BudgetAlert{
+					Budget:      b.Budget,
+					Kind:        usage.kind,
+					Threshold:   threshold,
+					Used:        usage.used,
+					PeriodStart: b.Start,
+				})
+			}
+		}
+	}
+
+	return alerts
+}
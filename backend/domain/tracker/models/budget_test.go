@@ -0,0 +1,153 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_BuildBurnDown(t *testing.T) {
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 5, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tracker := func(id uint64, start, end time.Time, project string) TimeTracker {
+		tracker := NewTimeTracker(id, start, end, "task")
+		tracker.Project = project
+
+		return tracker
+	}
+
+	budget := NewBudget(1, "acme", BudgetMonthly)
+	budget.Hours = 10 * time.Hour
+	budget.Amount = 50000
+	budget.HourlyRate = 10000
+	budget.Thresholds = []int{80, 100}
+
+	type expectation struct {
+		tracked time.Duration
+		cost    int64
+		days    []time.Duration
+		reached []BudgetKind
+	}
+
+	testCases := []struct {
+		description string
+		trackers    []TimeTracker
+		expected    expectation
+	}{
+		{
+			description: "when the budget is barely used",
+			trackers:    []TimeTracker{tracker(1, at(3, 9), at(3, 10), "acme")},
+			expected: expectation{
+				tracked: time.Hour,
+				cost:    10000,
+				days:    []time.Duration{time.Hour},
+				reached: []BudgetKind{},
+			},
+		},
+		{
+			description: "when other projects are tracked",
+			trackers: []TimeTracker{
+				tracker(1, at(3, 9), at(3, 13), "acme"),
+				tracker(2, at(3, 13), at(3, 23), "globex"),
+				tracker(3, at(4, 9), at(4, 10), "acme"),
+			},
+			expected: expectation{
+				tracked: 5 * time.Hour,
+				cost:    50000,
+				days:    []time.Duration{4 * time.Hour, 5 * time.Hour},
+				reached: []BudgetKind{BudgetMoney, BudgetMoney},
+			},
+		},
+		{
+			description: "when a tracker crosses midnight",
+			trackers:    []TimeTracker{tracker(1, at(3, 22), at(4, 2), "acme")},
+			expected: expectation{
+				tracked: 4 * time.Hour,
+				cost:    40000,
+				days:    []time.Duration{2 * time.Hour, 4 * time.Hour},
+				reached: []BudgetKind{BudgetMoney},
+			},
+		},
+		{
+			description: "when a tracker started in the previous period",
+			trackers: []TimeTracker{
+				tracker(1, time.Date(2021, 4, 30, 20, 0, 0, 0, time.UTC), at(1, 6), "acme"),
+				tracker(2, at(2, 9), time.Time{}, "acme"),
+			},
+			expected: expectation{
+				tracked: 8 * time.Hour,
+				cost:    80000,
+				days:    []time.Duration{6 * time.Hour, 8 * time.Hour},
+				reached: []BudgetKind{BudgetHours, BudgetMoney, BudgetMoney},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			start, end := budget.PeriodAt(at(2, 12), time.UTC)
+			g.Expect(start).To(Equal(at(1, 0)))
+			g.Expect(end).To(Equal(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)))
+
+			burnDown := BuildBurnDown(budget, tc.trackers, start, end, time.UTC, at(2, 11))
+
+			g.Expect(burnDown.Tracked).To(Equal(tc.expected.tracked))
+			g.Expect(burnDown.Cost).To(Equal(tc.expected.cost))
+
+			days := make([]time.Duration, 0, len(burnDown.Days))
+			for _, point := range burnDown.Days {
+				days = append(days, point.Tracked)
+			}
+			g.Expect(days).To(Equal(tc.expected.days))
+
+			reached := make([]BudgetKind, 0)
+			for _, alert := range burnDown.Reached() {
+				reached = append(reached, alert.Kind)
+				g.Expect(alert.PeriodStart).To(Equal(start))
+			}
+			g.Expect(reached).To(Equal(tc.expected.reached))
+		})
+	}
+}
+
+func Test_Budget_Cost(t *testing.T) {
+
+	budget := NewBudget(1, "acme", BudgetTotal)
+	budget.HourlyRate = 10000
+
+	testCases := []struct {
+		description string
+		input       time.Duration
+		expected    int64
+	}{
+		{
+			description: "when the session is short",
+			input:       90 * time.Minute,
+			expected:    15000,
+		},
+		{
+			description: "when less than a second is tracked",
+			input:       500 * time.Millisecond,
+			expected:    0,
+		},
+		{
+			description: "when more than 256 hours are tracked",
+			input:       300 * time.Hour,
+			expected:    3000000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(budget.Cost(tc.input)).To(Equal(tc.expected), "should be the same cost")
+		})
+	}
+}
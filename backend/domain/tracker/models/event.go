@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventStopped EventType = "stopped"
+	EventDeleted EventType = "deleted"
+	// EventReset tells a resuming client the events it missed are gone, it
+	// has to reload the trackers.
+	EventReset EventType = "reset"
+)
+
+type TrackerEvent struct {
+	ID         uint64
+	Type       EventType
+	Tracker    TimeTracker
+	OccurredAt time.Time
+}
+
+func NewTrackerEvent(eventType EventType, tracker TimeTracker) TrackerEvent {
+	return TrackerEvent{
+		Type:       eventType,
+		Tracker:    tracker,
+		OccurredAt: time.Now(),
+	}
+}
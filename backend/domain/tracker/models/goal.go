@@ -0,0 +1,163 @@
+package models
+
+import (
+	"pento/code-challenge/domain"
+	"time"
+)
+
+// Goals are the hours to track, per weekday and per week. Weeks start on
+// Monday.
+type Goals struct {
+	// Daily is indexed by time.Weekday, a zero target is a day off.
+	Daily  [7]time.Duration
+	Weekly time.Duration
+	Meta   domain.Meta
+}
+
+func NewGoals() Goals {
+	return Goals{Meta: domain.NewMeta()}
+}
+
+// Progress compares the tracked time with a target.
+type Progress struct {
+	Tracked time.Duration
+	Target  time.Duration
+}
+
+// Met reports whether a set target is reached.
+func (p Progress) Met() bool {
+	return p.Target > 0 && p.Tracked >= p.Target
+}
+
+func (p Progress) Remaining() time.Duration {
+	if p.Tracked >= p.Target {
+		return 0
+	}
+
+	return p.Target - p.Tracked
+}
+
+func (p Progress) Percent() float64 {
+	if p.Target <= 0 {
+		return 0
+	}
+
+	return float64(p.Tracked) / float64(p.Target) * 100
+}
+
+// Streak counts the consecutive days meeting their daily goal, days off
+// neither break nor extend it and today only counts once met.
+type Streak struct {
+	Current int
+	Longest int
+	// LastMet is the last day meeting its goal, zero when there is none.
+	LastMet time.Time
+}
+
+type GoalProgress struct {
+	// Date is the midnight starting today in the progress location.
+	Date      time.Time
+	WeekStart time.Time
+	Today     Progress
+	Week      Progress
+	// Running reports whether a running tracker is counted.
+	Running bool
+	Streak  Streak
+}
+
+// StartOfWeek returns the midnight starting the Monday of the week of t.
+func StartOfWeek(t time.Time, location *time.Location) time.Time {
+	day := StartOfDay(t, location)
+	offset := (int(day.Weekday()) + 6) % 7
+
+	return day.AddDate(0, 0, -offset)
+}
+
+// StartOfDay returns the midnight starting the day of t in location.
+func StartOfDay(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
+
+// DailyTotals sums the tracked time per day in [start, end), a zero bound
+// leaves that side open. Trackers crossing midnight count on both days.
+// Running trackers are measured up to now.
+func DailyTotals(trackers []TimeTracker, start, end time.Time, location *time.Location, now time.Time) map[time.Time]time.Duration {
+	totals := make(map[time.Time]time.Duration)
+
+	for _, tracker := range trackers {
+		from, to := tracker.Start, trackerEnd(tracker, now)
+		if !start.IsZero() && from.Before(start) {
+			from = start
+		}
+		if !end.IsZero() && to.After(end) {
+			to = end
+		}
+
+		for from.Before(to) {
+			day := StartOfDay(from, location)
+			next := day.AddDate(0, 0, 1)
+			if next.After(to) {
+				next = to
+			}
+
+			totals[day] += next.Sub(from)
+			from = next
+		}
+	}
+
+	return totals
+}
+
+// BuildGoalProgress computes today's and this week's progress and the streak
+// over the days since streakStart out of the trackers covering that period.
+func BuildGoalProgress(goals Goals, trackers []TimeTracker, streakStart time.Time, location *time.Location, now time.Time) GoalProgress {
+	today := StartOfDay(now, location)
+	weekStart := StartOfWeek(now, location)
+	streakStart = StartOfDay(streakStart, location)
+
+	from := streakStart
+	if weekStart.Before(from) {
+		from = weekStart
+	}
+
+	totals := DailyTotals(trackers, from, now, location, now)
+
+	progress := GoalProgress{
+		Date:      today,
+		WeekStart: weekStart,
+		Today:     Progress{Tracked: totals[today], Target: goals.Daily[today.Weekday()]},
+		Week:      Progress{Target: goals.Weekly},
+	}
+
+	for day := weekStart; !day.After(today); day = day.AddDate(0, 0, 1) {
+		progress.Week.Tracked += totals[day]
+	}
+
+	for _, tracker := range trackers {
+		if tracker.IsRunning() {
+			progress.Running = true
+		}
+	}
+
+	for day := streakStart; !day.After(today); day = day.AddDate(0, 0, 1) {
+		target := goals.Daily[day.Weekday()]
+		if target <= 0 {
+			continue
+		}
+
+		switch {
+		case totals[day] >= target:
+			progress.Streak.Current++
+			progress.Streak.LastMet = day
+			if progress.Streak.Current > progress.Streak.Longest {
+				progress.Streak.Longest = progress.Streak.Current
+			}
+		case !day.Equal(today):
+			progress.Streak.Current = 0
+		}
+	}
+
+	return progress
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_BuildGoalProgress(t *testing.T) {
+
+	// 2021-05-05 is a Wednesday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 5, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	goals := NewGoals()
+	for weekday := time.Monday; weekday <= time.Friday; weekday++ {
+		goals.Daily[weekday] = 8 * time.Hour
+	}
+	goals.Weekly = 40 * time.Hour
+
+	type expectation struct {
+		today   time.Duration
+		week    time.Duration
+		running bool
+		streak  Streak
+	}
+
+	testCases := []struct {
+		description string
+		trackers    []TimeTracker
+		expected    expectation
+	}{
+		{
+			description: "when the running tracker counts towards today",
+			trackers: []TimeTracker{
+				NewTimeTracker(1, at(3, 9, 0), at(3, 17, 0), "a"),
+				NewTimeTracker(2, at(4, 9, 0), at(4, 17, 0), "b"),
+				NewTimeTracker(3, at(5, 9, 0), time.Time{}, "c"),
+			},
+			expected: expectation{
+				today:   5*time.Hour + 12*time.Minute,
+				week:    21*time.Hour + 12*time.Minute,
+				running: true,
+				streak:  Streak{Current: 2, Longest: 2, LastMet: at(4, 0, 0)},
+			},
+		},
+		{
+			description: "when a missed day breaks the streak",
+			trackers: []TimeTracker{
+				NewTimeTracker(1, at(3, 9, 0), at(3, 17, 0), "a"),
+				NewTimeTracker(2, at(4, 9, 0), at(4, 12, 0), "b"),
+			},
+			expected: expectation{
+				week:   11 * time.Hour,
+				streak: Streak{Current: 0, Longest: 1, LastMet: at(3, 0, 0)},
+			},
+		},
+		{
+			description: "when the weekend is a day off and a session crosses midnight",
+			trackers: []TimeTracker{
+				NewTimeTracker(1, at(1, 9, 0), at(1, 17, 0), "saturday"),
+				NewTimeTracker(2, at(4, 20, 0), at(5, 4, 0), "tuesday night"),
+				NewTimeTracker(3, at(5, 4, 0), at(5, 8, 0), "wednesday morning"),
+			},
+			expected: expectation{
+				today:  8 * time.Hour,
+				week:   12 * time.Hour,
+				streak: Streak{Current: 1, Longest: 1, LastMet: at(5, 0, 0)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			progress := BuildGoalProgress(goals, tc.trackers, at(1, 0, 0), time.UTC, at(5, 14, 12))
+
+			g.Expect(progress.Date).To(Equal(at(5, 0, 0)))
+			g.Expect(progress.WeekStart).To(Equal(at(3, 0, 0)))
+			g.Expect(progress.Today.Tracked).To(Equal(tc.expected.today))
+			g.Expect(progress.Today.Target).To(Equal(8 * time.Hour))
+			g.Expect(progress.Week.Tracked).To(Equal(tc.expected.week))
+			g.Expect(progress.Running).To(Equal(tc.expected.running))
+			g.Expect(progress.Streak).To(Equal(tc.expected.streak))
+		})
+	}
+}
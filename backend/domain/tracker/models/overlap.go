@@ -0,0 +1,88 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Overlap is a period tracked by two sessions at once, End is zero when both
+// sessions are still running.
+type Overlap struct {
+	First  TimeTracker
+	Second TimeTracker
+	Start  time.Time
+	End    time.Time
+}
+
+// Overlaps reports whether both trackers cover a common instant. A running
+// tracker extends indefinitely and a session ending when the other starts
+// doesn't overlap it.
+func (t TimeTracker) Overlaps(other TimeTracker) bool {
+	return (other.IsRunning() || t.Start.Before(other.End)) &&
+		(t.IsRunning() || other.Start.Before(t.End))
+}
+
+// OverlapsAt reports whether both trackers covered a common instant by now,
+// running trackers are measured up to now.
+func (t TimeTracker) OverlapsAt(other TimeTracker, now time.Time) bool {
+	return t.measuredAt(now).Overlaps(other.measuredAt(now))
+}
+
+// measuredAt returns the tracker ended at now when it is still running.
+func (t TimeTracker) measuredAt(now time.Time) TimeTracker {
+	if t.IsRunning() {
+		t.End = now
+		if t.End.Before(t.Start) {
+			t.End = t.Start
+		}
+	}
+
+	return t
+}
+
+// FindOverlaps returns every pair of overlapping trackers, ordered by the
+// start of the overlap.
+func FindOverlaps(trackers []TimeTracker) []Overlap {
+	sorted := make([]TimeTracker, len(trackers))
+	copy(sorted, trackers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Start.Equal(sorted[j].Start) {
+			return sorted[i].ID < sorted[j].ID
+		}
+
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	overlaps := make([]Overlap, 0)
+
+	for i, first := range sorted {
+		for _, second := range sorted[i+1:] {
+			// later trackers start even later, none of them can overlap first
+			if !first.IsRunning() && !second.Start.Before(first.End) {
+				break
+			}
+
+			if !first.Overlaps(second) {
+				continue
+			}
+
+			overlap := Overlap{First: first, Second: second, Start: second.Start}
+			switch {
+			case first.IsRunning():
+				overlap.End = second.End
+			case second.IsRunning() || first.End.Before(second.End):
+				overlap.End = first.End
+			default:
+				overlap.End = second.End
+			}
+
+			overlaps = append(overlaps, overlap)
+		}
+	}
+
+	sort.SliceStable(overlaps, func(i, j int) bool {
+		return overlaps[i].Start.Before(overlaps[j].Start)
+	})
+
+	return overlaps
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_FindOverlaps(t *testing.T) {
+
+	at := func(hour int) time.Time {
+		return time.Date(2021, 5, 3, hour, 0, 0, 0, time.UTC)
+	}
+
+	type overlap struct {
+		first, second uint64
+		start, end    time.Time
+	}
+
+	testCases := []struct {
+		description string
+		input       []TimeTracker
+		expected    []overlap
+	}{
+		{
+			description: "when sessions follow each other",
+			input: []TimeTracker{
+				NewTimeTracker(1, at(8), at(10), "a"),
+				NewTimeTracker(2, at(10), at(12), "b"),
+			},
+			expected: []overlap{},
+		},
+		{
+			description: "when sessions partially overlap",
+			input: []TimeTracker{
+				NewTimeTracker(2, at(9), at(12), "b"),
+				NewTimeTracker(1, at(8), at(10), "a"),
+			},
+			expected: []overlap{{first: 1, second: 2, start: at(9), end: at(10)}},
+		},
+		{
+			description: "when a session contains another",
+			input: []TimeTracker{
+				NewTimeTracker(1, at(8), at(12), "a"),
+				NewTimeTracker(2, at(9), at(10), "b"),
+				NewTimeTracker(3, at(13), at(14), "c"),
+			},
+			expected: []overlap{{first: 1, second: 2, start: at(9), end: at(10)}},
+		},
+		{
+			description: "when a running tracker overlaps later sessions",
+			input: []TimeTracker{
+				NewTimeTracker(1, at(8), time.Time{}, "a"),
+				NewTimeTracker(2, at(9), at(10), "b"),
+				NewTimeTracker(3, at(11), time.Time{}, "c"),
+			},
+			expected: []overlap{
+				{first: 1, second: 2, start: at(9), end: at(10)},
+				{first: 1, second: 3, start: at(11)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			result := make([]overlap, 0)
+			for _, found := range FindOverlaps(tc.input) {
+				result = append(result, overlap{
+					first:  found.First.ID,
+					second: found.Second.ID,
+					start:  found.Start,
+					end:    found.End,
+				})
+			}
+
+			g.Expect(result).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_TimeTracker_OverlapsAt(t *testing.T) {
+
+	at := func(hour int) time.Time {
+		return time.Date(2021, 5, 3, hour, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		description string
+		tracker     TimeTracker
+		other       TimeTracker
+		expected    bool
+	}{
+		{
+			description: "when a tracker starts now while another runs",
+			tracker:     NewTimeTracker(1, at(12), time.Time{}, "a"),
+			other:       NewTimeTracker(2, at(9), time.Time{}, "b"),
+			expected:    false,
+		},
+		{
+			description: "when a tracker started before now while another runs",
+			tracker:     NewTimeTracker(1, at(10), time.Time{}, "a"),
+			other:       NewTimeTracker(2, at(9), time.Time{}, "b"),
+			expected:    true,
+		},
+		{
+			description: "when a session ends after a running tracker started",
+			tracker:     NewTimeTracker(1, at(9), at(11), "a"),
+			other:       NewTimeTracker(2, at(10), time.Time{}, "b"),
+			expected:    true,
+		},
+		{
+			description: "when a session ends before a running tracker started",
+			tracker:     NewTimeTracker(1, at(9), at(10), "a"),
+			other:       NewTimeTracker(2, at(10), time.Time{}, "b"),
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(tc.tracker.OverlapsAt(tc.other, at(12))).To(Equal(tc.expected))
+			g.Expect(tc.other.OverlapsAt(tc.tracker, at(12))).To(Equal(tc.expected), "should be symmetric")
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"pento/code-challenge/domain"
+	"time"
+)
+
+// PlannedBlock is time set aside for a project, such as Tuesday 9 to 12 for
+// a client, to compare with the time actually tracked.
+type PlannedBlock struct {
+	ID      uint64
+	Start   time.Time
+	End     time.Time
+	Project string
+	Note    string
+	Meta    domain.Meta
+}
+
+func NewPlannedBlock(id uint64, start, end time.Time, project string) PlannedBlock {
+	return PlannedBlock{
+		ID:      id,
+		Start:   start,
+		End:     end,
+		Project: project,
+		Meta:    domain.NewMeta(),
+	}
+}
+
+func (b PlannedBlock) IsZero() bool {
+	return b.ID == 0 &&
+		b.Start.IsZero() &&
+		b.End.IsZero() &&
+		b.Project == ""
+}
+
+func (b PlannedBlock) Duration() time.Duration {
+	return b.End.Sub(b.Start)
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// PlanVariance compares planned and tracked time. Matched is the tracked
+// time falling inside blocks of the same project, Variance is Actual minus
+// Planned so unplanned work shows as positive and missed blocks as negative.
+type PlanVariance struct {
+	Project  string
+	Planned  time.Duration
+	Actual   time.Duration
+	Matched  time.Duration
+	Variance time.Duration
+}
+
+// BlockMatch is a planned block and the trackers of its project covering
+// part of it.
+type BlockMatch struct {
+	Block    PlannedBlock
+	Matched  time.Duration
+	Trackers []uint64
+}
+
+type PlanDay struct {
+	// Date is the midnight starting the day in the report location.
+	Date     time.Time
+	Blocks   []BlockMatch
+	Projects []PlanVariance
+	Total    PlanVariance
+}
+
+// PlanReport compares the planned blocks with the tracked time per day and
+// project, a block or tracker belongs to the day it starts.
+type PlanReport struct {
+	Start    time.Time
+	End      time.Time
+	Days     []PlanDay
+	Projects []PlanVariance
+	Total    PlanVariance
+}
+
+// BuildPlanReport matches trackers to blocks by time and project, running
+// trackers are measured up to now.
+func BuildPlanReport(blocks []PlannedBlock, trackers []TimeTracker, location *time.Location, now time.Time) PlanReport {
+	days := make(map[time.Time]*PlanDay)
+	variances := make(map[time.Time]map[string]*PlanVariance)
+
+	variance := func(start time.Time, project string) (*PlanDay, *PlanVariance) {
+		start = start.In(location)
+		date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+
+		day, ok := days[date]
+		if !ok {
+			day = &PlanDay{Date: date, Blocks: make([]BlockMatch, 0)}
+			days[date] = day
+			variances[date] = make(map[string]*PlanVariance)
+		}
+
+		projectVariance, ok := variances[date][project]
+		if !ok {
+			projectVariance = &PlanVariance{Project: project}
+			variances[date][project] = projectVariance
+		}
+
+		return day, projectVariance
+	}
+
+	sortedBlocks := make([]PlannedBlock, len(blocks))
+	copy(sortedBlocks, blocks)
+	sort.SliceStable(sortedBlocks, func(i, j int) bool {
+		return sortedBlocks[i].Start.Before(sortedBlocks[j].Start)
+	})
+
+	for _, block := range sortedBlocks {
+		match := BlockMatch{Block: block, Trackers: make([]uint64, 0)}
+
+		for _, tracker := range trackers {
+			if tracker.Project != block.Project {
+				continue
+			}
+
+			shared := sharedTime(block.Start, block.End, tracker.Start, trackerEnd(tracker, now))
+			if shared > 0 {
+				match.Matched += shared
+				match.Trackers = append(match.Trackers, tracker.ID)
+			}
+		}
+
+		day, projectVariance := variance(block.Start, block.Project)
+		day.Blocks = append(day.Blocks, match)
+		projectVariance.Planned += block.Duration()
+		projectVariance.Matched += match.Matched
+	}
+
+	for _, tracker := range trackers {
+		_, projectVariance := variance(tracker.Start, tracker.Project)
+		projectVariance.Actual += tracker.Duration(now)
+	}
+
+	report := PlanReport{Days: make([]PlanDay, 0, len(days))}
+	projects := make(map[string]*PlanVariance)
+
+	for date, day := range days {
+		day.Projects = sortedVariances(variances[date])
+
+		for _, projectVariance := range day.Projects {
+			addVariance(&day.Total, projectVariance)
+
+			total, ok := projects[projectVariance.Project]
+			if !ok {
+				total = &PlanVariance{Project: projectVariance.Project}
+				projects[projectVariance.Project] = total
+			}
+			addVariance(total, projectVariance)
+		}
+
+		addVariance(&report.Total, day.Total)
+		report.Days = append(report.Days, *day)
+	}
+
+	sort.Slice(report.Days, func(i, j int) bool {
+		return report.Days[i].Date.Before(report.Days[j].Date)
+	})
+	report.Projects = sortedVariances(projects)
+
+	return report
+}
+
+func trackerEnd(tracker TimeTracker, now time.Time) time.Time {
+	if tracker.IsRunning() {
+		return now
+	}
+
+	return tracker.End
+}
+
+// sharedTime is the length of the intersection of two periods.
+func sharedTime(firstStart, firstEnd, secondStart, secondEnd time.Time) time.Duration {
+	start := firstStart
+	if secondStart.After(start) {
+		start = secondStart
+	}
+
+	end := firstEnd
+	if secondEnd.Before(end) {
+		end = secondEnd
+	}
+
+	if !end.After(start) {
+		return 0
+	}
+
+	return end.Sub(start)
+}
+
+func addVariance(total *PlanVariance, variance PlanVariance) {
+	total.Planned += variance.Planned
+	total.Actual += variance.Actual
+	total.Matched += variance.Matched
+	total.Variance = total.Actual - total.Planned
+}
+
+func sortedVariances(variances map[string]*PlanVariance) []PlanVariance {
+	sorted := make([]PlanVariance, 0, len(variances))
+	for _, variance := range variances {
+		variance.Variance = variance.Actual - variance.Planned
+		sorted = append(sorted, *variance)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Project < sorted[j].Project
+	})
+
+	return sorted
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_BuildPlanReport(t *testing.T) {
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 5, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tracker := func(id uint64, start, end time.Time, project string) TimeTracker {
+		tracker := NewTimeTracker(id, start, end, "task")
+		tracker.Project = project
+
+		return tracker
+	}
+
+	testCases := []struct {
+		description string
+		blocks      []PlannedBlock
+		trackers    []TimeTracker
+		expected    []PlanVariance
+	}{
+		{
+			description: "when the time is tracked as planned",
+			blocks:      []PlannedBlock{NewPlannedBlock(1, at(4, 9), at(4, 12), "acme")},
+			trackers:    []TimeTracker{tracker(1, at(4, 9), at(4, 12), "acme")},
+			expected: []PlanVariance{
+				{Project: "acme", Planned: 3 * time.Hour, Actual: 3 * time.Hour, Matched: 3 * time.Hour},
+			},
+		},
+		{
+			description: "when the work runs late and on another project",
+			blocks:      []PlannedBlock{NewPlannedBlock(1, at(4, 9), at(4, 12), "acme")},
+			trackers: []TimeTracker{
+				tracker(1, at(4, 10), at(4, 13), "acme"),
+				tracker(2, at(4, 9), at(4, 10), "globex"),
+			},
+			expected: []PlanVariance{
+				{Project: "acme", Planned: 3 * time.Hour, Actual: 3 * time.Hour, Matched: 2 * time.Hour},
+				{Project: "globex", Actual: time.Hour, Variance: time.Hour},
+			},
+		},
+		{
+			description: "when a block is skipped",
+			blocks:      []PlannedBlock{NewPlannedBlock(1, at(4, 9), at(4, 12), "acme")},
+			expected: []PlanVariance{
+				{Project: "acme", Planned: 3 * time.Hour, Variance: -3 * time.Hour},
+			},
+		},
+		{
+			description: "when a tracker is still running",
+			blocks:      []PlannedBlock{NewPlannedBlock(1, at(4, 9), at(4, 12), "acme")},
+			trackers:    []TimeTracker{tracker(1, at(4, 11), time.Time{}, "acme")},
+			expected: []PlanVariance{
+				{Project: "acme", Planned: 3 * time.Hour, Actual: 3 * time.Hour, Matched: time.Hour},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			report := BuildPlanReport(tc.blocks, tc.trackers, time.UTC, at(4, 14))
+
+			g.Expect(report.Days).To(HaveLen(1))
+			g.Expect(report.Days[0].Date).To(Equal(at(4, 0)))
+			g.Expect(report.Days[0].Projects).To(Equal(tc.expected))
+			g.Expect(report.Projects).To(Equal(tc.expected))
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"pento/code-challenge/domain"
+	"sort"
+	"time"
+)
+
+type PomodoroPhaseKind string
+
+const (
+	PhaseFocus      PomodoroPhaseKind = "focus"
+	PhaseShortBreak PomodoroPhaseKind = "short_break"
+	PhaseLongBreak  PomodoroPhaseKind = "long_break"
+)
+
+// PomodoroSchedule alternates focus phases and breaks, every LongBreakEvery
+// focus phase is followed by a long break. A schedule with Rounds ends after
+// that many focus phases, it runs until stopped otherwise.
+type PomodoroSchedule struct {
+	Focus          time.Duration
+	ShortBreak     time.Duration
+	LongBreak      time.Duration
+	LongBreakEvery int
+	Rounds         int
+}
+
+// PomodoroPhase is a focus phase or a break of a round, focus phases are
+// tracked by the tracker of TrackerID. Completed is false for a phase cut
+// short by a stop.
+type PomodoroPhase struct {
+	Kind      PomodoroPhaseKind
+	Round     int
+	Start     time.Time
+	End       time.Time
+	TrackerID uint64
+	Completed bool
+}
+
+func (p PomodoroPhase) Duration() time.Duration {
+	return p.End.Sub(p.Start)
+}
+
+// Pomodoro is a tracker session following a schedule. Phases holds the
+// phases recorded so far, TrackerID the tracker of the focus phase in
+// progress.
+type Pomodoro struct {
+	ID        uint64
+	Name      string
+	Project   string
+	Tags      []string
+	Schedule  PomodoroSchedule
+	Start     time.Time
+	End       time.Time
+	TrackerID uint64
+	Phases    []PomodoroPhase
+	Meta      domain.Meta
+}
+
+func NewPomodoro(id uint64, name string, schedule PomodoroSchedule, start time.Time) Pomodoro {
+	return Pomodoro{
+		ID:       id,
+		Name:     name,
+		Schedule: schedule,
+		Start:    start,
+		Phases:   make([]PomodoroPhase, 0),
+		Meta:     domain.NewMeta(),
+	}
+}
+
+func (p Pomodoro) IsZero() bool {
+	return p.ID == 0 && p.Start.IsZero()
+}
+
+func (p Pomodoro) IsActive() bool {
+	return p.End.IsZero()
+}
+
+// Phases returns the phases of a schedule started at start which begin no
+// later than until, the last one may still be in progress.
+func (s PomodoroSchedule) Phases(start, until time.Time) []PomodoroPhase {
+	phases := make([]PomodoroPhase, 0)
+
+	if s.Focus <= 0 {
+		return phases
+	}
+
+	for round := 1; s.Rounds == 0 || round <= s.Rounds; round++ {
+		if start.After(until) {
+			break
+		}
+
+		focus := PomodoroPhase{Kind: PhaseFocus, Round: round, Start: start, End: start.Add(s.Focus)}
+		phases = append(phases, focus)
+		start = focus.End
+
+		if round == s.Rounds || start.After(until) {
+			break
+		}
+
+		brk := PomodoroPhase{Kind: PhaseShortBreak, Round: round, Start: start, End: start.Add(s.ShortBreak)}
+		if s.LongBreakEvery > 0 && round%s.LongBreakEvery == 0 {
+			brk.Kind = PhaseLongBreak
+			brk.End = start.Add(s.LongBreak)
+		}
+
+		if brk.End.After(brk.Start) {
+			phases = append(phases, brk)
+		}
+		start = brk.End
+	}
+
+	return phases
+}
+
+// Current returns the phase in progress at now, false when the pomodoro is
+// over.
+func (p Pomodoro) Current(now time.Time) (PomodoroPhase, bool) {
+	if !p.IsActive() {
+		return PomodoroPhase{}, false
+	}
+
+	phases := p.Schedule.Phases(p.Start, now)
+	if len(phases) == 0 {
+		return PomodoroPhase{}, false
+	}
+
+	current := phases[len(phases)-1]
+	if !current.End.After(now) {
+		return PomodoroPhase{}, false
+	}
+
+	if current.Kind == PhaseFocus {
+		current.TrackerID = p.TrackerID
+	}
+
+	return current, true
+}
+
+// PomodoroDay sums the recorded phases of a day, Completed counts the focus
+// phases run to their end.
+type PomodoroDay struct {
+	Date        time.Time
+	Completed   int
+	Interrupted int
+	Focus       time.Duration
+	Break       time.Duration
+}
+
+// PomodoroDays groups phases per day of their start in location.
+func PomodoroDays(phases []PomodoroPhase, location *time.Location) []PomodoroDay {
+	sorted := make([]PomodoroPhase, len(phases))
+	copy(sorted, phases)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	days := make([]PomodoroDay, 0)
+
+	for _, phase := range sorted {
+		date := StartOfDay(phase.Start, location)
+
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, PomodoroDay{Date: date})
+		}
+
+		day := &days[len(days)-1]
+		switch {
+		case phase.Kind != PhaseFocus:
+			day.Break += phase.Duration()
+		case phase.Completed:
+			day.Completed++
+			day.Focus += phase.Duration()
+		default:
+			day.Interrupted++
+			day.Focus += phase.Duration()
+		}
+	}
+
+	return days
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_PomodoroSchedule_Phases(t *testing.T) {
+
+	start := time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	schedule := PomodoroSchedule{
+		Focus:          25 * time.Minute,
+		ShortBreak:     5 * time.Minute,
+		LongBreak:      15 * time.Minute,
+		LongBreakEvery: 2,
+		Rounds:         3,
+	}
+
+	type phase struct {
+		kind  PomodoroPhaseKind
+		round int
+		start time.Time
+		end   time.Time
+	}
+
+	testCases := []struct {
+		description string
+		schedule    PomodoroSchedule
+		until       time.Time
+		expected    []phase
+	}{
+		{
+			description: "when the first focus phase is in progress",
+			schedule:    schedule,
+			until:       at(10),
+			expected: []phase{
+				{PhaseFocus, 1, at(0), at(25)},
+			},
+		},
+		{
+			description: "when every second break is a long one",
+			schedule:    schedule,
+			until:       at(61),
+			expected: []phase{
+				{PhaseFocus, 1, at(0), at(25)},
+				{PhaseShortBreak, 1, at(25), at(30)},
+				{PhaseFocus, 2, at(30), at(55)},
+				{PhaseLongBreak, 2, at(55), at(70)},
+			},
+		},
+		{
+			description: "when the last round isn't followed by a break",
+			schedule:    schedule,
+			until:       at(240),
+			expected: []phase{
+				{PhaseFocus, 1, at(0), at(25)},
+				{PhaseShortBreak, 1, at(25), at(30)},
+				{PhaseFocus, 2, at(30), at(55)},
+				{PhaseLongBreak, 2, at(55), at(70)},
+				{PhaseFocus, 3, at(70), at(95)},
+			},
+		},
+		{
+			description: "when breaks are skipped",
+			schedule:    PomodoroSchedule{Focus: 25 * time.Minute},
+			until:       at(51),
+			expected: []phase{
+				{PhaseFocus, 1, at(0), at(25)},
+				{PhaseFocus, 2, at(25), at(50)},
+				{PhaseFocus, 3, at(50), at(75)},
+			},
+		},
+		{
+			description: "when the schedule has no focus time",
+			schedule:    PomodoroSchedule{ShortBreak: 5 * time.Minute},
+			until:       at(60),
+			expected:    []phase{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			phases := tc.schedule.Phases(start, tc.until)
+
+			actual := make([]phase, 0, len(phases))
+			for _, p := range phases {
+				actual = append(actual, phase{p.Kind, p.Round, p.Start, p.End})
+			}
+
+			g.Expect(actual).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_Pomodoro_Current(t *testing.T) {
+
+	start := time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	pomodoro := NewPomodoro(1, "deep work", PomodoroSchedule{Focus: 25 * time.Minute, ShortBreak: 5 * time.Minute, Rounds: 2}, start)
+	pomodoro.TrackerID = 7
+
+	ended := pomodoro
+	ended.End = at(10)
+
+	testCases := []struct {
+		description string
+		pomodoro    Pomodoro
+		now         time.Time
+		expected    PomodoroPhase
+		active      bool
+	}{
+		{
+			description: "when a focus phase is in progress",
+			pomodoro:    pomodoro,
+			now:         at(10),
+			expected:    PomodoroPhase{Kind: PhaseFocus, Round: 1, Start: at(0), End: at(25), TrackerID: 7},
+			active:      true,
+		},
+		{
+			description: "when a break is in progress",
+			pomodoro:    pomodoro,
+			now:         at(25),
+			expected:    PomodoroPhase{Kind: PhaseShortBreak, Round: 1, Start: at(25), End: at(30)},
+			active:      true,
+		},
+		{
+			description: "when the last round is over",
+			pomodoro:    pomodoro,
+			now:         at(55),
+			active:      false,
+		},
+		{
+			description: "when the pomodoro was stopped",
+			pomodoro:    ended,
+			now:         at(15),
+			active:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			current, active := tc.pomodoro.Current(tc.now)
+
+			g.Expect(active).To(Equal(tc.active))
+			g.Expect(current).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_PomodoroDays(t *testing.T) {
+	g := NewWithT(t)
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 5, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	phases := []PomodoroPhase{
+		{Kind: PhaseFocus, Round: 1, Start: at(4, 9, 0), End: at(4, 9, 25), Completed: true},
+		{Kind: PhaseFocus, Round: 1, Start: at(3, 23, 30), End: at(3, 23, 55), Completed: true},
+		{Kind: PhaseShortBreak, Round: 1, Start: at(4, 9, 25), End: at(4, 9, 30), Completed: true},
+		{Kind: PhaseFocus, Round: 2, Start: at(4, 9, 30), End: at(4, 9, 40)},
+	}
+
+	days := PomodoroDays(phases, time.UTC)
+
+	g.Expect(days).To(Equal([]PomodoroDay{
+		{Date: at(3, 0, 0), Completed: 1, Focus: 25 * time.Minute},
+		{Date: at(4, 0, 0), Completed: 1, Interrupted: 1, Focus: 35 * time.Minute, Break: 5 * time.Minute},
+	}))
+
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	days = PomodoroDays(phases, lisbon)
+	g.Expect(days).To(HaveLen(1))
+	g.Expect(days[0].Completed).To(Equal(2))
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// ReportEntry is a tracker in a report, a running tracker is measured up to
+// the time the report is built. Rounded equals Raw when rounding applies per
+// day.
+type ReportEntry struct {
+	Tracker TimeTracker
+	Raw     time.Duration
+	Rounded time.Duration
+}
+
+// ReportTotal sums the time tracked for Project, or for every project when
+// it is a day or report total.
+type ReportTotal struct {
+	Project string
+	Raw     time.Duration
+	Rounded time.Duration
+}
+
+type ReportDay struct {
+	// Date is the midnight starting the day in the report location.
+	Date     time.Time
+	Entries  []ReportEntry
+	Projects []ReportTotal
+	Total    ReportTotal
+}
+
+// Report groups trackers per day of their start. Rounded totals are always
+// the sum of the rounded units, the entries or the per day project totals
+// depending on the rounding aggregation, so they add up in invoices.
+type Report struct {
+	Start    time.Time
+	End      time.Time
+	Rounding Rounding
+	Days     []ReportDay
+	Projects []ReportTotal
+	Total    ReportTotal
+}
+
+// BuildReport groups the trackers per day in location and applies rounding.
+func BuildReport(trackers []TimeTracker, rounding Rounding, location *time.Location, now time.Time) Report {
+	sorted := make([]TimeTracker, len(trackers))
+	copy(sorted, trackers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	report := Report{
+		Rounding: rounding,
+		Days:     make([]ReportDay, 0),
+	}
+
+	for _, tracker := range sorted {
+		start := tracker.Start.In(location)
+		date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+
+		if len(report.Days) == 0 || !report.Days[len(report.Days)-1].Date.Equal(date) {
+			report.Days = append(report.Days, ReportDay{Date: date, Entries: make([]ReportEntry, 0)})
+		}
+
+		raw := tracker.Duration(now)
+		entry := ReportEntry{Tracker: tracker, Raw: raw, Rounded: raw}
+		if rounding.Aggregation != RoundPerDay {
+			entry.Rounded = rounding.Round(raw)
+		}
+
+		day := &report.Days[len(report.Days)-1]
+		day.Entries = append(day.Entries, entry)
+	}
+
+	projects := make(map[string]*ReportTotal)
+
+	for i := range report.Days {
+		day := &report.Days[i]
+		day.Projects = dayProjects(day.Entries, rounding)
+
+		for _, project := range day.Projects {
+			day.Total.Raw += project.Raw
+			day.Total.Rounded += project.Rounded
+
+			total, ok := projects[project.Project]
+			if !ok {
+				total = &ReportTotal{Project: project.Project}
+				projects[project.Project] = total
+			}
+			total.Raw += project.Raw
+			total.Rounded += project.Rounded
+		}
+
+		report.Total.Raw += day.Total.Raw
+		report.Total.Rounded += day.Total.Rounded
+	}
+
+	report.Projects = sortedTotals(projects)
+
+	return report
+}
+
+func dayProjects(entries []ReportEntry, rounding Rounding) []ReportTotal {
+	projects := make(map[string]*ReportTotal)
+
+	for _, entry := range entries {
+		total, ok := projects[entry.Tracker.Project]
+		if !ok {
+			total = &ReportTotal{Project: entry.Tracker.Project}
+			projects[entry.Tracker.Project] = total
+		}
+
+		total.Raw += entry.Raw
+		total.Rounded += entry.Rounded
+	}
+
+	if rounding.Aggregation == RoundPerDay {
+		for _, total := range projects {
+			total.Rounded = rounding.Round(total.Raw)
+		}
+	}
+
+	return sortedTotals(projects)
+}
+
+func sortedTotals(totals map[string]*ReportTotal) []ReportTotal {
+	sorted := make([]ReportTotal, 0, len(totals))
+	for _, total := range totals {
+		sorted = append(sorted, *total)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Project < sorted[j].Project
+	})
+
+	return sorted
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_BuildReport(t *testing.T) {
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 5, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tracker := func(id uint64, start, end time.Time, project string) TimeTracker {
+		tracker := NewTimeTracker(id, start, end, "task")
+		tracker.Project = project
+
+		return tracker
+	}
+
+	trackers := []TimeTracker{
+		tracker(3, at(4, 9, 0), at(4, 9, 20), "acme"),
+		tracker(1, at(3, 9, 0), at(3, 9, 10), "acme"),
+		tracker(2, at(3, 10, 0), at(3, 10, 10), "acme"),
+	}
+
+	type expectation struct {
+		days    int
+		acme    time.Duration
+		total   time.Duration
+		entries []time.Duration
+	}
+
+	testCases := []struct {
+		description string
+		rounding    Rounding
+		expected    expectation
+	}{
+		{
+			description: "when rounding every entry",
+			rounding:    Rounding{Mode: RoundUp, Increment: 15 * time.Minute, Aggregation: RoundPerEntry},
+			expected: expectation{
+				days:    2,
+				acme:    60 * time.Minute,
+				total:   60 * time.Minute,
+				entries: []time.Duration{15 * time.Minute, 15 * time.Minute, 30 * time.Minute},
+			},
+		},
+		{
+			description: "when rounding the time per day",
+			rounding:    Rounding{Mode: RoundUp, Increment: 15 * time.Minute, Aggregation: RoundPerDay},
+			expected: expectation{
+				days:    2,
+				acme:    60 * time.Minute,
+				total:   60 * time.Minute,
+				entries: []time.Duration{10 * time.Minute, 10 * time.Minute, 20 * time.Minute},
+			},
+		},
+		{
+			description: "when rounding the time per day to the nearest increment",
+			rounding:    Rounding{Mode: RoundNearest, Increment: 15 * time.Minute, Aggregation: RoundPerDay},
+			expected: expectation{
+				days:    2,
+				acme:    30 * time.Minute,
+				total:   30 * time.Minute,
+				entries: []time.Duration{10 * time.Minute, 10 * time.Minute, 20 * time.Minute},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			report := BuildReport(trackers, tc.rounding, time.UTC, at(5, 0, 0))
+
+			g.Expect(report.Rounding).To(Equal(tc.rounding))
+			g.Expect(report.Days).To(HaveLen(tc.expected.days))
+			g.Expect(report.Days[0].Date).To(Equal(at(3, 0, 0)))
+			g.Expect(report.Projects).To(HaveLen(1))
+			g.Expect(report.Projects[0].Rounded).To(Equal(tc.expected.acme))
+			g.Expect(report.Total.Raw).To(Equal(40 * time.Minute))
+			g.Expect(report.Total.Rounded).To(Equal(tc.expected.total))
+
+			entries := make([]time.Duration, 0)
+			for _, day := range report.Days {
+				for _, entry := range day.Entries {
+					g.Expect(entry.Tracker.Start).To(BeTemporally("<", entry.Tracker.End))
+					entries = append(entries, entry.Rounded)
+				}
+			}
+			g.Expect(entries).To(Equal(tc.expected.entries))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type RoundingMode string
+
+const (
+	RoundNone    RoundingMode = "none"
+	RoundNearest RoundingMode = "nearest"
+	RoundUp      RoundingMode = "up"
+	RoundDown    RoundingMode = "down"
+)
+
+// RoundingAggregation is what rounding applies to: every entry on its own, or
+// the time tracked per day and project.
+type RoundingAggregation string
+
+const (
+	RoundPerEntry RoundingAggregation = "entry"
+	RoundPerDay   RoundingAggregation = "day"
+)
+
+// Rounding turns tracked durations into billed ones, the tracker Start and
+// End are never changed.
+type Rounding struct {
+	Mode        RoundingMode
+	Increment   time.Duration
+	Aggregation RoundingAggregation
+}
+
+// Round rounds duration to a multiple of the increment, half increments
+// round up in nearest mode.
+func (r Rounding) Round(duration time.Duration) time.Duration {
+	if r.Increment <= 0 {
+		return duration
+	}
+
+	switch r.Mode {
+	case RoundNearest:
+		return duration.Round(r.Increment)
+	case RoundUp:
+		rounded := duration.Truncate(r.Increment)
+		if rounded < duration {
+			rounded += r.Increment
+		}
+
+		return rounded
+	case RoundDown:
+		return duration.Truncate(r.Increment)
+	default:
+		return duration
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_Rounding_Round(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		rounding    Rounding
+		input       time.Duration
+		expected    time.Duration
+	}{
+		{
+			description: "when rounding is disabled",
+			rounding:    Rounding{Mode: RoundNone, Increment: 15 * time.Minute},
+			input:       7 * time.Minute,
+			expected:    7 * time.Minute,
+		},
+		{
+			description: "when rounding to the nearest increment below half",
+			rounding:    Rounding{Mode: RoundNearest, Increment: 15 * time.Minute},
+			input:       22*time.Minute + 29*time.Second,
+			expected:    15 * time.Minute,
+		},
+		{
+			description: "when rounding to the nearest increment at half",
+			rounding:    Rounding{Mode: RoundNearest, Increment: 6 * time.Minute},
+			input:       9 * time.Minute,
+			expected:    12 * time.Minute,
+		},
+		{
+			description: "when rounding up",
+			rounding:    Rounding{Mode: RoundUp, Increment: 6 * time.Minute},
+			input:       6*time.Minute + time.Second,
+			expected:    12 * time.Minute,
+		},
+		{
+			description: "when rounding up an exact multiple",
+			rounding:    Rounding{Mode: RoundUp, Increment: 6 * time.Minute},
+			input:       12 * time.Minute,
+			expected:    12 * time.Minute,
+		},
+		{
+			description: "when rounding down",
+			rounding:    Rounding{Mode: RoundDown, Increment: 15 * time.Minute},
+			input:       29 * time.Minute,
+			expected:    15 * time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(tc.rounding.Round(tc.input)).To(Equal(tc.expected))
+		})
+	}
+}
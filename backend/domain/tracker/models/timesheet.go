@@ -0,0 +1,120 @@
+package models
+
+import (
+	"pento/code-challenge/domain"
+	"time"
+)
+
+type TimesheetStatus string
+
+const (
+	TimesheetOpen      TimesheetStatus = "open"
+	TimesheetSubmitted TimesheetStatus = "submitted"
+	TimesheetApproved  TimesheetStatus = "approved"
+	TimesheetRejected  TimesheetStatus = "rejected"
+)
+
+type TimesheetAction string
+
+const (
+	TimesheetSubmit  TimesheetAction = "submit"
+	TimesheetApprove TimesheetAction = "approve"
+	TimesheetReject  TimesheetAction = "reject"
+	TimesheetReopen  TimesheetAction = "reopen"
+)
+
+// timesheetTransitions lists the statuses every action applies to and the
+// status it leads to.
+var timesheetTransitions = map[TimesheetAction]struct {
+	from []TimesheetStatus
+	to   TimesheetStatus
+}{
+	TimesheetSubmit:  {from: []TimesheetStatus{TimesheetOpen, TimesheetRejected}, to: TimesheetSubmitted},
+	TimesheetApprove: {from: []TimesheetStatus{TimesheetSubmitted}, to: TimesheetApproved},
+	TimesheetReject:  {from: []TimesheetStatus{TimesheetSubmitted}, to: TimesheetRejected},
+	TimesheetReopen:  {from: []TimesheetStatus{TimesheetSubmitted, TimesheetApproved, TimesheetRejected}, to: TimesheetOpen},
+}
+
+// TimesheetComment is left with every action, Body may be empty.
+type TimesheetComment struct {
+	Action TimesheetAction
+	Author string
+	Body   string
+	At     time.Time
+}
+
+// TimesheetDay is the time tracked on a day of the week.
+type TimesheetDay struct {
+	Date    time.Time
+	Tracked time.Duration
+}
+
+// Timesheet groups the trackers of the week [Start, End). An approved
+// timesheet locks its week until it is reopened. Trackers, Days and Total
+// are filled in when the timesheet is read, they aren't stored.
+type Timesheet struct {
+	ID       uint64
+	Start    time.Time
+	End      time.Time
+	Status   TimesheetStatus
+	Comments []TimesheetComment
+	Trackers []TimeTracker
+	Days     []TimesheetDay
+	Total    time.Duration
+	Meta     domain.Meta
+}
+
+// NewTimesheet returns the open timesheet of the week of t in location.
+func NewTimesheet(t time.Time, location *time.Location) Timesheet {
+	start := StartOfWeek(t, location)
+
+	return Timesheet{
+		Start:    start,
+		End:      start.AddDate(0, 0, 7),
+		Status:   TimesheetOpen,
+		Comments: make([]TimesheetComment, 0),
+		Trackers: make([]TimeTracker, 0),
+		Days:     make([]TimesheetDay, 0),
+		Meta:     domain.NewMeta(),
+	}
+}
+
+func (t Timesheet) IsZero() bool {
+	return t.ID == 0 && t.Start.IsZero()
+}
+
+func (t Timesheet) Locked() bool {
+	return t.Status == TimesheetApproved
+}
+
+// Next returns the status action leads to, false when it doesn't apply to
+// the current status.
+func (t Timesheet) Next(action TimesheetAction) (TimesheetStatus, bool) {
+	transition, ok := timesheetTransitions[action]
+	if !ok {
+		return "", false
+	}
+
+	for _, from := range transition.from {
+		if t.Status == from {
+			return transition.to, true
+		}
+	}
+
+	return "", false
+}
+
+// Summarize sets the trackers of the week along with the time tracked per
+// day and in total, running trackers are measured up to now.
+func (t *Timesheet) Summarize(trackers []TimeTracker, location *time.Location, now time.Time) {
+	totals := DailyTotals(trackers, t.Start, t.End, location, now)
+
+	t.Trackers = trackers
+	t.Days = make([]TimesheetDay, 0, 7)
+	t.Total = 0
+
+	for day := StartOfDay(t.Start, location); day.Before(t.End); day = day.AddDate(0, 0, 1) {
+		t.Days = append(t.Days, TimesheetDay{Date: day, Tracked: totals[day]})
+		t.Total += totals[day]
+	}
+}
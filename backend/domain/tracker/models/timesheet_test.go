@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_Timesheet_Next(t *testing.T) {
+
+	testCases := []struct {
+		description string
+		status      TimesheetStatus
+		action      TimesheetAction
+		expected    TimesheetStatus
+		allowed     bool
+	}{
+		{"when an open timesheet is submitted", TimesheetOpen, TimesheetSubmit, TimesheetSubmitted, true},
+		{"when a rejected timesheet is submitted again", TimesheetRejected, TimesheetSubmit, TimesheetSubmitted, true},
+		{"when a submitted timesheet is approved", TimesheetSubmitted, TimesheetApprove, TimesheetApproved, true},
+		{"when a submitted timesheet is rejected", TimesheetSubmitted, TimesheetReject, TimesheetRejected, true},
+		{"when an approved timesheet is reopened", TimesheetApproved, TimesheetReopen, TimesheetOpen, true},
+		{"when an open timesheet is approved", TimesheetOpen, TimesheetApprove, "", false},
+		{"when an approved timesheet is submitted", TimesheetApproved, TimesheetSubmit, "", false},
+		{"when an open timesheet is reopened", TimesheetOpen, TimesheetReopen, "", false},
+		{"when the action is unknown", TimesheetSubmitted, TimesheetAction("archive"), "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			timesheet := NewTimesheet(time.Date(2021, 5, 5, 12, 0, 0, 0, time.UTC), time.UTC)
+			timesheet.Status = tc.status
+
+			next, allowed := timesheet.Next(tc.action)
+
+			g.Expect(allowed).To(Equal(tc.allowed))
+			g.Expect(next).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_Timesheet_Summarize(t *testing.T) {
+	g := NewWithT(t)
+
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// 2021-05-05 is a Wednesday, Lisbon is UTC+1.
+	timesheet := NewTimesheet(time.Date(2021, 5, 5, 12, 0, 0, 0, lisbon), lisbon)
+	g.Expect(timesheet.Start).To(Equal(time.Date(2021, 5, 3, 0, 0, 0, 0, lisbon)))
+	g.Expect(timesheet.End).To(Equal(time.Date(2021, 5, 10, 0, 0, 0, 0, lisbon)))
+
+	// read back from a store in UTC
+	timesheet.Start = timesheet.Start.UTC()
+
+	trackers := []TimeTracker{
+		NewTimeTracker(1, time.Date(2021, 5, 2, 22, 0, 0, 0, time.UTC), time.Date(2021, 5, 3, 1, 0, 0, 0, time.UTC), "a"),
+		NewTimeTracker(2, time.Date(2021, 5, 5, 8, 0, 0, 0, time.UTC), time.Date(2021, 5, 5, 12, 0, 0, 0, time.UTC), "b"),
+		NewTimeTracker(3, time.Date(2021, 5, 9, 22, 0, 0, 0, time.UTC), time.Time{}, "c"),
+	}
+
+	timesheet.Summarize(trackers, lisbon, time.Date(2021, 5, 10, 1, 0, 0, 0, time.UTC))
+
+	g.Expect(timesheet.Days).To(HaveLen(7))
+	g.Expect(timesheet.Days[0].Tracked).To(Equal(2 * time.Hour))
+	g.Expect(timesheet.Days[2].Tracked).To(Equal(4 * time.Hour))
+	g.Expect(timesheet.Days[6].Tracked).To(Equal(time.Hour))
+	g.Expect(timesheet.Total).To(Equal(7 * time.Hour))
+}
@@ -6,11 +6,24 @@ import (
 )
 
 type TimeTracker struct {
-	ID    uint64
-	Start time.Time
-	End   time.Time
-	Name  string
-	Meta  domain.Meta
+	ID      uint64
+	Start   time.Time
+	End     time.Time
+	Name    string
+	Project string
+	Tags    []string
+	// Overlapping lists the trackers this one was stored overlapping with, it
+	// is only filled in by writes.
+	Overlapping []uint64
+	// OverlapAccepted is set on trackers stored overlapping other sessions
+	// under the warn or trim policy, the store leaves them out of its overlap
+	// constraint.
+	OverlapAccepted bool
+	// LastHeartbeat is the last activity reported while the tracker ran.
+	LastHeartbeat time.Time
+	// AutoStop is set once the tracker was stopped by the scheduler.
+	AutoStop *AutoStop
+	Meta     domain.Meta
 }
 
 func NewTimeTracker(id uint64, start, end time.Time, name string) TimeTracker {
@@ -29,3 +42,20 @@ func (t TimeTracker) IsZero() bool {
 		t.End.IsZero() &&
 		t.Name == ""
 }
+
+func (t TimeTracker) IsRunning() bool {
+	return t.End.IsZero()
+}
+
+// Duration returns the tracked time, a running tracker is measured up to now.
+func (t TimeTracker) Duration(now time.Time) time.Duration {
+	if t.IsRunning() {
+		if now.Before(t.Start) {
+			return 0
+		}
+
+		return now.Sub(t.Start)
+	}
+
+	return t.End.Sub(t.Start)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_TimeTracker_Duration(t *testing.T) {
+
+	at := func(hour int) time.Time {
+		return time.Date(2021, 5, 3, hour, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		description string
+		input       TimeTracker
+		expected    time.Duration
+	}{
+		{
+			description: "when the tracker is stopped",
+			input:       NewTimeTracker(1, at(8), at(10), "a"),
+			expected:    2 * time.Hour,
+		},
+		{
+			description: "when the tracker is running",
+			input:       NewTimeTracker(1, at(9), time.Time{}, "a"),
+			expected:    3 * time.Hour,
+		},
+		{
+			description: "when the tracker starts in the future",
+			input:       NewTimeTracker(1, at(14), time.Time{}, "a"),
+			expected:    0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(tc.input.Duration(at(12))).To(Equal(tc.expected), "should be the same duration")
+		})
+	}
+}
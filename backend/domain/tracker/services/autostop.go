@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/logging"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var ErrNotRunning = errors.New("tracker is not running")
+
+// AutoStopRules decide when a tracker left running is stopped by the
+// scheduler, zero values disable a rule.
+type AutoStopRules struct {
+	MaxDuration time.Duration
+	// EndOfDay is the time of day, as an offset from midnight in Location,
+	// trackers are stopped at. 24h stands for midnight.
+	EndOfDay time.Duration
+	Location *time.Location
+}
+
+func (r AutoStopRules) Enabled() bool {
+	return r.MaxDuration > 0 || r.EndOfDay > 0
+}
+
+// threshold returns when the tracker is due to be stopped and which rule
+// decided it, a zero time when no rule applies.
+func (r AutoStopRules) threshold(tracker models.TimeTracker) (time.Time, models.AutoStopReason) {
+	var (
+		threshold time.Time
+		reason    models.AutoStopReason
+	)
+
+	if r.MaxDuration > 0 {
+		threshold = tracker.Start.Add(r.MaxDuration)
+		reason = models.AutoStopMaxDuration
+	}
+
+	if r.EndOfDay > 0 {
+		location := r.Location
+		if location == nil {
+			location = time.UTC
+		}
+
+		start := tracker.Start.In(location)
+		endOfDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location).Add(r.EndOfDay)
+		if !endOfDay.After(start) {
+			endOfDay = time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, location).Add(r.EndOfDay)
+		}
+
+		if threshold.IsZero() || endOfDay.Before(threshold) {
+			threshold = endOfDay
+			reason = models.AutoStopEndOfDay
+		}
+	}
+
+	return threshold, reason
+}
+
+// AutoStopTrackers stops the running trackers past their auto-stop threshold,
+// at the threshold or at the last heartbeat received before it. Each tracker
+// is stopped in its own transaction, failures are logged and retried on the
+// next run.
+func (s TrackerService) AutoStopTrackers(ctx context.Context) ([]models.TimeTracker, error) {
+	stopped := make([]models.TimeTracker, 0)
+
+	if !s.autoStop.Enabled() {
+		return stopped, nil
+	}
+
+	running, err := s.store.ListRunning(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to list running trackers", err)
+	}
+
+	now := s.now()
+
+	for _, tracker := range running {
+		threshold, reason := s.autoStop.threshold(tracker)
+		if threshold.IsZero() || now.Before(threshold) {
+			continue
+		}
+
+		end := threshold
+		if tracker.LastHeartbeat.After(tracker.Start) && tracker.LastHeartbeat.Before(threshold) {
+			end = tracker.LastHeartbeat
+		}
+
+		tracker.AutoStop = &models.AutoStop{Reason: reason, At: now}
+
+		var event models.TrackerEvent
+		err := s.store.WithinTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			event, err = s.endSession(ctx, tracker, end, 0)
+
+			return err
+		})
+		if err != nil {
+			logging.FromContext(ctx, s.logger).WithError(err).WithField("tracker_id", tracker.ID).
+				Warn("failed to auto-stop tracker")
+
+			continue
+		}
+
+		logging.FromContext(ctx, s.logger).WithFields(logrus.Fields{
+			"tracker_id": tracker.ID,
+			"reason":     reason,
+			"end":        end,
+		}).Info("auto-stopped tracker")
+
+		s.publish(ctx, event.Type, event.Tracker)
+		s.checkBudgets(ctx, []models.TrackerEvent{event})
+		stopped = append(stopped, event.Tracker)
+	}
+
+	return stopped, nil
+}
+
+// Heartbeat records activity on a running tracker, the auto-stop scheduler
+// stops a forgotten tracker at its last heartbeat.
+func (s TrackerService) Heartbeat(ctx context.Context, id uint64) error {
+	tracker, err := s.GetTracker(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !tracker.IsRunning() {
+		return ErrNotRunning
+	}
+
+	found, err := s.store.Heartbeat(ctx, id, s.now())
+	if err != nil {
+		return fmt.Errorf("%w failed to record heartbeat", err)
+	}
+
+	// stopped since it was read
+	if !found {
+		return ErrNotRunning
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"pento/code-challenge/domain/tracker/models"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_AutoStopRules_threshold(t *testing.T) {
+
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testExpectation struct {
+		threshold time.Time
+		reason    models.AutoStopReason
+	}
+
+	testCases := []struct {
+		description string
+		rules       AutoStopRules
+		start       time.Time
+		expected    testExpectation
+	}{
+		{
+			description: "when no rule is set",
+			rules:       AutoStopRules{},
+			start:       time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC),
+			expected:    testExpectation{},
+		},
+		{
+			description: "when the tracker reaches the maximum duration first",
+			rules:       AutoStopRules{MaxDuration: 4 * time.Hour, EndOfDay: 19 * time.Hour, Location: time.UTC},
+			start:       time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC),
+			expected: testExpectation{
+				threshold: time.Date(2021, 5, 3, 13, 0, 0, 0, time.UTC),
+				reason:    models.AutoStopMaxDuration,
+			},
+		},
+		{
+			description: "when the tracker reaches the end of day first",
+			rules:       AutoStopRules{MaxDuration: 12 * time.Hour, EndOfDay: 19 * time.Hour, Location: time.UTC},
+			start:       time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC),
+			expected: testExpectation{
+				threshold: time.Date(2021, 5, 3, 19, 0, 0, 0, time.UTC),
+				reason:    models.AutoStopEndOfDay,
+			},
+		},
+		{
+			description: "when the tracker starts after the end of day",
+			rules:       AutoStopRules{EndOfDay: 19 * time.Hour, Location: time.UTC},
+			start:       time.Date(2021, 5, 3, 20, 0, 0, 0, time.UTC),
+			expected: testExpectation{
+				threshold: time.Date(2021, 5, 4, 19, 0, 0, 0, time.UTC),
+				reason:    models.AutoStopEndOfDay,
+			},
+		},
+		{
+			description: "when the end of day is in another time zone",
+			rules:       AutoStopRules{EndOfDay: 24 * time.Hour, Location: lisbon},
+			start:       time.Date(2021, 5, 3, 22, 30, 0, 0, time.UTC),
+			expected: testExpectation{
+				threshold: time.Date(2021, 5, 3, 23, 0, 0, 0, time.UTC),
+				reason:    models.AutoStopEndOfDay,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			threshold, reason := tc.rules.threshold(models.NewTimeTracker(1, tc.start, time.Time{}, "code review"))
+
+			g.Expect(threshold.Equal(tc.expected.threshold)).To(BeTrue(), "threshold %s", threshold)
+			g.Expect(reason).To(Equal(tc.expected.reason))
+		})
+	}
+}
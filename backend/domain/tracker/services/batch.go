@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"time"
+)
+
+const MaxBatchOperations = 100
+
+var (
+	ErrEmptyBatch       = errors.New("batch has no operations")
+	ErrBatchTooLarge    = errors.New("batch has too many operations")
+	ErrUnknownOperation = errors.New("unknown batch operation")
+	ErrBatchAborted     = errors.New("batch aborted")
+)
+
+type BatchOperationType string
+
+const (
+	BatchCreate BatchOperationType = "create"
+	BatchUpdate BatchOperationType = "update"
+	BatchDelete BatchOperationType = "delete"
+)
+
+type BatchOperation struct {
+	Type    BatchOperationType
+	ID      uint64
+	Start   time.Time
+	End     time.Time
+	Name    string
+	Project string
+	// Tags replace the current tags of an updated tracker unless nil.
+	Tags    []string
+	Version uint32
+}
+
+type BatchParams struct {
+	Operations []BatchOperation
+	// Atomic rolls back every operation when one of them fails, otherwise
+	// each operation is applied on its own savepoint and failures are reported
+	// per item.
+	Atomic bool
+}
+
+type BatchResult struct {
+	Tracker models.TimeTracker
+	Applied bool
+	Err     error
+}
+
+// Batch executes the operations in a single transaction. Events are only
+// published once the transaction has been committed.
+func (s TrackerService) Batch(ctx context.Context, params BatchParams) ([]BatchResult, error) {
+	if len(params.Operations) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	if len(params.Operations) > MaxBatchOperations {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]BatchResult, len(params.Operations))
+	events := make([]models.TrackerEvent, 0, len(params.Operations))
+
+	err := s.store.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, operation := range params.Operations {
+			var (
+				tracker         models.TimeTracker
+				operationEvents []models.TrackerEvent
+			)
+
+			apply := func(ctx context.Context) error {
+				var err error
+				tracker, operationEvents, err = s.applyOperation(ctx, operation)
+
+				return err
+			}
+
+			var err error
+			if params.Atomic {
+				err = apply(ctx)
+			} else {
+				err = s.store.WithinTransaction(ctx, apply)
+			}
+
+			if err != nil {
+				results[i] = BatchResult{Err: err}
+				if params.Atomic {
+					return err
+				}
+
+				continue
+			}
+
+			results[i] = BatchResult{Tracker: tracker, Applied: true}
+			events = append(events, operationEvents...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		for i := range results {
+			results[i].Applied = false
+		}
+
+		return results, fmt.Errorf("%w: %v", ErrBatchAborted, err)
+	}
+
+	s.publishAll(ctx, events)
+	s.checkBudgets(ctx, events)
+
+	return results, nil
+}
+
+func (s TrackerService) applyOperation(ctx context.Context, operation BatchOperation) (models.TimeTracker, []models.TrackerEvent, error) {
+	switch operation.Type {
+	case BatchCreate:
+		return s.createTracker(ctx, CreateTrackerParams{
+			Start:   operation.Start,
+			Name:    operation.Name,
+			Project: operation.Project,
+			Tags:    operation.Tags,
+		})
+	case BatchUpdate:
+		return s.updateTracker(ctx, UpdateTrackerParams{
+			ID:      operation.ID,
+			Start:   operation.Start,
+			End:     operation.End,
+			Name:    operation.Name,
+			Project: operation.Project,
+			Tags:    operation.Tags,
+			Version: operation.Version,
+		})
+	case BatchDelete:
+		// unlike a single delete, a batch reports a missing tracker instead of
+		// applying it as a no-op
+		if _, err := s.GetTracker(ctx, operation.ID); err != nil {
+			return models.TimeTracker{}, nil, err
+		}
+
+		err := s.deleteTracker(ctx, DeleteTrackerParams{
+			ID:      operation.ID,
+			Version: operation.Version,
+		})
+		if err != nil {
+			return models.TimeTracker{}, nil, err
+		}
+
+		tracker := models.NewTimeTracker(operation.ID, time.Time{}, time.Time{}, "")
+
+		return tracker, []models.TrackerEvent{models.NewTrackerEvent(models.EventDeleted, tracker)}, nil
+	default:
+		return models.TimeTracker{}, nil, ErrUnknownOperation
+	}
+}
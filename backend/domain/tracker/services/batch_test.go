@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pento/code-challenge/domain/tracker/models"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_TrackerService_Batch(t *testing.T) {
+
+	at := func(hour int) time.Time {
+		return time.Date(2021, 5, 10, hour, 0, 0, 0, time.UTC)
+	}
+
+	existing := models.NewTimeTracker(1, at(9), at(10), "a")
+
+	testCases := []struct {
+		description string
+		operation   BatchOperation
+		expected    error
+		assert      func(g *WithT, tracker models.TimeTracker)
+	}{
+		{
+			description: "when a tracker is created with a project and tags",
+			operation: BatchOperation{
+				Type:    BatchCreate,
+				Start:   at(11),
+				Name:    "b",
+				Project: "pento",
+				Tags:    []string{"review"},
+			},
+			assert: func(g *WithT, tracker models.TimeTracker) {
+				g.Expect(tracker.Project).To(Equal("pento"), "should store the project")
+				g.Expect(tracker.Tags).To(Equal([]string{"review"}), "should store the tags")
+			},
+		},
+		{
+			description: "when a tracker is updated with a project and tags",
+			operation: BatchOperation{
+				Type:    BatchUpdate,
+				ID:      1,
+				Name:    "a",
+				Project: "pento",
+				Tags:    []string{"review"},
+				Version: 1,
+			},
+			assert: func(g *WithT, tracker models.TimeTracker) {
+				g.Expect(tracker.Project).To(Equal("pento"), "should update the project")
+				g.Expect(tracker.Tags).To(Equal([]string{"review"}), "should update the tags")
+			},
+		},
+		{
+			description: "when a missing tracker is deleted",
+			operation:   BatchOperation{Type: BatchDelete, ID: 2},
+			expected:    ErrTrackerNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			store := newMemoryStore(existing)
+			service := newTestTrackerService(store, OverlapReject, at(12))
+
+			results, err := service.Batch(context.Background(), BatchParams{
+				Operations: []BatchOperation{tc.operation},
+				Atomic:     false,
+			})
+
+			g.Expect(err).ToNot(HaveOccurred(), "should run the batch")
+			g.Expect(results).To(HaveLen(1), "should report the operation")
+
+			if tc.expected != nil {
+				g.Expect(errors.Is(results[0].Err, tc.expected)).To(BeTrue(), "should report the failure")
+				g.Expect(results[0].Applied).To(BeFalse(), "should not report the operation as applied")
+				g.Expect(store.trackers).To(HaveLen(1), "should leave the store untouched")
+
+				return
+			}
+
+			g.Expect(results[0].Err).ToNot(HaveOccurred(), "should apply the operation")
+			tc.assert(g, store.trackers[results[0].Tracker.ID])
+		})
+	}
+}
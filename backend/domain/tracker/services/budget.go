@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/logging"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrBudgetNotFound = errors.New("budget not found")
+	ErrBudgetExists   = errors.New("project already has a budget")
+)
+
+const (
+	maxThreshold = 1000
+	// checkTimeout bounds a budget check, notifications included. The check
+	// outlives the request that triggered it so an alert isn't lost when the
+	// client goes away.
+	checkTimeout = time.Minute
+)
+
+type BudgetStore interface {
+	Get(ctx context.Context, id uint64) (models.Budget, error)
+	// GetByProject returns the budget of project, a zero budget when it has
+	// none.
+	GetByProject(ctx context.Context, project string) (models.Budget, error)
+	List(ctx context.Context) ([]models.Budget, error)
+	Store(ctx context.Context, budget models.Budget, version uint32) (models.Budget, error)
+	Delete(ctx context.Context, id uint64) error
+	// AlertRecorded reports whether the alert was recorded for its budget,
+	// period, kind and threshold.
+	AlertRecorded(ctx context.Context, alert models.BudgetAlert) (bool, error)
+	// RecordAlert reports whether the alert wasn't recorded yet for its
+	// budget, period, kind and threshold.
+	RecordAlert(ctx context.Context, alert models.BudgetAlert) (bool, error)
+}
+
+// Notifier delivers budget alerts.
+type Notifier interface {
+	Notify(ctx context.Context, alert models.BudgetAlert) error
+}
+
+// BudgetService manages project budgets, their burn-down and the alerts
+// raised when the tracked time reaches their thresholds.
+type BudgetService struct {
+	store      BudgetStore
+	trackers   TrackerStore
+	notifier   Notifier
+	rules      ValidationRules
+	thresholds []int
+	location   *time.Location
+	now        func() time.Time
+	logger     logrus.FieldLogger
+}
+
+// BudgetParams describes a budget, thresholds default to the configured
+// ones when empty.
+type BudgetParams struct {
+	Project    string
+	Period     models.BudgetPeriod
+	Hours      time.Duration
+	Amount     int64
+	HourlyRate int64
+	Currency   string
+	Thresholds []int
+}
+
+type UpdateBudgetParams struct {
+	BudgetParams
+	ID      uint64
+	Version uint32
+}
+
+type DeleteBudgetParams struct {
+	ID      uint64
+	Version uint32
+}
+
+// NewBudgetService returns a budget service, monthly periods start at
+// midnight in location.
+func NewBudgetService(store BudgetStore, trackers TrackerStore, notifier Notifier, rules ValidationRules, thresholds []int, location *time.Location, logger logrus.FieldLogger) BudgetService {
+	return BudgetService{
+		store:      store,
+		trackers:   trackers,
+		notifier:   notifier,
+		rules:      rules,
+		thresholds: thresholds,
+		location:   location,
+		now:        time.Now,
+		logger:     logger,
+	}
+}
+
+func (s BudgetService) GetBudget(ctx context.Context, id uint64) (models.Budget, error) {
+	budget, err := s.store.Get(ctx, id)
+	if err != nil {
+		return models.Budget{}, fmt.Errorf("%w failed to get budget", err)
+	}
+
+	if budget.IsZero() {
+		return models.Budget{}, ErrBudgetNotFound
+	}
+
+	return budget, nil
+}
+
+func (s BudgetService) ListBudgets(ctx context.Context) ([]models.Budget, error) {
+	budgets, err := s.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to list budgets", err)
+	}
+
+	return budgets, nil
+}
+
+func (s BudgetService) CreateBudget(ctx context.Context, params BudgetParams) (models.Budget, error) {
+	budget := s.fromParams(models.NewBudget(0, params.Project, params.Period), params)
+
+	if err := s.validate(budget); err != nil {
+		return models.Budget{}, err
+	}
+
+	stored, err := s.store.Store(ctx, budget, 0)
+	if err != nil {
+		return models.Budget{}, fmt.Errorf("%w failed to store budget", err)
+	}
+
+	return stored, nil
+}
+
+// UpdateBudget replaces the budget, every field is written.
+func (s BudgetService) UpdateBudget(ctx context.Context, params UpdateBudgetParams) (models.Budget, error) {
+	budget, err := s.GetBudget(ctx, params.ID)
+	if err != nil {
+		return models.Budget{}, err
+	}
+
+	if budget.Meta.GetVersion() != params.Version {
+		return models.Budget{}, ErrWrongVersion
+	}
+
+	budget = s.fromParams(budget, params.BudgetParams)
+
+	if err := s.validate(budget); err != nil {
+		return models.Budget{}, err
+	}
+
+	stored, err := s.store.Store(ctx, budget, params.Version)
+	if err != nil {
+		return models.Budget{}, fmt.Errorf("%w failed to store budget", err)
+	}
+
+	return stored, nil
+}
+
+func (s BudgetService) DeleteBudget(ctx context.Context, params DeleteBudgetParams) error {
+	budget, err := s.GetBudget(ctx, params.ID)
+	if err != nil {
+		return err
+	}
+
+	if params.Version != 0 && budget.Meta.GetVersion() != params.Version {
+		return ErrWrongVersion
+	}
+
+	if err := s.store.Delete(ctx, params.ID); err != nil {
+		return fmt.Errorf("%w failed to delete budget", err)
+	}
+
+	return nil
+}
+
+// BurnDown compares the budget with the time tracked in its period
+// containing at, the current one when at is zero.
+func (s BudgetService) BurnDown(ctx context.Context, id uint64, at time.Time) (models.BurnDown, error) {
+	budget, err := s.GetBudget(ctx, id)
+	if err != nil {
+		return models.BurnDown{}, err
+	}
+
+	return s.burnDown(ctx, budget, at)
+}
+
+// CheckBudgets notifies the thresholds newly reached by the budgets of the
+// projects, failures are logged since the tracker change causing the check is
+// already stored.
+func (s BudgetService) CheckBudgets(ctx context.Context, projects []string) {
+	logger := logging.FromContext(ctx, s.logger)
+	checked := make(map[string]bool)
+
+	for _, project := range projects {
+		if project == "" || checked[project] {
+			continue
+		}
+		checked[project] = true
+
+		if err := s.checkBudget(logger.WithField("project", project), project); err != nil {
+			logger.WithError(err).WithField("project", project).Error("failed to check budget")
+		}
+	}
+}
+
+// checkBudget records an alert once it is delivered, a failed notification is
+// retried by the next check.
+func (s BudgetService) checkBudget(logger logrus.FieldLogger, project string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	budget, err := s.store.GetByProject(ctx, project)
+	if err != nil {
+		return fmt.Errorf("%w failed to get budget", err)
+	}
+
+	if budget.IsZero() {
+		return nil
+	}
+
+	burnDown, err := s.burnDown(ctx, budget, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	for _, alert := range burnDown.Reached() {
+		recorded, err := s.store.AlertRecorded(ctx, alert)
+		if err != nil {
+			return fmt.Errorf("%w failed to look up budget alert", err)
+		}
+
+		if recorded {
+			continue
+		}
+
+		entry := logger.WithFields(logrus.Fields{
+			"kind":      alert.Kind,
+			"threshold": alert.Threshold,
+			"used":      alert.Used,
+		})
+		entry.Info("budget threshold reached")
+
+		if err := s.notifier.Notify(ctx, alert); err != nil {
+			return fmt.Errorf("%w failed to notify budget alert", err)
+		}
+
+		recorded, err = s.store.RecordAlert(ctx, alert)
+		if err != nil {
+			return fmt.Errorf("%w failed to record budget alert", err)
+		}
+
+		if !recorded {
+			entry.Warn("budget alert delivered by a concurrent check as well")
+		}
+	}
+
+	return nil
+}
+
+func (s BudgetService) burnDown(ctx context.Context, budget models.Budget, at time.Time) (models.BurnDown, error) {
+	now := s.now()
+	if at.IsZero() {
+		at = now
+	}
+
+	start, end := budget.PeriodAt(at, s.location)
+
+	trackers, err := s.trackers.ProjectTrackers(ctx, budget.Project, start, end)
+	if err != nil {
+		return models.BurnDown{}, fmt.Errorf("%w failed to list project trackers", err)
+	}
+
+	return models.BuildBurnDown(budget, trackers, start, end, s.location, now), nil
+}
+
+func (s BudgetService) fromParams(budget models.Budget, params BudgetParams) models.Budget {
+	budget.Project = params.Project
+	budget.Period = params.Period
+	budget.Hours = params.Hours
+	budget.Amount = params.Amount
+	budget.HourlyRate = params.HourlyRate
+	budget.Currency = strings.ToUpper(params.Currency)
+	budget.Thresholds = params.Thresholds
+
+	if len(budget.Thresholds) == 0 {
+		budget.Thresholds = s.thresholds
+	}
+
+	return budget
+}
+
+func (s BudgetService) validate(budget models.Budget) error {
+	problems := &ValidationError{}
+
+	if strings.TrimSpace(budget.Project) == "" {
+		problems.add("project", "required", "must not be empty")
+	} else if utf8.RuneCountInString(budget.Project) > s.rules.MaxNameLength {
+		problems.add("project", "too_long", fmt.Sprintf("must be at most %d characters", s.rules.MaxNameLength))
+	}
+
+	switch budget.Period {
+	case models.BudgetTotal, models.BudgetMonthly:
+	default:
+		problems.add("period", "invalid", "must be total or monthly")
+	}
+
+	if budget.Hours < 0 || budget.Amount < 0 || budget.HourlyRate < 0 {
+		problems.add("budget", "negative", "hours, amount and hourly rate must not be negative")
+	} else if budget.Hours == 0 && budget.Amount == 0 {
+		problems.add("budget", "required", "an hour or money budget must be set")
+	}
+
+	if budget.Amount > 0 {
+		if budget.HourlyRate == 0 {
+			problems.add("hourly_rate", "required", "must be set with a money budget")
+		}
+		if len(budget.Currency) != 3 {
+			problems.add("currency", "invalid", "must be an ISO 4217 code")
+		}
+	}
+
+	for _, threshold := range budget.Thresholds {
+		if threshold <= 0 || threshold > maxThreshold {
+			problems.add("thresholds", "invalid", fmt.Sprintf("must be percentages between 1 and %d", maxThreshold))
+
+			break
+		}
+	}
+
+	if len(problems.Fields) > 0 {
+		return problems
+	}
+
+	return nil
+}
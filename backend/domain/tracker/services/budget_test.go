@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// memoryBudgets holds a single budget and the recorded alerts.
+type memoryBudgets struct {
+	budget models.Budget
+	alerts map[string]bool
+}
+
+func alertKey(alert models.BudgetAlert) string {
+	return fmt.Sprintf("%s/%d", alert.Kind, alert.Threshold)
+}
+
+func (m *memoryBudgets) Get(ctx context.Context, id uint64) (models.Budget, error) {
+	return m.budget, nil
+}
+
+func (m *memoryBudgets) GetByProject(ctx context.Context, project string) (models.Budget, error) {
+	if project != m.budget.Project {
+		return models.Budget{}, nil
+	}
+
+	return m.budget, nil
+}
+
+func (m *memoryBudgets) List(ctx context.Context) ([]models.Budget, error) {
+	return []models.Budget{m.budget}, nil
+}
+
+func (m *memoryBudgets) Store(ctx context.Context, budget models.Budget, version uint32) (models.Budget, error) {
+	m.budget = budget
+
+	return budget, nil
+}
+
+func (m *memoryBudgets) Delete(ctx context.Context, id uint64) error {
+	return nil
+}
+
+func (m *memoryBudgets) AlertRecorded(ctx context.Context, alert models.BudgetAlert) (bool, error) {
+	return m.alerts[alertKey(alert)], nil
+}
+
+func (m *memoryBudgets) RecordAlert(ctx context.Context, alert models.BudgetAlert) (bool, error) {
+	if m.alerts[alertKey(alert)] {
+		return false, nil
+	}
+	m.alerts[alertKey(alert)] = true
+
+	return true, nil
+}
+
+// recordingNotifier records the delivered alerts, or fails them all.
+type recordingNotifier struct {
+	err    error
+	alerts []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert models.BudgetAlert) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.alerts = append(n.alerts, alertKey(alert))
+
+	return nil
+}
+
+func Test_BudgetService_CheckBudgets(t *testing.T) {
+
+	at := func(hour int) time.Time {
+		return time.Date(2021, 5, 3, hour, 0, 0, 0, time.UTC)
+	}
+
+	type testExpectation struct {
+		notified []string
+		recorded map[string]bool
+	}
+
+	testCases := []struct {
+		description string
+		recorded    map[string]bool
+		notifyErr   error
+		expected    testExpectation
+	}{
+		{
+			description: "when thresholds are reached",
+			recorded:    map[string]bool{},
+			expected: testExpectation{
+				notified: []string{"hours/50", "hours/100"},
+				recorded: map[string]bool{"hours/50": true, "hours/100": true},
+			},
+		},
+		{
+			description: "when a threshold was already notified",
+			recorded:    map[string]bool{"hours/50": true},
+			expected: testExpectation{
+				notified: []string{"hours/100"},
+				recorded: map[string]bool{"hours/50": true, "hours/100": true},
+			},
+		},
+		{
+			description: "when the notification fails",
+			recorded:    map[string]bool{},
+			notifyErr:   errors.New("webhook unavailable"),
+			expected: testExpectation{
+				recorded: map[string]bool{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			budget := models.NewBudget(1, "acme", models.BudgetTotal)
+			budget.Hours = 2 * time.Hour
+			budget.Thresholds = []int{50, 100}
+
+			tracker := models.NewTimeTracker(1, at(8), at(10), "task")
+			tracker.Project = "acme"
+
+			budgets := &memoryBudgets{budget: budget, alerts: tc.recorded}
+			notifier := &recordingNotifier{err: tc.notifyErr}
+
+			service := NewBudgetService(budgets, newMemoryStore(tracker), notifier, DefaultValidationRules, nil, time.UTC, logrus.New())
+			service.now = func() time.Time { return at(12) }
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			service.CheckBudgets(ctx, []string{"acme", "globex"})
+
+			g.Expect(notifier.alerts).To(ConsistOf(tc.expected.notified), "should notify the new alerts")
+			g.Expect(budgets.alerts).To(Equal(tc.expected.recorded), "should record the delivered alerts")
+		})
+	}
+}
+
+// projectWatcher passes on the projects it is asked to check, along with
+// whether the context of the check was already done.
+type projectWatcher chan watchedCheck
+
+type watchedCheck struct {
+	projects []string
+	err      error
+}
+
+func (w projectWatcher) CheckBudgets(ctx context.Context, projects []string) {
+	w <- watchedCheck{projects: projects, err: ctx.Err()}
+}
+
+func Test_TrackerService_CreateTracker_checksBudgets(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2021, 5, 3, 12, 0, 0, 0, time.UTC)
+
+	watcher := make(projectWatcher, 1)
+	service := newTestTrackerService(newMemoryStore(), OverlapReject, now).WithBudgets(watcher)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := service.CreateTracker(ctx, CreateTrackerParams{Start: now.Add(-3 * time.Hour), Name: "task", Project: "acme"})
+	cancel()
+
+	g.Expect(err).ToNot(HaveOccurred(), "should store the tracker")
+
+	var check watchedCheck
+	g.Eventually(watcher).Should(Receive(&check), "should check the budget in the background")
+	g.Expect(check.projects).To(Equal([]string{"acme"}), "should check the budget of the project")
+	g.Expect(check.err).ToNot(HaveOccurred(), "should not check within the request context")
+}
+
+func Test_BudgetService_CreateBudget_rules(t *testing.T) {
+	g := NewWithT(t)
+
+	rules := DefaultValidationRules
+	rules.MaxNameLength = 4
+
+	service := NewBudgetService(&memoryBudgets{}, newMemoryStore(), &recordingNotifier{}, rules, nil, time.UTC, logrus.New())
+
+	_, err := service.CreateBudget(context.Background(), BudgetParams{
+		Project: "globex",
+		Period:  models.BudgetTotal,
+		Hours:   time.Hour,
+	})
+
+	var problems *ValidationError
+	g.Expect(errors.As(err, &problems)).To(BeTrue(), "should reject the budget")
+	g.Expect(problems.Fields).To(HaveLen(1), "should report a single problem")
+	g.Expect(problems.Fields[0].Code).To(Equal("too_long"), "should apply the configured name length")
+}
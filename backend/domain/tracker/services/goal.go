@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"time"
+)
+
+// streakWindow is how far back streaks are counted.
+const streakWindow = 365
+
+type GoalStore interface {
+	// Get returns the goals, zero targets when none were set.
+	Get(ctx context.Context) (models.Goals, error)
+	Store(ctx context.Context, goals models.Goals, version uint32) (models.Goals, error)
+}
+
+// GoalService keeps the daily and weekly hour goals and the progress made
+// towards them.
+type GoalService struct {
+	store    GoalStore
+	trackers TrackerStore
+	now      func() time.Time
+}
+
+// UpdateGoalsParams replaces the goals, Version is zero until they are first
+// stored.
+type UpdateGoalsParams struct {
+	Daily   [7]time.Duration
+	Weekly  time.Duration
+	Version uint32
+}
+
+func NewGoalService(store GoalStore, trackers TrackerStore) GoalService {
+	return GoalService{
+		store:    store,
+		trackers: trackers,
+		now:      time.Now,
+	}
+}
+
+func (s GoalService) GetGoals(ctx context.Context) (models.Goals, error) {
+	goals, err := s.store.Get(ctx)
+	if err != nil {
+		return models.Goals{}, fmt.Errorf("%w failed to get goals", err)
+	}
+
+	return goals, nil
+}
+
+func (s GoalService) UpdateGoals(ctx context.Context, params UpdateGoalsParams) (models.Goals, error) {
+	goals, err := s.GetGoals(ctx)
+	if err != nil {
+		return models.Goals{}, err
+	}
+
+	if goals.Meta.GetVersion() != params.Version {
+		return models.Goals{}, ErrWrongVersion
+	}
+
+	goals.Daily = params.Daily
+	goals.Weekly = params.Weekly
+
+	if err := validateGoals(goals); err != nil {
+		return models.Goals{}, err
+	}
+
+	stored, err := s.store.Store(ctx, goals, params.Version)
+	if err != nil {
+		return models.Goals{}, fmt.Errorf("%w failed to store goals", err)
+	}
+
+	return stored, nil
+}
+
+// Progress reports today's and this week's progress, the running tracker
+// included, and the streak of days meeting their goal, days start at
+// midnight in location.
+func (s GoalService) Progress(ctx context.Context, location *time.Location) (models.GoalProgress, error) {
+	goals, err := s.GetGoals(ctx)
+	if err != nil {
+		return models.GoalProgress{}, err
+	}
+
+	now := s.now()
+	streakStart := models.StartOfDay(now, location).AddDate(0, 0, -streakWindow)
+
+	from := streakStart
+	if weekStart := models.StartOfWeek(now, location); weekStart.Before(from) {
+		from = weekStart
+	}
+
+	trackers, err := s.trackers.Overlapping(ctx, from, time.Time{}, 0)
+	if err != nil {
+		return models.GoalProgress{}, fmt.Errorf("%w failed to list trackers", err)
+	}
+
+	return models.BuildGoalProgress(goals, trackers, streakStart, location, now), nil
+}
+
+func validateGoals(goals models.Goals) error {
+	problems := &ValidationError{}
+
+	for weekday, target := range goals.Daily {
+		if target < 0 || target > 24*time.Hour {
+			problems.add("daily."+weekdayName(time.Weekday(weekday)), "out_of_range", "must be between 0 and 24 hours")
+		}
+	}
+
+	if goals.Weekly < 0 || goals.Weekly > 7*24*time.Hour {
+		problems.add("weekly", "out_of_range", "must be between 0 and 168 hours")
+	}
+
+	if len(problems.Fields) > 0 {
+		return problems
+	}
+
+	return nil
+}
+
+func weekdayName(weekday time.Weekday) string {
+	return map[time.Weekday]string{
+		time.Sunday:    "sunday",
+		time.Monday:    "monday",
+		time.Tuesday:   "tuesday",
+		time.Wednesday: "wednesday",
+		time.Thursday:  "thursday",
+		time.Friday:    "friday",
+		time.Saturday:  "saturday",
+	}[weekday]
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/logging"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var ErrOverlap = errors.New("tracker overlaps another session")
+
+// OverlapPolicy decides what happens when a write makes a tracker overlap
+// other sessions.
+type OverlapPolicy string
+
+const (
+	// OverlapReject refuses the write.
+	OverlapReject OverlapPolicy = "reject"
+	// OverlapWarn stores the tracker and reports the sessions it overlaps.
+	OverlapWarn OverlapPolicy = "warn"
+	// OverlapTrim ends the sessions started before the tracker at its start,
+	// sessions starting later are still rejected.
+	OverlapTrim OverlapPolicy = "trim"
+)
+
+// OverlapError lists the sessions a rejected tracker overlaps, it matches
+// ErrOverlap with errors.Is.
+type OverlapError struct {
+	IDs []uint64
+}
+
+func (e *OverlapError) Error() string {
+	ids := make([]string, 0, len(e.IDs))
+	for _, id := range e.IDs {
+		ids = append(ids, strconv.FormatUint(id, 10))
+	}
+
+	return fmt.Sprintf("%s: overlaps trackers %s", ErrOverlap, strings.Join(ids, ", "))
+}
+
+func (e *OverlapError) Is(target error) bool {
+	return target == ErrOverlap
+}
+
+// ListOverlaps returns the overlapping sessions whose shared period falls in
+// the window, every overlap is returned when no window is given.
+func (s TrackerService) ListOverlaps(ctx context.Context, params ListTimeTracker) ([]models.Overlap, error) {
+	trackers, err := s.store.Overlapping(ctx, params.Start, params.End, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to list overlapping trackers", err)
+	}
+
+	overlaps := make([]models.Overlap, 0)
+	for _, overlap := range models.FindOverlaps(trackers) {
+		if !params.End.IsZero() && !overlap.Start.Before(params.End) {
+			continue
+		}
+		if !params.Start.IsZero() && !overlap.End.IsZero() && !overlap.End.After(params.Start) {
+			continue
+		}
+
+		overlaps = append(overlaps, overlap)
+	}
+
+	return overlaps, nil
+}
+
+// resolveOverlaps applies the overlap policy to a tracker about to be stored.
+// previous is the stored version of an updated tracker, sessions it already
+// overlapped are accepted as they are. Under the reject policy that grace is
+// limited to sessions stored overlapping others, and running trackers are
+// measured up to now: a running tracker only overlaps the sessions it
+// covered so far, the check runs again once it is stopped. It returns the
+// events of the sessions it trimmed.
+func (s TrackerService) resolveOverlaps(ctx context.Context, tracker, previous models.TimeTracker) (models.TimeTracker, []models.TrackerEvent, error) {
+	conflicts, err := s.store.Overlapping(ctx, tracker.Start, tracker.End, tracker.ID)
+	if err != nil {
+		return models.TimeTracker{}, nil, fmt.Errorf("%w failed to look up overlapping trackers", err)
+	}
+
+	var (
+		overlapping = make([]uint64, 0)
+		rejected    = make([]uint64, 0)
+		events      = make([]models.TrackerEvent, 0)
+		now         = s.now()
+	)
+
+	for _, conflict := range conflicts {
+		if s.overlaps == OverlapReject && !tracker.OverlapsAt(conflict, now) {
+			continue
+		}
+
+		switch {
+		case s.acceptsOverlap(previous, conflict):
+			overlapping = append(overlapping, conflict.ID)
+		case s.overlaps == OverlapWarn:
+			overlapping = append(overlapping, conflict.ID)
+		case s.overlaps == OverlapTrim && conflict.Start.Before(tracker.Start):
+			event, err := s.endSession(ctx, conflict, tracker.Start, tracker.ID)
+			if err != nil {
+				return models.TimeTracker{}, nil, err
+			}
+
+			events = append(events, event)
+		default:
+			rejected = append(rejected, conflict.ID)
+		}
+	}
+
+	if len(rejected) > 0 {
+		return models.TimeTracker{}, nil, &OverlapError{IDs: rejected}
+	}
+
+	if s.overlaps == OverlapWarn && len(overlapping) > 0 {
+		logging.FromContext(ctx, s.logger).WithFields(logrus.Fields{
+			"tracker_id":  tracker.ID,
+			"overlapping": overlapping,
+		}).Warn("storing overlapping tracker")
+	}
+
+	tracker.Overlapping = overlapping
+	tracker.OverlapAccepted = s.overlaps != OverlapReject && len(overlapping) > 0
+
+	return tracker, events, nil
+}
+
+// acceptsOverlap reports whether the overlap of an updated tracker with other
+// is kept because previous, its stored version, already overlapped it. Under
+// the reject policy only overlaps with sessions stored overlapping others are
+// kept, they are the ones the store doesn't guard.
+func (s TrackerService) acceptsOverlap(previous, other models.TimeTracker) bool {
+	if previous.IsZero() || !previous.Overlaps(other) {
+		return false
+	}
+
+	return s.overlaps != OverlapReject || other.OverlapAccepted
+}
+
+// endSession stores session ended at end, which may leave it overlapping
+// other sessions but the one of ignoreID, about to be moved out of its way.
+// Under the reject policy a running session ended over a later one is left
+// alone with an OverlapError, so are sessions in locked periods with
+// ErrPeriodLocked.
+func (s TrackerService) endSession(ctx context.Context, session models.TimeTracker, end time.Time, ignoreID uint64) (models.TrackerEvent, error) {
+	eventType := models.EventUpdated
+	if session.IsRunning() {
+		eventType = models.EventStopped
+	}
+
+	previous := session
+	session.End = end
+
+	if err := s.checkLocked(ctx, previous, session); err != nil {
+		return models.TrackerEvent{}, err
+	}
+
+	remaining, err := s.store.Overlapping(ctx, session.Start, session.End, session.ID)
+	if err != nil {
+		return models.TrackerEvent{}, fmt.Errorf("%w failed to look up overlapping trackers", err)
+	}
+
+	var (
+		rejected = make([]uint64, 0)
+		now      = s.now()
+	)
+
+	session.Overlapping = make([]uint64, 0)
+	for _, other := range remaining {
+		switch {
+		case other.ID == ignoreID:
+		case s.overlaps != OverlapReject, s.acceptsOverlap(previous, other):
+			session.Overlapping = append(session.Overlapping, other.ID)
+		case session.OverlapsAt(other, now):
+			rejected = append(rejected, other.ID)
+		}
+	}
+
+	if len(rejected) > 0 {
+		return models.TrackerEvent{}, &OverlapError{IDs: rejected}
+	}
+
+	session.OverlapAccepted = s.overlaps != OverlapReject && len(session.Overlapping) > 0
+
+	ended, err := s.store.Store(ctx, session, session.Meta.GetVersion())
+	if err != nil {
+		return models.TrackerEvent{}, fmt.Errorf("%w failed to end tracker %d", err, session.ID)
+	}
+
+	return models.NewTrackerEvent(eventType, ended), nil
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrBlockNotFound = errors.New("planned block not found")
+
+const maxNoteLength = 1024
+
+type PlanStore interface {
+	Get(ctx context.Context, id uint64) (models.PlannedBlock, error)
+	// List returns the blocks starting in [start, end), every block when both
+	// are zero.
+	List(ctx context.Context, start, end time.Time) ([]models.PlannedBlock, error)
+	Store(ctx context.Context, block models.PlannedBlock, version uint32) (models.PlannedBlock, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+// PlanService manages planned blocks and compares them with the tracked
+// time.
+type PlanService struct {
+	store    PlanStore
+	trackers TrackerStore
+	rules    ValidationRules
+	now      func() time.Time
+}
+
+type ListBlocksParams struct {
+	Start time.Time
+	End   time.Time
+}
+
+type CreateBlockParams struct {
+	Start   time.Time
+	End     time.Time
+	Project string
+	Note    string
+}
+
+// UpdateBlockParams replaces the block, every field is written.
+type UpdateBlockParams struct {
+	ID      uint64
+	Start   time.Time
+	End     time.Time
+	Project string
+	Note    string
+	Version uint32
+}
+
+type DeleteBlockParams struct {
+	ID      uint64
+	Version uint32
+}
+
+type PlanReportParams struct {
+	Start    time.Time
+	End      time.Time
+	Location *time.Location
+}
+
+func NewPlanService(store PlanStore, trackers TrackerStore, rules ValidationRules) PlanService {
+	return PlanService{
+		store:    store,
+		trackers: trackers,
+		rules:    rules,
+		now:      time.Now,
+	}
+}
+
+func (s PlanService) GetBlock(ctx context.Context, id uint64) (models.PlannedBlock, error) {
+	block, err := s.store.Get(ctx, id)
+	if err != nil {
+		return models.PlannedBlock{}, fmt.Errorf("%w failed to get planned block", err)
+	}
+
+	if block.IsZero() {
+		return models.PlannedBlock{}, ErrBlockNotFound
+	}
+
+	return block, nil
+}
+
+func (s PlanService) ListBlocks(ctx context.Context, params ListBlocksParams) ([]models.PlannedBlock, error) {
+	blocks, err := s.store.List(ctx, params.Start, params.End)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to list planned blocks", err)
+	}
+
+	return blocks, nil
+}
+
+func (s PlanService) CreateBlock(ctx context.Context, params CreateBlockParams) (models.PlannedBlock, error) {
+	block := models.NewPlannedBlock(0, params.Start, params.End, params.Project)
+	block.Note = params.Note
+
+	if err := s.validate(block); err != nil {
+		return models.PlannedBlock{}, err
+	}
+
+	stored, err := s.store.Store(ctx, block, 0)
+	if err != nil {
+		return models.PlannedBlock{}, fmt.Errorf("%w failed to store planned block", err)
+	}
+
+	return stored, nil
+}
+
+func (s PlanService) UpdateBlock(ctx context.Context, params UpdateBlockParams) (models.PlannedBlock, error) {
+	block, err := s.GetBlock(ctx, params.ID)
+	if err != nil {
+		return models.PlannedBlock{}, err
+	}
+
+	if block.Meta.GetVersion() != params.Version {
+		return models.PlannedBlock{}, ErrWrongVersion
+	}
+
+	block.Start = params.Start
+	block.End = params.End
+	block.Project = params.Project
+	block.Note = params.Note
+
+	if err := s.validate(block); err != nil {
+		return models.PlannedBlock{}, err
+	}
+
+	stored, err := s.store.Store(ctx, block, params.Version)
+	if err != nil {
+		return models.PlannedBlock{}, fmt.Errorf("%w failed to store planned block", err)
+	}
+
+	return stored, nil
+}
+
+func (s PlanService) DeleteBlock(ctx context.Context, params DeleteBlockParams) error {
+	block, err := s.GetBlock(ctx, params.ID)
+	if err != nil {
+		return err
+	}
+
+	if params.Version != 0 && block.Meta.GetVersion() != params.Version {
+		return ErrWrongVersion
+	}
+
+	if err := s.store.Delete(ctx, params.ID); err != nil {
+		return fmt.Errorf("%w failed to delete planned block", err)
+	}
+
+	return nil
+}
+
+// PlanReport compares the blocks planned in [Start, End) with the time
+// tracked in that period, trackers crossing its bounds are cut at them.
+func (s PlanService) PlanReport(ctx context.Context, params PlanReportParams) (models.PlanReport, error) {
+	location := params.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	blocks, err := s.store.List(ctx, params.Start, params.End)
+	if err != nil {
+		return models.PlanReport{}, fmt.Errorf("%w failed to list planned blocks", err)
+	}
+
+	trackers, err := s.trackers.Overlapping(ctx, params.Start, params.End, 0)
+	if err != nil {
+		return models.PlanReport{}, fmt.Errorf("%w failed to list trackers", err)
+	}
+
+	now := s.now()
+	for i, tracker := range trackers {
+		if tracker.Start.Before(params.Start) {
+			trackers[i].Start = params.Start
+		}
+		if !params.End.IsZero() && (tracker.End.After(params.End) || (tracker.IsRunning() && now.After(params.End))) {
+			trackers[i].End = params.End
+		}
+	}
+
+	report := models.BuildPlanReport(blocks, trackers, location, now)
+	report.Start = params.Start
+	report.End = params.End
+
+	return report, nil
+}
+
+func (s PlanService) validate(block models.PlannedBlock) error {
+	problems := &ValidationError{}
+
+	switch {
+	case block.Start.IsZero():
+		problems.add("start", "required", "must be set")
+	case block.End.IsZero():
+		problems.add("end", "required", "must be set")
+	case !block.End.After(block.Start):
+		problems.add("end", "before_start", "must be after start")
+	case block.Duration() > s.rules.MaxSessionLength:
+		problems.add("end", "session_too_long", fmt.Sprintf("blocks must not exceed %s", s.rules.MaxSessionLength))
+	}
+
+	if strings.TrimSpace(block.Project) == "" {
+		problems.add("project", "required", "must not be empty")
+	} else if utf8.RuneCountInString(block.Project) > s.rules.MaxNameLength {
+		problems.add("project", "too_long", fmt.Sprintf("must be at most %d characters", s.rules.MaxNameLength))
+	}
+
+	if utf8.RuneCountInString(block.Note) > maxNoteLength {
+		problems.add("note", "too_long", fmt.Sprintf("must be at most %d characters", maxNoteLength))
+	}
+
+	if len(problems.Fields) > 0 {
+		return problems
+	}
+
+	return nil
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/logging"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrPomodoroNotFound = errors.New("pomodoro not found")
+	ErrPomodoroEnded    = errors.New("pomodoro already ended")
+)
+
+// abortTimeout bounds stopping the tracker of a pomodoro that failed to
+// start, whatever is left of the request.
+const abortTimeout = 10 * time.Second
+
+type PomodoroStore interface {
+	// Get returns the pomodoro with its recorded phases, a zero pomodoro when
+	// it doesn't exist.
+	Get(ctx context.Context, id uint64) (models.Pomodoro, error)
+	ListActive(ctx context.Context) ([]models.Pomodoro, error)
+	Store(ctx context.Context, pomodoro models.Pomodoro, version uint32) (models.Pomodoro, error)
+	// RecordPhase stores a finished phase, recording it again is a no-op.
+	RecordPhase(ctx context.Context, pomodoroID uint64, phase models.PomodoroPhase) error
+	// ListPhases returns the recorded phases starting in [start, end).
+	ListPhases(ctx context.Context, start, end time.Time) ([]models.PomodoroPhase, error)
+}
+
+// PomodoroTrackers writes the trackers of the focus phases, the tracker
+// service so they go through its validation and events.
+type PomodoroTrackers interface {
+	GetTracker(ctx context.Context, id uint64) (models.TimeTracker, error)
+	CreateTracker(ctx context.Context, params CreateTrackerParams) (models.TimeTracker, error)
+	UpdateTracker(ctx context.Context, params UpdateTrackerParams) (models.TimeTracker, error)
+}
+
+// PomodoroService runs trackers on a focus and break schedule. Focus phases
+// are tracked by regular trackers, breaks are only recorded as phases so they
+// never count as tracked time.
+type PomodoroService struct {
+	store    PomodoroStore
+	trackers PomodoroTrackers
+	schedule models.PomodoroSchedule
+	now      func() time.Time
+	logger   logrus.FieldLogger
+}
+
+type StartPomodoroParams struct {
+	Name    string
+	Project string
+	Tags    []string
+	// Schedule overrides the configured schedule field by field, zero fields
+	// keep it. Continuous runs rounds until the pomodoro is stopped.
+	Schedule   models.PomodoroSchedule
+	Continuous bool
+}
+
+type PomodoroReportParams struct {
+	Start    time.Time
+	End      time.Time
+	Location *time.Location
+}
+
+func NewPomodoroService(store PomodoroStore, trackers PomodoroTrackers, schedule models.PomodoroSchedule, logger logrus.FieldLogger) PomodoroService {
+	return PomodoroService{
+		store:    store,
+		trackers: trackers,
+		schedule: schedule,
+		now:      time.Now,
+		logger:   logger,
+	}
+}
+
+// StartPomodoro starts the schedule now with the tracker of its first focus
+// phase. The tracker is stopped again when the pomodoro can't be stored, so
+// no tracker is left running without its pomodoro.
+func (s PomodoroService) StartPomodoro(ctx context.Context, params StartPomodoroParams) (models.Pomodoro, error) {
+	schedule := s.resolveSchedule(params)
+	if err := validateSchedule(schedule); err != nil {
+		return models.Pomodoro{}, err
+	}
+
+	tracker, err := s.trackers.CreateTracker(ctx, CreateTrackerParams{
+		Start:   s.now(),
+		Name:    params.Name,
+		Project: params.Project,
+		Tags:    params.Tags,
+	})
+	if err != nil {
+		return models.Pomodoro{}, err
+	}
+
+	pomodoro := models.NewPomodoro(0, params.Name, schedule, tracker.Start)
+	pomodoro.Project = params.Project
+	pomodoro.Tags = params.Tags
+	pomodoro.TrackerID = tracker.ID
+
+	stored, err := s.store.Store(ctx, pomodoro, 0)
+	if err != nil {
+		s.abortStart(ctx, tracker)
+
+		return models.Pomodoro{}, fmt.Errorf("%w failed to store pomodoro", err)
+	}
+
+	return stored, nil
+}
+
+// abortStart stops the tracker of a pomodoro that failed to start, a failure
+// is only logged since the one that aborted the start is the one worth
+// returning.
+func (s PomodoroService) abortStart(ctx context.Context, tracker models.TimeTracker) {
+	logger := logging.FromContext(ctx, s.logger).WithField("tracker_id", tracker.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+
+	if _, err := s.stopTracker(ctx, tracker.ID, s.now()); err != nil {
+		logger.WithError(err).Error("failed to stop the tracker of an aborted pomodoro")
+	}
+}
+
+func (s PomodoroService) GetPomodoro(ctx context.Context, id uint64) (models.Pomodoro, error) {
+	pomodoro, err := s.store.Get(ctx, id)
+	if err != nil {
+		return models.Pomodoro{}, fmt.Errorf("%w failed to get pomodoro", err)
+	}
+
+	if pomodoro.IsZero() {
+		return models.Pomodoro{}, ErrPomodoroNotFound
+	}
+
+	return pomodoro, nil
+}
+
+func (s PomodoroService) ListActivePomodoros(ctx context.Context) ([]models.Pomodoro, error) {
+	pomodoros, err := s.store.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to list active pomodoros", err)
+	}
+
+	return pomodoros, nil
+}
+
+// StopPomodoro ends the pomodoro now, the phase in progress is recorded as
+// cut short.
+func (s PomodoroService) StopPomodoro(ctx context.Context, id uint64) (models.Pomodoro, error) {
+	pomodoro, err := s.GetPomodoro(ctx, id)
+	if err != nil {
+		return models.Pomodoro{}, err
+	}
+
+	if !pomodoro.IsActive() {
+		return models.Pomodoro{}, ErrPomodoroEnded
+	}
+
+	now := s.now()
+
+	pomodoro, err = s.advance(ctx, pomodoro, now)
+	if err != nil {
+		return models.Pomodoro{}, err
+	}
+
+	if !pomodoro.IsActive() {
+		return pomodoro, nil
+	}
+
+	version := pomodoro.Meta.GetVersion()
+
+	if current, ok := pomodoro.Current(now); ok {
+		current.End = now
+
+		if current.Kind == models.PhaseFocus && pomodoro.TrackerID != 0 {
+			tracker, err := s.stopTracker(ctx, pomodoro.TrackerID, now)
+			if err != nil {
+				return models.Pomodoro{}, err
+			}
+			current.End = tracker.End
+		}
+
+		if err := s.store.RecordPhase(ctx, pomodoro.ID, current); err != nil {
+			return models.Pomodoro{}, fmt.Errorf("%w failed to record pomodoro phase", err)
+		}
+		pomodoro.Phases = append(pomodoro.Phases, current)
+	}
+
+	pomodoro.End = now
+	pomodoro.TrackerID = 0
+
+	stored, err := s.store.Store(ctx, pomodoro, version)
+	if err != nil {
+		return models.Pomodoro{}, fmt.Errorf("%w failed to store pomodoro", err)
+	}
+
+	return stored, nil
+}
+
+// AdvancePomodoros moves the active pomodoros to their current phase, failures
+// are logged and retried on the next run.
+func (s PomodoroService) AdvancePomodoros(ctx context.Context) error {
+	pomodoros, err := s.ListActivePomodoros(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+
+	for _, pomodoro := range pomodoros {
+		if _, err := s.advance(ctx, pomodoro, now); err != nil {
+			logging.FromContext(ctx, s.logger).WithError(err).WithField("pomodoro_id", pomodoro.ID).
+				Warn("failed to advance pomodoro")
+		}
+	}
+
+	return nil
+}
+
+// PomodoroReport sums the pomodoros completed per day.
+func (s PomodoroService) PomodoroReport(ctx context.Context, params PomodoroReportParams) ([]models.PomodoroDay, error) {
+	location := params.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	phases, err := s.store.ListPhases(ctx, params.Start, params.End)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to list pomodoro phases", err)
+	}
+
+	return models.PomodoroDays(phases, location), nil
+}
+
+// advance records the phases of the pomodoro finished by now. Focus trackers
+// are stopped at the end of their phase and started at the beginning of the
+// next one, a focus tracker stopped early by hand ends the pomodoro.
+func (s PomodoroService) advance(ctx context.Context, pomodoro models.Pomodoro, now time.Time) (models.Pomodoro, error) {
+	phases := pomodoro.Schedule.Phases(pomodoro.Start, now)
+	if len(phases) < len(pomodoro.Phases) {
+		return pomodoro, nil
+	}
+
+	version := pomodoro.Meta.GetVersion()
+	changed := false
+
+	for _, phase := range phases[len(pomodoro.Phases):] {
+		if phase.Kind == models.PhaseFocus {
+			if pomodoro.TrackerID == 0 {
+				tracker, err := s.trackers.CreateTracker(ctx, CreateTrackerParams{
+					Start:   phase.Start,
+					Name:    pomodoro.Name,
+					Project: pomodoro.Project,
+					Tags:    pomodoro.Tags,
+				})
+				if err != nil {
+					return models.Pomodoro{}, err
+				}
+
+				pomodoro.TrackerID = tracker.ID
+				changed = true
+			}
+
+			tracker, err := s.trackers.GetTracker(ctx, pomodoro.TrackerID)
+			if err != nil && !errors.Is(err, ErrTrackerNotFound) {
+				return models.Pomodoro{}, err
+			}
+
+			if err != nil || (!tracker.IsRunning() && tracker.End.Before(phase.End)) {
+				phase.End = tracker.End
+				if err != nil || phase.End.Before(phase.Start) {
+					phase.End = phase.Start
+				}
+				phase.TrackerID = tracker.ID
+
+				if err := s.store.RecordPhase(ctx, pomodoro.ID, phase); err != nil {
+					return models.Pomodoro{}, fmt.Errorf("%w failed to record pomodoro phase", err)
+				}
+
+				pomodoro.Phases = append(pomodoro.Phases, phase)
+				pomodoro.End = phase.End
+				pomodoro.TrackerID = 0
+				changed = true
+
+				break
+			}
+
+			if phase.End.After(now) {
+				break
+			}
+
+			if tracker.IsRunning() {
+				if _, err := s.stopTracker(ctx, tracker.ID, phase.End); err != nil {
+					return models.Pomodoro{}, err
+				}
+			}
+
+			phase.TrackerID = tracker.ID
+			pomodoro.TrackerID = 0
+		} else if phase.End.After(now) {
+			break
+		}
+
+		phase.Completed = true
+		if err := s.store.RecordPhase(ctx, pomodoro.ID, phase); err != nil {
+			return models.Pomodoro{}, fmt.Errorf("%w failed to record pomodoro phase", err)
+		}
+
+		pomodoro.Phases = append(pomodoro.Phases, phase)
+		changed = true
+
+		if phase.Kind == models.PhaseFocus && phase.Round == pomodoro.Schedule.Rounds {
+			pomodoro.End = phase.End
+		}
+	}
+
+	if !changed {
+		return pomodoro, nil
+	}
+
+	stored, err := s.store.Store(ctx, pomodoro, version)
+	if err != nil {
+		return models.Pomodoro{}, fmt.Errorf("%w failed to store pomodoro", err)
+	}
+
+	return stored, nil
+}
+
+func (s PomodoroService) stopTracker(ctx context.Context, id uint64, end time.Time) (models.TimeTracker, error) {
+	tracker, err := s.trackers.GetTracker(ctx, id)
+	if err != nil {
+		return models.TimeTracker{}, err
+	}
+
+	if !tracker.IsRunning() {
+		return tracker, nil
+	}
+
+	return s.trackers.UpdateTracker(ctx, UpdateTrackerParams{
+		ID:      tracker.ID,
+		End:     end,
+		Version: tracker.Meta.GetVersion(),
+	})
+}
+
+func (s PomodoroService) resolveSchedule(params StartPomodoroParams) models.PomodoroSchedule {
+	schedule := s.schedule
+
+	if params.Schedule.Focus != 0 {
+		schedule.Focus = params.Schedule.Focus
+	}
+	if params.Schedule.ShortBreak != 0 {
+		schedule.ShortBreak = params.Schedule.ShortBreak
+	}
+	if params.Schedule.LongBreak != 0 {
+		schedule.LongBreak = params.Schedule.LongBreak
+	}
+	if params.Schedule.LongBreakEvery != 0 {
+		schedule.LongBreakEvery = params.Schedule.LongBreakEvery
+	}
+	if params.Schedule.Rounds != 0 {
+		schedule.Rounds = params.Schedule.Rounds
+	}
+	if params.Continuous {
+		schedule.Rounds = 0
+	}
+
+	return schedule
+}
+
+func validateSchedule(schedule models.PomodoroSchedule) error {
+	problems := &ValidationError{}
+
+	if schedule.Focus < time.Minute || schedule.Focus > 4*time.Hour {
+		problems.add("focus", "out_of_range", "must be between 1 minute and 4 hours")
+	}
+
+	if schedule.ShortBreak < 0 || schedule.LongBreak < 0 || schedule.ShortBreak > 4*time.Hour || schedule.LongBreak > 4*time.Hour {
+		problems.add("breaks", "out_of_range", "must be between 0 and 4 hours")
+	}
+
+	if schedule.LongBreakEvery < 0 || schedule.Rounds < 0 {
+		problems.add("rounds", "negative", "long_break_every and rounds must not be negative")
+	}
+
+	if len(problems.Fields) > 0 {
+		return problems
+	}
+
+	return nil
+}
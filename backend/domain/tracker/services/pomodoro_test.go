@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pento/code-challenge/domain/tracker/models"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+type memoryPomodoros struct {
+	pomodoros map[uint64]models.Pomodoro
+	phases    map[uint64][]models.PomodoroPhase
+}
+
+func (m *memoryPomodoros) Get(ctx context.Context, id uint64) (models.Pomodoro, error) {
+	pomodoro := m.pomodoros[id]
+	pomodoro.Phases = append([]models.PomodoroPhase{}, m.phases[id]...)
+
+	return pomodoro, nil
+}
+
+func (m *memoryPomodoros) ListActive(ctx context.Context) ([]models.Pomodoro, error) {
+	active := make([]models.Pomodoro, 0)
+	for id, pomodoro := range m.pomodoros {
+		if pomodoro.IsActive() {
+			pomodoro, _ = m.Get(ctx, id)
+			active = append(active, pomodoro)
+		}
+	}
+
+	return active, nil
+}
+
+func (m *memoryPomodoros) Store(ctx context.Context, pomodoro models.Pomodoro, version uint32) (models.Pomodoro, error) {
+	if version == 0 {
+		pomodoro.ID = uint64(len(m.pomodoros) + 1)
+	} else if m.pomodoros[pomodoro.ID].Meta.GetVersion() != version {
+		return models.Pomodoro{}, ErrWrongVersion
+	}
+
+	pomodoro.Meta.HydrateMeta(false, time.Time{}, time.Time{}, version+1)
+	m.pomodoros[pomodoro.ID] = pomodoro
+
+	return m.Get(ctx, pomodoro.ID)
+}
+
+func (m *memoryPomodoros) RecordPhase(ctx context.Context, pomodoroID uint64, phase models.PomodoroPhase) error {
+	for _, recorded := range m.phases[pomodoroID] {
+		if recorded.Round == phase.Round && recorded.Kind == phase.Kind {
+			return nil
+		}
+	}
+	m.phases[pomodoroID] = append(m.phases[pomodoroID], phase)
+
+	return nil
+}
+
+func (m *memoryPomodoros) ListPhases(ctx context.Context, start, end time.Time) ([]models.PomodoroPhase, error) {
+	return nil, nil
+}
+
+type memoryTrackers map[uint64]models.TimeTracker
+
+func (m memoryTrackers) GetTracker(ctx context.Context, id uint64) (models.TimeTracker, error) {
+	tracker, ok := m[id]
+	if !ok {
+		return models.TimeTracker{}, ErrTrackerNotFound
+	}
+
+	return tracker, nil
+}
+
+func (m memoryTrackers) CreateTracker(ctx context.Context, params CreateTrackerParams) (models.TimeTracker, error) {
+	tracker := models.NewTimeTracker(uint64(len(m)+1), params.Start, time.Time{}, params.Name)
+	m[tracker.ID] = tracker
+
+	return tracker, nil
+}
+
+func (m memoryTrackers) UpdateTracker(ctx context.Context, params UpdateTrackerParams) (models.TimeTracker, error) {
+	tracker := m[params.ID]
+	tracker.End = params.End
+	m[tracker.ID] = tracker
+
+	return tracker, nil
+}
+
+func Test_PomodoroService_AdvancePomodoros(t *testing.T) {
+
+	start := time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	type testExpectation struct {
+		phases   []models.PomodoroPhaseKind
+		trackers []time.Time
+		running  bool
+		end      time.Time
+	}
+
+	testCases := []struct {
+		description string
+		ticks       []int
+		// stopAt stops the focus tracker by hand at that minute, -1 leaves it.
+		stopAt   int
+		expected testExpectation
+	}{
+		{
+			description: "when the first focus phase is in progress",
+			ticks:       []int{10},
+			stopAt:      -1,
+			expected: testExpectation{
+				phases:   []models.PomodoroPhaseKind{},
+				trackers: []time.Time{{}},
+				running:  true,
+			},
+		},
+		{
+			description: "when a break is in progress the focus tracker is stopped on time",
+			ticks:       []int{27},
+			stopAt:      -1,
+			expected: testExpectation{
+				phases:   []models.PomodoroPhaseKind{models.PhaseFocus},
+				trackers: []time.Time{at(25)},
+			},
+		},
+		{
+			description: "when the next focus phase starts a new tracker",
+			ticks:       []int{27, 31},
+			stopAt:      -1,
+			expected: testExpectation{
+				phases:   []models.PomodoroPhaseKind{models.PhaseFocus, models.PhaseShortBreak},
+				trackers: []time.Time{at(25), {}},
+				running:  true,
+			},
+		},
+		{
+			description: "when a missed run catches up to the end of the schedule",
+			ticks:       []int{120},
+			stopAt:      -1,
+			expected: testExpectation{
+				phases:   []models.PomodoroPhaseKind{models.PhaseFocus, models.PhaseShortBreak, models.PhaseFocus},
+				trackers: []time.Time{at(25), at(55)},
+				end:      at(55),
+			},
+		},
+		{
+			description: "when the focus tracker was stopped by hand",
+			ticks:       []int{20},
+			stopAt:      15,
+			expected: testExpectation{
+				phases:   []models.PomodoroPhaseKind{models.PhaseFocus},
+				trackers: []time.Time{at(15)},
+				end:      at(15),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			store := &memoryPomodoros{pomodoros: map[uint64]models.Pomodoro{}, phases: map[uint64][]models.PomodoroPhase{}}
+			trackers := memoryTrackers{}
+			service := NewPomodoroService(store, trackers, models.PomodoroSchedule{
+				Focus:      25 * time.Minute,
+				ShortBreak: 5 * time.Minute,
+				Rounds:     2,
+			}, logrus.New())
+			service.now = func() time.Time { return start }
+
+			pomodoro, err := service.StartPomodoro(context.Background(), StartPomodoroParams{Name: "deep work"})
+			g.Expect(err).ToNot(HaveOccurred())
+
+			if tc.stopAt >= 0 {
+				_, err := trackers.UpdateTracker(context.Background(), UpdateTrackerParams{ID: pomodoro.TrackerID, End: at(tc.stopAt)})
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+
+			for _, tick := range tc.ticks {
+				service.now = func() time.Time { return at(tick) }
+				g.Expect(service.AdvancePomodoros(context.Background())).To(Succeed())
+			}
+
+			pomodoro, err = service.GetPomodoro(context.Background(), pomodoro.ID)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			kinds := make([]models.PomodoroPhaseKind, 0, len(pomodoro.Phases))
+			for _, phase := range pomodoro.Phases {
+				kinds = append(kinds, phase.Kind)
+			}
+			g.Expect(kinds).To(Equal(tc.expected.phases))
+
+			ends := make([]time.Time, 0, len(trackers))
+			for id := uint64(1); id <= uint64(len(trackers)); id++ {
+				ends = append(ends, trackers[id].End)
+			}
+			g.Expect(ends).To(Equal(tc.expected.trackers))
+
+			g.Expect(pomodoro.TrackerID != 0).To(Equal(tc.expected.running))
+			g.Expect(pomodoro.End).To(Equal(tc.expected.end))
+		})
+	}
+}
+
+func Test_PomodoroService_StopPomodoro(t *testing.T) {
+	g := NewWithT(t)
+
+	start := time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC)
+
+	store := &memoryPomodoros{pomodoros: map[uint64]models.Pomodoro{}, phases: map[uint64][]models.PomodoroPhase{}}
+	trackers := memoryTrackers{}
+	service := NewPomodoroService(store, trackers, models.PomodoroSchedule{Focus: 25 * time.Minute}, logrus.New())
+	service.now = func() time.Time { return start }
+
+	pomodoro, err := service.StartPomodoro(context.Background(), StartPomodoroParams{Name: "deep work"})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	service.now = func() time.Time { return start.Add(40 * time.Minute) }
+
+	stopped, err := service.StopPomodoro(context.Background(), pomodoro.ID)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(stopped.End).To(Equal(start.Add(40 * time.Minute)))
+	g.Expect(stopped.Phases).To(HaveLen(2))
+	g.Expect(stopped.Phases[0].Completed).To(BeTrue())
+	g.Expect(stopped.Phases[1].Completed).To(BeFalse())
+	g.Expect(stopped.Phases[1].Duration()).To(Equal(15 * time.Minute))
+	g.Expect(trackers[2].End).To(Equal(start.Add(40 * time.Minute)))
+
+	_, err = service.StopPomodoro(context.Background(), pomodoro.ID)
+	g.Expect(err).To(MatchError(ErrPomodoroEnded))
+}
+
+// failingPomodoros can't store new pomodoros.
+type failingPomodoros struct {
+	*memoryPomodoros
+}
+
+func (f failingPomodoros) Store(ctx context.Context, pomodoro models.Pomodoro, version uint32) (models.Pomodoro, error) {
+	return models.Pomodoro{}, errors.New("connection reset")
+}
+
+func Test_PomodoroService_StartPomodoro_storeFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	start := time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC)
+
+	store := failingPomodoros{&memoryPomodoros{pomodoros: map[uint64]models.Pomodoro{}, phases: map[uint64][]models.PomodoroPhase{}}}
+	trackers := memoryTrackers{}
+	service := NewPomodoroService(store, trackers, models.PomodoroSchedule{Focus: 25 * time.Minute}, logrus.New())
+	service.now = func() time.Time { return start }
+
+	_, err := service.StartPomodoro(context.Background(), StartPomodoroParams{Name: "deep work"})
+	g.Expect(err).To(HaveOccurred(), "should report the failure")
+
+	g.Expect(trackers).To(HaveLen(1), "should have started the tracker")
+	g.Expect(trackers[1].IsRunning()).To(BeFalse(), "should stop the tracker again")
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"time"
+)
+
+var ErrInvalidRounding = errors.New("invalid rounding policy")
+
+// ReportService builds reports out of the stored trackers, it never writes
+// them.
+type ReportService struct {
+	store    TrackerStore
+	rounding models.Rounding
+	now      func() time.Time
+}
+
+type ReportParams struct {
+	// Start and End bound the report, trackers are included when they start
+	// in [Start, End).
+	Start    time.Time
+	End      time.Time
+	Location *time.Location
+	// Rounding overrides the configured policy field by field, zero fields
+	// keep the configured value.
+	Rounding models.Rounding
+}
+
+func NewReportService(store TrackerStore, rounding models.Rounding) ReportService {
+	return ReportService{
+		store:    store,
+		rounding: rounding,
+		now:      time.Now,
+	}
+}
+
+// ValidateRounding checks the rounding mode, increment and aggregation.
+func ValidateRounding(rounding models.Rounding) error {
+	switch rounding.Mode {
+	case models.RoundNone, models.RoundNearest, models.RoundUp, models.RoundDown:
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidRounding, rounding.Mode)
+	}
+
+	switch rounding.Aggregation {
+	case models.RoundPerEntry, models.RoundPerDay:
+	default:
+		return fmt.Errorf("%w: unknown aggregation %q", ErrInvalidRounding, rounding.Aggregation)
+	}
+
+	if rounding.Mode != models.RoundNone && (rounding.Increment < time.Minute || rounding.Increment > 24*time.Hour) {
+		return fmt.Errorf("%w: increment must be between 1m and 24h", ErrInvalidRounding)
+	}
+
+	return nil
+}
+
+func (s ReportService) Report(ctx context.Context, params ReportParams) (models.Report, error) {
+	rounding := s.rounding
+	if params.Rounding.Mode != "" {
+		rounding.Mode = params.Rounding.Mode
+	}
+	if params.Rounding.Increment != 0 {
+		rounding.Increment = params.Rounding.Increment
+	}
+	if params.Rounding.Aggregation != "" {
+		rounding.Aggregation = params.Rounding.Aggregation
+	}
+
+	if err := ValidateRounding(rounding); err != nil {
+		return models.Report{}, err
+	}
+
+	location := params.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	trackers, err := s.store.List(ctx, params.Start, params.End)
+	if err != nil {
+		return models.Report{}, fmt.Errorf("%w failed to list trackers", err)
+	}
+
+	included := make([]models.TimeTracker, 0, len(trackers))
+	for _, tracker := range trackers {
+		if !params.End.IsZero() && !tracker.Start.Before(params.End) {
+			continue
+		}
+
+		included = append(included, tracker)
+	}
+
+	report := models.BuildReport(included, rounding, location, s.now())
+	report.Start = params.Start
+	report.End = params.End
+
+	return report, nil
+}
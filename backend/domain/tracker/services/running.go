@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+)
+
+var ErrTrackerRunning = errors.New("another tracker is already running")
+
+// RunningPolicy decides what happens to running trackers when a new one
+// starts.
+type RunningPolicy struct {
+	// Single allows one running tracker at a time, the store backs it with a
+	// unique index.
+	Single bool
+	// AutoStop stops the running trackers at the start of the new one
+	// instead of rejecting it.
+	AutoStop bool
+}
+
+// stopRunning applies the running policy to a tracker about to start, it
+// returns the events of the trackers it stopped.
+func (s TrackerService) stopRunning(ctx context.Context, tracker models.TimeTracker) ([]models.TrackerEvent, error) {
+	events := make([]models.TrackerEvent, 0)
+
+	if !s.running.Single && !s.running.AutoStop {
+		return events, nil
+	}
+
+	running, err := s.store.ListRunning(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to list running trackers", err)
+	}
+
+	for _, previous := range running {
+		// a tracker started after the new one can't be stopped at its start
+		if !s.running.AutoStop || !previous.Start.Before(tracker.Start) {
+			if s.running.Single {
+				return nil, ErrTrackerRunning
+			}
+
+			continue
+		}
+
+		event, err := s.endSession(ctx, previous, tracker.Start, tracker.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"time"
+)
+
+var (
+	// ErrPeriodLocked rejects changes to trackers inside an approved
+	// timesheet, until an approver reopens it.
+	ErrPeriodLocked           = errors.New("period is locked by an approved timesheet")
+	ErrInvalidTransition      = errors.New("timesheet action doesn't apply to its status")
+	ErrUnknownTimesheetAction = errors.New("unknown timesheet action")
+)
+
+type TimesheetStore interface {
+	// GetByWeek returns the timesheet of the week starting at start, a zero
+	// timesheet when none was stored.
+	GetByWeek(ctx context.Context, start time.Time) (models.Timesheet, error)
+	// List returns the stored timesheets, all of them when status is empty.
+	List(ctx context.Context, status models.TimesheetStatus) ([]models.Timesheet, error)
+	// Store creates the timesheet when version is zero and updates its
+	// status otherwise, comment is added in the same transaction.
+	Store(ctx context.Context, timesheet models.Timesheet, version uint32, comment models.TimesheetComment) (models.Timesheet, error)
+	// Approved returns the approved timesheets covering part of [start, end].
+	Approved(ctx context.Context, start, end time.Time) ([]models.Timesheet, error)
+}
+
+// TimesheetService groups trackers per week and moves the weekly timesheets
+// through submission and approval. Approved weeks are locked, see
+// TrackerService.WithLocks.
+type TimesheetService struct {
+	store    TimesheetStore
+	trackers TrackerStore
+	location *time.Location
+	now      func() time.Time
+}
+
+// TimesheetActionParams applies an action to the timesheet of the week of
+// Week, a day read by its calendar date. Version is zero until the
+// timesheet is first stored.
+type TimesheetActionParams struct {
+	Week    time.Time
+	Action  models.TimesheetAction
+	Author  string
+	Comment string
+	Version uint32
+}
+
+func NewTimesheetService(store TimesheetStore, trackers TrackerStore, location *time.Location) TimesheetService {
+	return TimesheetService{
+		store:    store,
+		trackers: trackers,
+		location: location,
+		now:      time.Now,
+	}
+}
+
+// GetTimesheet returns the timesheet of the week of day, read by its
+// calendar date, with its trackers. Weeks never submitted are open.
+func (s TimesheetService) GetTimesheet(ctx context.Context, day time.Time) (models.Timesheet, error) {
+	timesheet, err := s.timesheet(ctx, day)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	trackers, err := s.trackers.Overlapping(ctx, timesheet.Start, timesheet.End, 0)
+	if err != nil {
+		return models.Timesheet{}, fmt.Errorf("%w failed to list trackers", err)
+	}
+
+	timesheet.Summarize(trackers, s.location, s.now())
+
+	return timesheet, nil
+}
+
+func (s TimesheetService) ListTimesheets(ctx context.Context, status models.TimesheetStatus) ([]models.Timesheet, error) {
+	timesheets, err := s.store.List(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to list timesheets", err)
+	}
+
+	for i := range timesheets {
+		timesheets[i] = s.localize(timesheets[i])
+	}
+
+	return timesheets, nil
+}
+
+// Transition applies an action to a timesheet. Rejections need a comment,
+// and a week can't be submitted while one of its trackers is running.
+func (s TimesheetService) Transition(ctx context.Context, params TimesheetActionParams) (models.Timesheet, error) {
+	timesheet, err := s.GetTimesheet(ctx, params.Week)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	if timesheet.Meta.GetVersion() != params.Version {
+		return models.Timesheet{}, ErrWrongVersion
+	}
+
+	if err := validateTransition(timesheet, params); err != nil {
+		return models.Timesheet{}, err
+	}
+
+	next, ok := timesheet.Next(params.Action)
+	if !ok {
+		return models.Timesheet{}, fmt.Errorf("%w: can't %s a %s timesheet", ErrInvalidTransition, params.Action, timesheet.Status)
+	}
+
+	timesheet.Status = next
+
+	stored, err := s.store.Store(ctx, timesheet, params.Version, models.TimesheetComment{
+		Action: params.Action,
+		Author: params.Author,
+		Body:   params.Comment,
+		At:     s.now(),
+	})
+	if err != nil {
+		return models.Timesheet{}, fmt.Errorf("%w failed to store timesheet", err)
+	}
+
+	stored = s.localize(stored)
+	stored.Summarize(timesheet.Trackers, s.location, s.now())
+
+	return stored, nil
+}
+
+// LockedPeriod returns an approved timesheet covering part of [start, end],
+// false when the period is open.
+func (s TimesheetService) LockedPeriod(ctx context.Context, start, end time.Time) (models.Timesheet, bool, error) {
+	approved, err := s.store.Approved(ctx, start, end)
+	if err != nil {
+		return models.Timesheet{}, false, fmt.Errorf("%w failed to list approved timesheets", err)
+	}
+
+	if len(approved) == 0 {
+		return models.Timesheet{}, false, nil
+	}
+
+	return approved[0], true, nil
+}
+
+func (s TimesheetService) timesheet(ctx context.Context, day time.Time) (models.Timesheet, error) {
+	// noon keeps the calendar date clear of DST shifts
+	empty := models.NewTimesheet(time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, s.location), s.location)
+
+	timesheet, err := s.store.GetByWeek(ctx, empty.Start)
+	if err != nil {
+		return models.Timesheet{}, fmt.Errorf("%w failed to get timesheet", err)
+	}
+
+	if timesheet.IsZero() {
+		return empty, nil
+	}
+
+	return s.localize(timesheet), nil
+}
+
+// localize reads the week bounds of a stored timesheet in the location.
+func (s TimesheetService) localize(timesheet models.Timesheet) models.Timesheet {
+	timesheet.Start = timesheet.Start.In(s.location)
+	timesheet.End = timesheet.End.In(s.location)
+
+	return timesheet
+}
+
+func validateTransition(timesheet models.Timesheet, params TimesheetActionParams) error {
+	problems := &ValidationError{}
+
+	switch params.Action {
+	case models.TimesheetSubmit:
+		for _, tracker := range timesheet.Trackers {
+			if tracker.IsRunning() {
+				problems.add("trackers", "running", fmt.Sprintf("tracker %d is still running", tracker.ID))
+
+				break
+			}
+		}
+	case models.TimesheetReject:
+		if params.Comment == "" {
+			problems.add("comment", "required", "is required to reject a timesheet")
+		}
+	case models.TimesheetApprove, models.TimesheetReopen:
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownTimesheetAction, params.Action)
+	}
+
+	if len(problems.Fields) > 0 {
+		return problems
+	}
+
+	return nil
+}
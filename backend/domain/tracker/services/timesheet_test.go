@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pento/code-challenge/domain/tracker/models"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// weekLocker locks a single approved week.
+type weekLocker models.Timesheet
+
+func (l weekLocker) LockedPeriod(ctx context.Context, start, end time.Time) (models.Timesheet, bool, error) {
+	if !l.Start.After(end) && l.End.After(start) {
+		return models.Timesheet(l), true, nil
+	}
+
+	return models.Timesheet{}, false, nil
+}
+
+func Test_TrackerService_checkLocked(t *testing.T) {
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 5, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	approved := models.NewTimesheet(at(5, 12), time.UTC)
+	approved.Status = models.TimesheetApproved
+
+	service := TrackerService{locks: weekLocker(approved), now: func() time.Time { return at(12, 9) }}
+
+	testCases := []struct {
+		description string
+		tracker     models.TimeTracker
+		locked      bool
+	}{
+		{
+			description: "when the tracker is inside the approved week",
+			tracker:     models.NewTimeTracker(1, at(5, 9), at(5, 17), "a"),
+			locked:      true,
+		},
+		{
+			description: "when the tracker ends in the approved week",
+			tracker:     models.NewTimeTracker(1, at(2, 22), at(3, 2), "a"),
+			locked:      true,
+		},
+		{
+			description: "when the tracker ends as the approved week starts",
+			tracker:     models.NewTimeTracker(1, at(2, 20), at(3, 0), "a"),
+			locked:      true,
+		},
+		{
+			description: "when the tracker starts as the approved week ends",
+			tracker:     models.NewTimeTracker(1, at(10, 0), at(10, 8), "a"),
+			locked:      false,
+		},
+		{
+			description: "when a running tracker started before the approved week",
+			tracker:     models.NewTimeTracker(1, at(1, 9), time.Time{}, "a"),
+			locked:      true,
+		},
+		{
+			description: "when a running tracker started after the approved week",
+			tracker:     models.NewTimeTracker(1, at(11, 9), time.Time{}, "a"),
+			locked:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := service.checkLocked(context.Background(), tc.tracker)
+
+			g.Expect(errors.Is(err, ErrPeriodLocked)).To(Equal(tc.locked))
+		})
+	}
+
+	g := NewWithT(t)
+	g.Expect(TrackerService{}.checkLocked(context.Background(), models.NewTimeTracker(1, at(5, 9), at(5, 17), "a"))).To(Succeed())
+}
+
+func Test_validateTransition(t *testing.T) {
+
+	timesheet := models.NewTimesheet(time.Date(2021, 5, 5, 12, 0, 0, 0, time.UTC), time.UTC)
+	timesheet.Trackers = []models.TimeTracker{
+		models.NewTimeTracker(1, time.Date(2021, 5, 5, 9, 0, 0, 0, time.UTC), time.Time{}, "a"),
+	}
+
+	testCases := []struct {
+		description string
+		timesheet   models.Timesheet
+		params      TimesheetActionParams
+		expected    error
+	}{
+		{
+			description: "when a tracker of the week is running",
+			timesheet:   timesheet,
+			params:      TimesheetActionParams{Action: models.TimesheetSubmit},
+			expected:    ErrValidation,
+		},
+		{
+			description: "when the week is submitted",
+			timesheet:   models.NewTimesheet(time.Date(2021, 5, 5, 12, 0, 0, 0, time.UTC), time.UTC),
+			params:      TimesheetActionParams{Action: models.TimesheetSubmit},
+		},
+		{
+			description: "when a rejection has no comment",
+			timesheet:   timesheet,
+			params:      TimesheetActionParams{Action: models.TimesheetReject},
+			expected:    ErrValidation,
+		},
+		{
+			description: "when a rejection has a comment",
+			timesheet:   timesheet,
+			params:      TimesheetActionParams{Action: models.TimesheetReject, Comment: "missing Friday"},
+		},
+		{
+			description: "when the action is unknown",
+			timesheet:   timesheet,
+			params:      TimesheetActionParams{Action: models.TimesheetAction("archive")},
+			expected:    ErrUnknownTimesheetAction,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := validateTransition(tc.timesheet, tc.params)
+
+			if tc.expected == nil {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(errors.Is(err, tc.expected)).To(BeTrue(), "error %v", err)
+			}
+		})
+	}
+}
+
+func Test_TrackerService_endSession_locked(t *testing.T) {
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 5, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	approved := models.NewTimesheet(at(5, 12), time.UTC)
+	approved.Status = models.TimesheetApproved
+
+	testCases := []struct {
+		description string
+		change      func(service TrackerService) error
+	}{
+		{
+			description: "when a create trims a session of the approved week",
+			change: func(service TrackerService) error {
+				service.overlaps = OverlapTrim
+				_, err := service.CreateTracker(context.Background(), CreateTrackerParams{Start: at(10, 9), Name: "b"})
+
+				return err
+			},
+		},
+		{
+			description: "when a create stops a tracker running since the approved week",
+			change: func(service TrackerService) error {
+				service.running = RunningPolicy{AutoStop: true}
+				_, err := service.CreateTracker(context.Background(), CreateTrackerParams{Start: at(10, 9), Name: "b"})
+
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			store := newMemoryStore(models.NewTimeTracker(1, at(7, 9), time.Time{}, "a"))
+			service := newTestTrackerService(store, OverlapReject, at(10, 12)).WithLocks(weekLocker(approved))
+
+			err := tc.change(service)
+
+			g.Expect(errors.Is(err, ErrPeriodLocked)).To(BeTrue(), "should refuse to change the locked session")
+			g.Expect(store.trackers[1].IsRunning()).To(BeTrue(), "should leave the locked session as it was")
+		})
+	}
+
+	t.Run("when the auto-stop scheduler stops a tracker of the approved week", func(t *testing.T) {
+		g := NewWithT(t)
+
+		store := newMemoryStore(models.NewTimeTracker(1, at(7, 9), time.Time{}, "a"))
+		service := newTestTrackerService(store, OverlapReject, at(10, 12)).WithLocks(weekLocker(approved))
+		service.autoStop = AutoStopRules{MaxDuration: 8 * time.Hour}
+
+		stopped, err := service.AutoStopTrackers(context.Background())
+
+		g.Expect(err).ToNot(HaveOccurred(), "should log the failure")
+		g.Expect(stopped).To(BeEmpty(), "should not stop the tracker")
+		g.Expect(store.trackers[1].IsRunning()).To(BeTrue(), "should leave the locked session as it was")
+	})
+}
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/logging"
 
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -18,16 +21,55 @@ type TrackerStore interface {
 	Get(ctx context.Context, id uint64) (models.TimeTracker, error)
 	List(ctx context.Context, start, end time.Time) ([]models.TimeTracker, error)
 	Store(ctx context.Context, tracker models.TimeTracker, version uint32) (models.TimeTracker, error)
-	Delete(ctx context.Context, id uint64) error
+	// Delete deletes the tracker, a non zero version only deletes it while it
+	// is still at that version.
+	Delete(ctx context.Context, id uint64, version uint32) error
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	CountRunning(ctx context.Context) (int, error)
+	ListRunning(ctx context.Context) ([]models.TimeTracker, error)
+	Heartbeat(ctx context.Context, id uint64, at time.Time) (bool, error)
+	// Overlapping returns the trackers other than excludeID covering part of
+	// the period, a zero start or end leaves that side unbounded.
+	Overlapping(ctx context.Context, start, end time.Time, excludeID uint64) ([]models.TimeTracker, error)
+	// ProjectTrackers returns the trackers of project covering part of the
+	// period, a zero start or end leaves that side unbounded.
+	ProjectTrackers(ctx context.Context, project string, start, end time.Time) ([]models.TimeTracker, error)
+}
+
+type EventPublisher interface {
+	// Publish returns the id assigned to the event.
+	Publish(event models.TrackerEvent) uint64
+}
+
+// BudgetWatcher checks the budgets of projects whose tracked time changed.
+type BudgetWatcher interface {
+	CheckBudgets(ctx context.Context, projects []string)
+}
+
+// PeriodLocker reports the locked periods, like the weeks of approved
+// timesheets.
+type PeriodLocker interface {
+	LockedPeriod(ctx context.Context, start, end time.Time) (models.Timesheet, bool, error)
 }
 
 type TrackerService struct {
-	store TrackerStore
+	store     TrackerStore
+	publisher EventPublisher
+	rules     ValidationRules
+	overlaps  OverlapPolicy
+	running   RunningPolicy
+	autoStop  AutoStopRules
+	budgets   BudgetWatcher
+	locks     PeriodLocker
+	now       func() time.Time
+	logger    logrus.FieldLogger
 }
 
 type CreateTrackerParams struct {
-	Start time.Time
-	Name  string
+	Start   time.Time
+	Name    string
+	Project string
+	Tags    []string
 }
 
 type ListTimeTracker struct {
@@ -40,19 +82,46 @@ type UpdateTrackerParams struct {
 	Start   time.Time
 	End     time.Time
 	Name    string
+	Project string
+	// Tags replace the current tags unless nil.
+	Tags    []string
 	Version uint32
 }
 
 type DeleteTrackerParams struct {
-	ID uint64
+	ID      uint64
+	Version uint32
 }
 
-func NewTrackerService(store TrackerStore) TrackerService {
+func NewTrackerService(store TrackerStore, publisher EventPublisher, rules ValidationRules, overlaps OverlapPolicy, running RunningPolicy, autoStop AutoStopRules, logger logrus.FieldLogger) TrackerService {
 	return TrackerService{
-		store: store,
+		store:     store,
+		publisher: publisher,
+		rules:     rules,
+		overlaps:  overlaps,
+		running:   running,
+		autoStop:  autoStop,
+		now:       time.Now,
+		logger:    logger,
 	}
 }
 
+// WithBudgets returns the service checking the budgets of the projects
+// changed by creates, updates, stops included, batches and auto-stops.
+func (s TrackerService) WithBudgets(budgets BudgetWatcher) TrackerService {
+	s.budgets = budgets
+
+	return s
+}
+
+// WithLocks returns the service rejecting creates, updates and deletes of
+// trackers inside locked periods with ErrPeriodLocked.
+func (s TrackerService) WithLocks(locks PeriodLocker) TrackerService {
+	s.locks = locks
+
+	return s
+}
+
 func (s TrackerService) GetTracker(ctx context.Context, id uint64) (models.TimeTracker, error) {
 	timeTracker, err := s.store.Get(ctx, id)
 	if err != nil {
@@ -76,28 +145,141 @@ func (s TrackerService) ListTrackers(ctx context.Context, params ListTimeTracker
 	return timeTrackers, nil
 }
 
+func (s TrackerService) CountRunningTrackers(ctx context.Context) (int, error) {
+	running, err := s.store.CountRunning(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w failed to count running trackers", err)
+	}
+
+	return running, nil
+}
+
+// CreateTracker stores a new tracker, trackers stopped by the running policy
+// and sessions trimmed by the overlap policy are changed in the same
+// transaction.
 func (s TrackerService) CreateTracker(ctx context.Context, params CreateTrackerParams) (models.TimeTracker, error) {
-	timeTracker := models.NewTimeTracker(0, params.Start, time.Time{}, params.Name)
+	var (
+		timeTracker models.TimeTracker
+		events      []models.TrackerEvent
+	)
+
+	err := s.store.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		timeTracker, events, err = s.createTracker(ctx, params)
 
-	timeTracker, err := s.store.Store(ctx, timeTracker, 0)
+		return err
+	})
 	if err != nil {
-		return models.TimeTracker{}, fmt.Errorf("%w failed to store time tracker", err)
+		return models.TimeTracker{}, err
 	}
 
+	s.publishAll(ctx, events)
+	s.checkBudgets(ctx, events)
+
 	return timeTracker, nil
 }
 
+// UpdateTracker changes a tracker, sessions trimmed by the overlap policy are
+// changed in the same transaction.
 func (s TrackerService) UpdateTracker(ctx context.Context, params UpdateTrackerParams) (models.TimeTracker, error) {
-	timeTracker, err := s.GetTracker(ctx, params.ID)
+	var (
+		timeTracker models.TimeTracker
+		events      []models.TrackerEvent
+	)
+
+	err := s.store.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		timeTracker, events, err = s.updateTracker(ctx, params)
+
+		return err
+	})
 	if err != nil {
 		return models.TimeTracker{}, err
 	}
 
+	s.publishAll(ctx, events)
+	s.checkBudgets(ctx, events)
+
+	return timeTracker, nil
+}
+
+// DeleteTracker deletes a tracker, the version and period lock checks run in
+// the transaction of the delete.
+func (s TrackerService) DeleteTracker(ctx context.Context, params DeleteTrackerParams) error {
+	err := s.store.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.deleteTracker(ctx, params)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.publish(ctx, models.EventDeleted, models.NewTimeTracker(params.ID, time.Time{}, time.Time{}, ""))
+
+	return nil
+}
+
+// createTracker stores a new tracker and returns the events to publish once
+// the transaction is committed.
+func (s TrackerService) createTracker(ctx context.Context, params CreateTrackerParams) (models.TimeTracker, []models.TrackerEvent, error) {
+	timeTracker := models.NewTimeTracker(0, params.Start, time.Time{}, params.Name)
+	timeTracker.Project = params.Project
+	timeTracker.Tags = params.Tags
+
+	if err := s.rules.Validate(timeTracker, s.now()); err != nil {
+		return models.TimeTracker{}, nil, err
+	}
+
+	if err := s.checkLocked(ctx, timeTracker); err != nil {
+		return models.TimeTracker{}, nil, err
+	}
+
+	events, err := s.stopRunning(ctx, timeTracker)
+	if err != nil {
+		return models.TimeTracker{}, nil, err
+	}
+
+	timeTracker, trimmed, err := s.resolveOverlaps(ctx, timeTracker, models.TimeTracker{})
+	if err != nil {
+		return models.TimeTracker{}, nil, err
+	}
+	events = append(events, trimmed...)
+
+	stored, err := s.store.Store(ctx, timeTracker, 0)
+	if err != nil {
+		return models.TimeTracker{}, nil, fmt.Errorf("%w failed to store time tracker", err)
+	}
+	stored.Overlapping = timeTracker.Overlapping
+
+	return stored, append(events, models.NewTrackerEvent(models.EventCreated, stored)), nil
+}
+
+// updateTracker applies the changes and returns the events to publish once
+// the transaction is committed.
+func (s TrackerService) updateTracker(ctx context.Context, params UpdateTrackerParams) (models.TimeTracker, []models.TrackerEvent, error) {
+	timeTracker, err := s.GetTracker(ctx, params.ID)
+	if err != nil {
+		return models.TimeTracker{}, nil, err
+	}
+
 	if timeTracker.IsZero() {
-		return models.TimeTracker{}, ErrTrackerNotFound
+		return models.TimeTracker{}, nil, ErrTrackerNotFound
+	}
+
+	if timeTracker.Meta.GetVersion() != params.Version {
+		return models.TimeTracker{}, nil, ErrWrongVersion
+	}
+
+	previous := timeTracker
+	eventType := models.EventUpdated
+
+	if !params.Start.IsZero() {
+		timeTracker.Start = params.Start
 	}
 
 	if !params.End.IsZero() {
+		if timeTracker.End.IsZero() {
+			eventType = models.EventStopped
+		}
 		timeTracker.End = params.End
 	}
 
@@ -105,19 +287,137 @@ func (s TrackerService) UpdateTracker(ctx context.Context, params UpdateTrackerP
 		timeTracker.Name = params.Name
 	}
 
-	timeTracker, err = s.store.Store(ctx, timeTracker, params.Version)
+	if params.Project != "" {
+		timeTracker.Project = params.Project
+	}
+
+	if params.Tags != nil {
+		timeTracker.Tags = params.Tags
+	}
+
+	rules := s.rules
+	if previous.IsRunning() {
+		// a tracker left running longer than a session may last can still be
+		// stopped, the limit applies to edits of stopped sessions.
+		rules.MaxSessionLength = 0
+	}
+
+	if err := rules.Validate(timeTracker, s.now()); err != nil {
+		return models.TimeTracker{}, nil, err
+	}
+
+	if err := s.checkLocked(ctx, previous, timeTracker); err != nil {
+		return models.TimeTracker{}, nil, err
+	}
+
+	timeTracker, events, err := s.resolveOverlaps(ctx, timeTracker, previous)
 	if err != nil {
-		return models.TimeTracker{}, fmt.Errorf("%w failed to store tracker", err)
+		return models.TimeTracker{}, nil, err
 	}
 
-	return timeTracker, nil
+	stored, err := s.store.Store(ctx, timeTracker, params.Version)
+	if err != nil {
+		return models.TimeTracker{}, nil, fmt.Errorf("%w failed to store tracker", err)
+	}
+	stored.Overlapping = timeTracker.Overlapping
+
+	return stored, append(events, models.NewTrackerEvent(eventType, stored)), nil
 }
 
-func (s TrackerService) DeleteTracker(ctx context.Context, params DeleteTrackerParams) error {
-	err := s.store.Delete(ctx, params.ID)
+// deleteTracker deletes the tracker once its version and period are checked,
+// the store only deletes it at the version checked so a concurrent edit
+// can't slip in between.
+func (s TrackerService) deleteTracker(ctx context.Context, params DeleteTrackerParams) error {
+	var version uint32
+
+	if params.Version != 0 || s.locks != nil {
+		timeTracker, err := s.GetTracker(ctx, params.ID)
+
+		switch {
+		case errors.Is(err, ErrTrackerNotFound) && params.Version == 0:
+			// deleting a missing tracker without a version stays a no-op
+		case err != nil:
+			return err
+		case params.Version != 0 && timeTracker.Meta.GetVersion() != params.Version:
+			return ErrWrongVersion
+		default:
+			if err := s.checkLocked(ctx, timeTracker); err != nil {
+				return err
+			}
+			version = timeTracker.Meta.GetVersion()
+		}
+	}
+
+	err := s.store.Delete(ctx, params.ID, version)
 	if err != nil {
-		return fmt.Errorf("%w failed to delete user", err)
+		return fmt.Errorf("%w failed to delete tracker", err)
+	}
+
+	return nil
+}
+
+// checkLocked returns ErrPeriodLocked when one of the trackers covers part of
+// a locked period, running trackers are measured up to now.
+func (s TrackerService) checkLocked(ctx context.Context, trackers ...models.TimeTracker) error {
+	if s.locks == nil {
+		return nil
+	}
+
+	for _, tracker := range trackers {
+		end := tracker.End
+		if tracker.IsRunning() {
+			end = s.now()
+		}
+		if end.Before(tracker.Start) {
+			end = tracker.Start
+		}
+
+		timesheet, locked, err := s.locks.LockedPeriod(ctx, tracker.Start, end)
+		if err != nil {
+			return err
+		}
+
+		if locked {
+			return fmt.Errorf("%w: week of %s", ErrPeriodLocked, timesheet.Start.Format("2006-01-02"))
+		}
 	}
 
 	return nil
 }
+
+func (s TrackerService) publishAll(ctx context.Context, events []models.TrackerEvent) {
+	for _, event := range events {
+		s.publish(ctx, event.Type, event.Tracker)
+	}
+}
+
+// checkBudgets has the budgets of the trackers changed by the events checked
+// in the background, the check keeps the request logger but not its
+// cancellation so slow notifications don't hold up the response.
+func (s TrackerService) checkBudgets(ctx context.Context, events []models.TrackerEvent) {
+	if s.budgets == nil {
+		return
+	}
+
+	projects := make([]string, 0, len(events))
+	for _, event := range events {
+		projects = append(projects, event.Tracker.Project)
+	}
+
+	detached := logging.WithLogger(context.Background(), logging.FromContext(ctx, s.logger))
+	go s.budgets.CheckBudgets(detached, projects)
+}
+
+func (s TrackerService) publish(ctx context.Context, eventType models.EventType, tracker models.TimeTracker) {
+	if s.publisher == nil {
+		return
+	}
+
+	id := s.publisher.Publish(models.NewTrackerEvent(eventType, tracker))
+
+	logging.FromContext(ctx, s.logger).WithFields(logrus.Fields{
+		"event_id":   id,
+		"event_type": eventType,
+		"tracker_id": tracker.ID,
+	}).Debug("published tracker event")
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pento/code-challenge/domain/tracker/models"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// memoryStore keeps trackers in memory, transactions are counted but not
+// rolled back.
+type memoryStore struct {
+	trackers     map[uint64]models.TimeTracker
+	transactions int
+}
+
+func newMemoryStore(trackers ...models.TimeTracker) *memoryStore {
+	m := &memoryStore{trackers: make(map[uint64]models.TimeTracker)}
+	for _, tracker := range trackers {
+		tracker.Meta.HydrateMeta(false, time.Time{}, time.Time{}, 1)
+		m.trackers[tracker.ID] = tracker
+	}
+
+	return m
+}
+
+func (m *memoryStore) Get(ctx context.Context, id uint64) (models.TimeTracker, error) {
+	return m.trackers[id], nil
+}
+
+func (m *memoryStore) List(ctx context.Context, start, end time.Time) ([]models.TimeTracker, error) {
+	return m.Overlapping(ctx, start, end, 0)
+}
+
+func (m *memoryStore) Store(ctx context.Context, tracker models.TimeTracker, version uint32) (models.TimeTracker, error) {
+	if version == 0 {
+		tracker.ID = uint64(len(m.trackers) + 1)
+	} else if m.trackers[tracker.ID].Meta.GetVersion() != version {
+		return models.TimeTracker{}, ErrWrongVersion
+	}
+
+	tracker.Overlapping = nil
+	tracker.Meta.HydrateMeta(false, time.Time{}, time.Time{}, version+1)
+	m.trackers[tracker.ID] = tracker
+
+	return tracker, nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, id uint64, version uint32) error {
+	tracker := m.trackers[id]
+	if version != 0 && tracker.Meta.GetVersion() != version {
+		return ErrWrongVersion
+	}
+
+	tracker.Meta.SetDeleted(true)
+	m.trackers[id] = tracker
+
+	return nil
+}
+
+func (m *memoryStore) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.transactions++
+
+	return fn(ctx)
+}
+
+func (m *memoryStore) CountRunning(ctx context.Context) (int, error) {
+	running, err := m.ListRunning(ctx)
+
+	return len(running), err
+}
+
+func (m *memoryStore) ListRunning(ctx context.Context) ([]models.TimeTracker, error) {
+	running := make([]models.TimeTracker, 0)
+	for _, tracker := range m.sorted() {
+		if tracker.IsRunning() {
+			running = append(running, tracker)
+		}
+	}
+
+	return running, nil
+}
+
+func (m *memoryStore) Heartbeat(ctx context.Context, id uint64, at time.Time) (bool, error) {
+	return false, nil
+}
+
+func (m *memoryStore) Overlapping(ctx context.Context, start, end time.Time, excludeID uint64) ([]models.TimeTracker, error) {
+	period := models.TimeTracker{Start: start, End: end}
+
+	overlapping := make([]models.TimeTracker, 0)
+	for _, tracker := range m.sorted() {
+		if tracker.ID != excludeID && period.Overlaps(tracker) {
+			overlapping = append(overlapping, tracker)
+		}
+	}
+
+	return overlapping, nil
+}
+
+func (m *memoryStore) ProjectTrackers(ctx context.Context, project string, start, end time.Time) ([]models.TimeTracker, error) {
+	trackers, _ := m.Overlapping(ctx, start, end, 0)
+
+	matching := make([]models.TimeTracker, 0)
+	for _, tracker := range trackers {
+		if tracker.Project == project {
+			matching = append(matching, tracker)
+		}
+	}
+
+	return matching, nil
+}
+
+// sorted returns the trackers left, by start.
+func (m *memoryStore) sorted() []models.TimeTracker {
+	trackers := make([]models.TimeTracker, 0, len(m.trackers))
+	for _, tracker := range m.trackers {
+		if !tracker.Meta.GetDeleted() {
+			trackers = append(trackers, tracker)
+		}
+	}
+
+	sort.Slice(trackers, func(i, j int) bool {
+		return trackers[i].Start.Before(trackers[j].Start)
+	})
+
+	return trackers
+}
+
+func newTestTrackerService(store TrackerStore, overlaps OverlapPolicy, now time.Time) TrackerService {
+	service := NewTrackerService(store, nil, DefaultValidationRules, overlaps, RunningPolicy{}, AutoStopRules{}, logrus.New())
+	service.now = func() time.Time { return now }
+
+	return service
+}
+
+func Test_TrackerService_UpdateTracker_sessionLength(t *testing.T) {
+
+	now := time.Date(2021, 5, 3, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		description string
+		tracker     models.TimeTracker
+		end         time.Time
+		valid       bool
+	}{
+		{
+			description: "when a tracker running for longer than a session is stopped",
+			tracker:     models.NewTimeTracker(1, now.Add(-30*time.Hour), time.Time{}, "a"),
+			end:         now,
+			valid:       true,
+		},
+		{
+			description: "when a stopped session is stretched too long",
+			tracker:     models.NewTimeTracker(1, now.Add(-30*time.Hour), now.Add(-29*time.Hour), "a"),
+			end:         now,
+			valid:       false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			service := newTestTrackerService(newMemoryStore(tc.tracker), OverlapReject, now)
+
+			_, err := service.UpdateTracker(context.Background(), UpdateTrackerParams{ID: 1, End: tc.end, Version: 1})
+
+			if tc.valid {
+				g.Expect(err).ToNot(HaveOccurred(), "should store the tracker")
+			} else {
+				g.Expect(errors.Is(err, ErrValidation)).To(BeTrue(), "should reject the session length")
+			}
+		})
+	}
+}
+
+func Test_TrackerService_UpdateTracker_start(t *testing.T) {
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 5, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	approved := models.NewTimesheet(at(5, 12), time.UTC)
+	approved.Status = models.TimesheetApproved
+
+	testCases := []struct {
+		description string
+		start       time.Time
+		expected    error
+	}{
+		{
+			description: "when the start is moved earlier",
+			start:       at(10, 8),
+		},
+		{
+			description: "when the start is moved after the end",
+			start:       at(10, 11),
+			expected:    ErrValidation,
+		},
+		{
+			description: "when the start is moved into a locked period",
+			start:       at(9, 22),
+			expected:    ErrPeriodLocked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			store := newMemoryStore(models.NewTimeTracker(1, at(10, 9), at(10, 10), "a"))
+			service := newTestTrackerService(store, OverlapReject, at(12, 9)).WithLocks(weekLocker(approved))
+
+			_, err := service.UpdateTracker(context.Background(), UpdateTrackerParams{ID: 1, Start: tc.start, Version: 1})
+
+			if tc.expected != nil {
+				g.Expect(errors.Is(err, tc.expected)).To(BeTrue(), "should refuse the start")
+				g.Expect(store.trackers[1].Start).To(Equal(at(10, 9)), "should keep the start")
+
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred(), "should store the tracker")
+			g.Expect(store.trackers[1].Start).To(Equal(tc.start), "should apply the start")
+		})
+	}
+}
+
+func Test_TrackerService_CreateTracker_overlaps(t *testing.T) {
+
+	at := func(hour int) time.Time {
+		return time.Date(2021, 5, 3, hour, 0, 0, 0, time.UTC)
+	}
+
+	accepted := models.NewTimeTracker(3, at(6), at(8), "accepted")
+	accepted.OverlapAccepted = true
+
+	type testExpectation struct {
+		rejected    []uint64
+		overlapping []uint64
+		accepted    bool
+	}
+
+	testCases := []struct {
+		description string
+		policy      OverlapPolicy
+		stored      []models.TimeTracker
+		start       time.Time
+		expected    testExpectation
+	}{
+		{
+			description: "when another tracker is running",
+			policy:      OverlapReject,
+			stored:      []models.TimeTracker{models.NewTimeTracker(1, at(9), time.Time{}, "running")},
+			start:       at(12),
+			expected:    testExpectation{overlapping: []uint64{}},
+		},
+		{
+			description: "when the start is moved back over a running tracker",
+			policy:      OverlapReject,
+			stored:      []models.TimeTracker{models.NewTimeTracker(1, at(9), time.Time{}, "running")},
+			start:       at(10),
+			expected:    testExpectation{rejected: []uint64{1}},
+		},
+		{
+			description: "when a tracker runs from after the start",
+			policy:      OverlapReject,
+			stored:      []models.TimeTracker{models.NewTimeTracker(1, at(9), time.Time{}, "running")},
+			start:       at(8),
+			expected:    testExpectation{rejected: []uint64{1}},
+		},
+		{
+			description: "when a stopped session covers the start",
+			policy:      OverlapReject,
+			stored:      []models.TimeTracker{models.NewTimeTracker(2, at(9), at(11), "stopped")},
+			start:       at(10),
+			expected:    testExpectation{rejected: []uint64{2}},
+		},
+		{
+			description: "when the overlapped session was stored overlapping others",
+			policy:      OverlapReject,
+			stored:      []models.TimeTracker{accepted},
+			start:       at(7),
+			expected:    testExpectation{rejected: []uint64{3}},
+		},
+		{
+			description: "when overlaps are only reported",
+			policy:      OverlapWarn,
+			stored:      []models.TimeTracker{models.NewTimeTracker(2, at(9), at(11), "stopped")},
+			start:       at(10),
+			expected:    testExpectation{overlapping: []uint64{2}, accepted: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			store := newMemoryStore(tc.stored...)
+			service := newTestTrackerService(store, tc.policy, at(12))
+
+			tracker, err := service.CreateTracker(context.Background(), CreateTrackerParams{Start: tc.start, Name: "new"})
+
+			if len(tc.expected.rejected) > 0 {
+				var overlapErr *OverlapError
+				g.Expect(errors.As(err, &overlapErr)).To(BeTrue(), "should reject the tracker")
+				g.Expect(overlapErr.IDs).To(Equal(tc.expected.rejected), "should list the overlapped trackers")
+
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred(), "should store the tracker")
+			g.Expect(tracker.Overlapping).To(Equal(tc.expected.overlapping), "should report the overlapped trackers")
+			g.Expect(store.trackers[tracker.ID].OverlapAccepted).To(Equal(tc.expected.accepted), "should flag accepted overlaps only")
+		})
+	}
+}
+
+func Test_TrackerService_UpdateTracker_stopOverlapping(t *testing.T) {
+
+	at := func(hour int) time.Time {
+		return time.Date(2021, 5, 3, hour, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		description string
+		end         time.Time
+		rejected    bool
+	}{
+		{
+			description: "when the tracker is stopped over the one started after it",
+			end:         at(11),
+			rejected:    true,
+		},
+		{
+			description: "when the tracker is stopped as the other one starts",
+			end:         at(10),
+			rejected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			store := newMemoryStore(models.NewTimeTracker(1, at(9), time.Time{}, "first"))
+
+			second, err := newTestTrackerService(store, OverlapReject, at(10)).
+				CreateTracker(context.Background(), CreateTrackerParams{Start: at(10), Name: "second"})
+			g.Expect(err).ToNot(HaveOccurred(), "should start a tracker while the first one runs")
+			g.Expect(store.trackers[second.ID].OverlapAccepted).To(BeFalse(), "should keep the tracker under the constraint")
+
+			_, err = newTestTrackerService(store, OverlapReject, at(12)).
+				UpdateTracker(context.Background(), UpdateTrackerParams{ID: 1, End: tc.end, Version: 1})
+
+			if tc.rejected {
+				var overlapErr *OverlapError
+				g.Expect(errors.As(err, &overlapErr)).To(BeTrue(), "should reject the stop")
+				g.Expect(overlapErr.IDs).To(Equal([]uint64{second.ID}), "should list the overlapped tracker")
+				g.Expect(store.trackers[1].IsRunning()).To(BeTrue(), "should leave the tracker running")
+
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred(), "should stop the tracker")
+			g.Expect(store.trackers[1].OverlapAccepted).To(BeFalse(), "should keep the tracker under the constraint")
+		})
+	}
+}
+
+// editedStore has every tracker edited by another writer right after it is
+// read.
+type editedStore struct {
+	*memoryStore
+}
+
+func (e editedStore) Get(ctx context.Context, id uint64) (models.TimeTracker, error) {
+	tracker, err := e.memoryStore.Get(ctx, id)
+
+	edited := tracker
+	edited.Meta.HydrateMeta(false, time.Time{}, time.Time{}, tracker.Meta.GetVersion()+1)
+	e.trackers[id] = edited
+
+	return tracker, err
+}
+
+func Test_TrackerService_DeleteTracker(t *testing.T) {
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 5, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	approved := models.NewTimesheet(at(5, 12), time.UTC)
+	approved.Status = models.TimesheetApproved
+
+	testCases := []struct {
+		description string
+		tracker     models.TimeTracker
+		version     uint32
+		edited      bool
+		expected    error
+	}{
+		{
+			description: "when the tracker is deleted at its version",
+			tracker:     models.NewTimeTracker(1, at(10, 9), at(10, 10), "a"),
+			version:     1,
+		},
+		{
+			description: "when the version is outdated",
+			tracker:     models.NewTimeTracker(1, at(10, 9), at(10, 10), "a"),
+			version:     2,
+			expected:    ErrWrongVersion,
+		},
+		{
+			description: "when the tracker is edited after its checks",
+			tracker:     models.NewTimeTracker(1, at(10, 9), at(10, 10), "a"),
+			edited:      true,
+			expected:    ErrWrongVersion,
+		},
+		{
+			description: "when the tracker is in a locked period",
+			tracker:     models.NewTimeTracker(1, at(5, 9), at(5, 10), "a"),
+			expected:    ErrPeriodLocked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			memory := newMemoryStore(tc.tracker)
+			var store TrackerStore = memory
+			if tc.edited {
+				store = editedStore{memory}
+			}
+			service := newTestTrackerService(store, OverlapReject, at(12, 9)).WithLocks(weekLocker(approved))
+
+			err := service.DeleteTracker(context.Background(), DeleteTrackerParams{ID: 1, Version: tc.version})
+
+			g.Expect(memory.transactions).To(Equal(1), "should check and delete in one transaction")
+
+			if tc.expected != nil {
+				g.Expect(errors.Is(err, tc.expected)).To(BeTrue(), "should refuse the delete")
+				g.Expect(memory.trackers[1].Meta.GetDeleted()).To(BeFalse(), "should keep the tracker")
+
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred(), "should delete the tracker")
+			g.Expect(memory.trackers[1].Meta.GetDeleted()).To(BeTrue(), "should delete the tracker")
+		})
+	}
+}
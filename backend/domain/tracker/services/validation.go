@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrValidation = errors.New("validation failed")
+
+const (
+	maxTags      = 20
+	maxTagLength = 64
+)
+
+// FieldError describes why a single field was rejected, Code is stable for
+// clients while Message is meant for humans.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every rule a tracker breaks, it matches
+// ErrValidation with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+
+	return "validation failed: " + strings.Join(messages, ", ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (e *ValidationError) add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+type ValidationRules struct {
+	// FutureTolerance is how far ahead of the server clock start and end may
+	// be, to absorb client clock drift.
+	FutureTolerance time.Duration
+	// MaxSessionLength bounds the time between start and end of a stopped
+	// tracker, zero disables it.
+	MaxSessionLength time.Duration
+	MaxNameLength    int
+}
+
+var DefaultValidationRules = ValidationRules{
+	FutureTolerance:  5 * time.Minute,
+	MaxSessionLength: 24 * time.Hour,
+	MaxNameLength:    255,
+}
+
+// Validate checks the tracker as it would be stored, it returns a
+// *ValidationError listing every broken rule.
+func (r ValidationRules) Validate(tracker models.TimeTracker, now time.Time) error {
+	problems := &ValidationError{}
+
+	r.validateName(problems, tracker.Name)
+	r.validateTimes(problems, tracker, now)
+
+	if utf8.RuneCountInString(tracker.Project) > r.MaxNameLength {
+		problems.add("project", "too_long", fmt.Sprintf("must be at most %d characters", r.MaxNameLength))
+	}
+
+	if len(tracker.Tags) > maxTags {
+		problems.add("tags", "too_many", fmt.Sprintf("at most %d tags are allowed", maxTags))
+	}
+	for _, tag := range tracker.Tags {
+		if strings.TrimSpace(tag) == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			problems.add("tags", "invalid", fmt.Sprintf("tags must be 1 to %d characters", maxTagLength))
+
+			break
+		}
+	}
+
+	if len(problems.Fields) > 0 {
+		return problems
+	}
+
+	return nil
+}
+
+func (r ValidationRules) validateName(problems *ValidationError, name string) {
+	if strings.TrimSpace(name) == "" {
+		problems.add("name", "required", "must not be empty")
+
+		return
+	}
+
+	if !utf8.ValidString(name) {
+		problems.add("name", "invalid_characters", "must be valid UTF-8")
+
+		return
+	}
+
+	if utf8.RuneCountInString(name) > r.MaxNameLength {
+		problems.add("name", "too_long", fmt.Sprintf("must be at most %d characters", r.MaxNameLength))
+	}
+
+	for _, char := range name {
+		if !unicode.IsPrint(char) {
+			problems.add("name", "invalid_characters", "must not contain control characters")
+
+			return
+		}
+	}
+}
+
+func (r ValidationRules) validateTimes(problems *ValidationError, tracker models.TimeTracker, now time.Time) {
+	latest := now.Add(r.FutureTolerance)
+
+	if tracker.Start.IsZero() {
+		problems.add("start", "required", "must be set")
+	} else if tracker.Start.After(latest) {
+		problems.add("start", "in_future", "must not be in the future")
+	}
+
+	if tracker.End.IsZero() || tracker.Start.IsZero() {
+		return
+	}
+
+	switch {
+	case tracker.End.After(latest):
+		problems.add("end", "in_future", "must not be in the future")
+	case tracker.End.Before(tracker.Start):
+		problems.add("end", "before_start", "must not be before start")
+	case r.MaxSessionLength > 0 && tracker.End.Sub(tracker.Start) > r.MaxSessionLength:
+		problems.add("end", "session_too_long", fmt.Sprintf("sessions must not exceed %s", r.MaxSessionLength))
+	}
+}
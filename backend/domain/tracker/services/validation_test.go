@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"pento/code-challenge/domain/tracker/models"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_ValidationRules_Validate(t *testing.T) {
+
+	now := time.Date(2021, 5, 3, 12, 0, 0, 0, time.UTC)
+
+	tracker := func(start, end time.Time, name string) models.TimeTracker {
+		return models.NewTimeTracker(1, start, end, name)
+	}
+
+	testCases := []struct {
+		description string
+		input       models.TimeTracker
+		expected    []string
+	}{
+		{
+			description: "when the tracker is running",
+			input:       tracker(now.Add(-time.Hour), time.Time{}, "code review"),
+			expected:    []string{},
+		},
+		{
+			description: "when the tracker is stopped",
+			input:       tracker(now.Add(-2*time.Hour), now.Add(-time.Hour), "code review"),
+			expected:    []string{},
+		},
+		{
+			description: "when the start is within the clock drift tolerance",
+			input:       tracker(now.Add(time.Minute), time.Time{}, "code review"),
+			expected:    []string{},
+		},
+		{
+			description: "when the end is before the start",
+			input:       tracker(now.Add(-time.Hour), now.Add(-2*time.Hour), "code review"),
+			expected:    []string{"end:before_start"},
+		},
+		{
+			description: "when the start is in the future",
+			input:       tracker(now.AddDate(2, 0, 0), time.Time{}, "code review"),
+			expected:    []string{"start:in_future"},
+		},
+		{
+			description: "when the session is too long",
+			input:       tracker(now.Add(-30*time.Hour), now.Add(-time.Hour), "code review"),
+			expected:    []string{"end:session_too_long"},
+		},
+		{
+			description: "when the name is blank and the start missing",
+			input:       tracker(time.Time{}, time.Time{}, "  "),
+			expected:    []string{"name:required", "start:required"},
+		},
+		{
+			description: "when the name is too long",
+			input:       tracker(now, time.Time{}, strings.Repeat("a", 256)),
+			expected:    []string{"name:too_long"},
+		},
+		{
+			description: "when the name holds control characters",
+			input:       tracker(now, time.Time{}, "code\x00review"),
+			expected:    []string{"name:invalid_characters"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := DefaultValidationRules.Validate(tc.input, now)
+
+			if len(tc.expected) == 0 {
+				g.Expect(err).ToNot(HaveOccurred())
+
+				return
+			}
+
+			g.Expect(errors.Is(err, ErrValidation)).To(BeTrue())
+
+			var validationErr *ValidationError
+			g.Expect(errors.As(err, &validationErr)).To(BeTrue())
+
+			fields := make([]string, 0, len(validationErr.Fields))
+			for _, field := range validationErr.Fields {
+				fields = append(fields, field.Field+":"+field.Code)
+			}
+			g.Expect(fields).To(Equal(tc.expected))
+		})
+	}
+}
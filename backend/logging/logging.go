@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+type loggerKey struct{}
+
+// New builds a logger writing in the given format (json or logfmt) at the
+// given level.
+func New(out io.Writer, format, level string) (*logrus.Logger, error) {
+	logger := logrus.New()
+	logger.SetOutput(out)
+
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("%w invalid log level", err)
+	}
+	logger.SetLevel(parsed)
+
+	switch format {
+	case FormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case FormatLogfmt:
+		logger.SetFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true})
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	return logger, nil
+}
+
+// WithLogger returns a context carrying the request scoped logger.
+func WithLogger(ctx context.Context, logger logrus.FieldLogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the request scoped logger, which carries the request
+// id, or fallback outside of a request.
+func FromContext(ctx context.Context, fallback logrus.FieldLogger) logrus.FieldLogger {
+	if logger, ok := ctx.Value(loggerKey{}).(logrus.FieldLogger); ok {
+		return logger
+	}
+
+	return fallback
+}
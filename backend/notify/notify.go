@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"pento/code-challenge/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogNotifier writes budget alerts to the log.
+type LogNotifier struct {
+	logger logrus.FieldLogger
+}
+
+func NewLogNotifier(logger logrus.FieldLogger) *LogNotifier {
+	return &LogNotifier{logger}
+}
+
+func (n LogNotifier) Notify(ctx context.Context, alert models.BudgetAlert) error {
+	logging.FromContext(ctx, n.logger).WithFields(logrus.Fields{
+		"budget_id": alert.Budget.ID,
+		"project":   alert.Budget.Project,
+		"kind":      alert.Kind,
+		"threshold": alert.Threshold,
+		"used":      alert.Used,
+	}).Warn("budget alert")
+
+	return nil
+}
+
+// WebhookNotifier posts budget alerts as JSON to a URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type alertPayload struct {
+	BudgetID    uint64            `json:"budget_id"`
+	Project     string            `json:"project"`
+	Period      string            `json:"period"`
+	PeriodStart *time.Time        `json:"period_start"`
+	Kind        models.BudgetKind `json:"kind"`
+	Threshold   int               `json:"threshold"`
+	UsedPercent float64           `json:"used_percent"`
+}
+
+func (n WebhookNotifier) Notify(ctx context.Context, alert models.BudgetAlert) error {
+	payload := alertPayload{
+		BudgetID:    alert.Budget.ID,
+		Project:     alert.Budget.Project,
+		Period:      string(alert.Budget.Period),
+		Kind:        alert.Kind,
+		Threshold:   alert.Threshold,
+		UsedPercent: alert.Used,
+	}
+	if !alert.PeriodStart.IsZero() {
+		payload.PeriodStart = &alert.PeriodStart
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := n.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("%w failed to post budget alert", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("budget alert webhook answered %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+// Multi delivers alerts to every notifier, it returns the first failure.
+type Multi []services.Notifier
+
+func (m Multi) Notify(ctx context.Context, alert models.BudgetAlert) error {
+	var first error
+
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, alert); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,210 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+
+	pgerr "github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+const budgetColumns = `id, project, period, hours, amount, hourly_rate, currency, thresholds,
+	created_at, updated_at, deleted, version`
+
+type BudgetStore struct {
+	pool *sql.DB
+}
+
+func NewBudgetStore(pool *sql.DB) *BudgetStore {
+	return &BudgetStore{pool}
+}
+
+func (s BudgetStore) Get(ctx context.Context, id uint64) (models.Budget, error) {
+	row := traced(s.pool).QueryRowContext(ctx, `
+		SELECT `+budgetColumns+`
+		FROM budget
+		WHERE id = $1 AND deleted = 'f'
+	`, id)
+
+	return s.scanOne(row)
+}
+
+func (s BudgetStore) GetByProject(ctx context.Context, project string) (models.Budget, error) {
+	row := traced(s.pool).QueryRowContext(ctx, `
+		SELECT `+budgetColumns+`
+		FROM budget
+		WHERE project = $1 AND deleted = 'f'
+	`, project)
+
+	return s.scanOne(row)
+}
+
+func (s BudgetStore) List(ctx context.Context) ([]models.Budget, error) {
+	rows, err := traced(s.pool).QueryContext(ctx, `
+		SELECT `+budgetColumns+`
+		FROM budget
+		WHERE deleted = 'f'
+		ORDER BY project ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to query budgets", err)
+	}
+	defer rows.Close()
+
+	budgets := make([]models.Budget, 0)
+	for rows.Next() {
+		budget, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		budgets = append(budgets, budget)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w rows returned error", err)
+	}
+
+	return budgets, nil
+}
+
+// Store creates the budget when version is zero and updates it otherwise, the
+// update only applies to the given version.
+func (s BudgetStore) Store(ctx context.Context, budget models.Budget, version uint32) (models.Budget, error) {
+	var row *sql.Row
+
+	if version == 0 {
+		row = traced(s.pool).QueryRowContext(ctx, `
+			INSERT INTO budget(project, period, hours, amount, hourly_rate, currency, thresholds)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING `+budgetColumns+`
+		`, budget.Project, string(budget.Period), budget.Hours.Seconds(), budget.Amount, budget.HourlyRate, budget.Currency, intArray(budget.Thresholds))
+	} else {
+		row = traced(s.pool).QueryRowContext(ctx, `
+			UPDATE budget
+			SET project = $1, period = $2, hours = $3, amount = $4, hourly_rate = $5, currency = $6, thresholds = $7,
+				version = $8, updated_at = NOW()
+			WHERE id = $9 AND version = $10 AND deleted = 'f'
+			RETURNING `+budgetColumns+`
+		`, budget.Project, string(budget.Period), budget.Hours.Seconds(), budget.Amount, budget.HourlyRate, budget.Currency, intArray(budget.Thresholds),
+			version+1, budget.ID, version)
+	}
+
+	stored, err := s.scan(row)
+	if err == sql.ErrNoRows {
+		return models.Budget{}, services.ErrWrongVersion
+	}
+
+	return stored, err
+}
+
+func (s BudgetStore) Delete(ctx context.Context, id uint64) error {
+	_, err := traced(s.pool).ExecContext(ctx, `
+		UPDATE budget
+		SET deleted = 't', updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("%w failed to set to deleted", err)
+	}
+
+	return nil
+}
+
+func (s BudgetStore) AlertRecorded(ctx context.Context, alert models.BudgetAlert) (bool, error) {
+	var recorded bool
+
+	row := traced(s.pool).QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM budget_alert
+			WHERE budget_id = $1 AND period_start = $2 AND kind = $3 AND threshold = $4
+		)
+	`, alert.Budget.ID, alert.PeriodStart, string(alert.Kind), alert.Threshold)
+	if err := row.Scan(&recorded); err != nil {
+		return false, fmt.Errorf("%w failed to look up budget alert", err)
+	}
+
+	return recorded, nil
+}
+
+func (s BudgetStore) RecordAlert(ctx context.Context, alert models.BudgetAlert) (bool, error) {
+	result, err := traced(s.pool).ExecContext(ctx, `
+		INSERT INTO budget_alert(budget_id, period_start, kind, threshold)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, alert.Budget.ID, alert.PeriodStart, string(alert.Kind), alert.Threshold)
+	if err != nil {
+		return false, fmt.Errorf("%w failed to record budget alert", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%w failed to read affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// scanOne reports a missing budget as a zero value.
+func (s BudgetStore) scanOne(row *sql.Row) (models.Budget, error) {
+	budget, err := s.scan(row)
+	if err == sql.ErrNoRows {
+		return models.Budget{}, nil
+	}
+
+	return budget, err
+}
+
+func (s BudgetStore) scan(row rowScanner) (models.Budget, error) {
+	var (
+		budget     models.Budget
+		period     string
+		hours      float64
+		thresholds pgtype.Int4Array
+		createdAt  time.Time
+		updatedAt  time.Time
+		deleted    bool
+		version    uint32
+	)
+
+	err := row.Scan(&budget.ID, &budget.Project, &period, &hours, &budget.Amount, &budget.HourlyRate, &budget.Currency,
+		&thresholds, &createdAt, &updatedAt, &deleted, &version)
+	if err != nil {
+		if pgErr, ok := err.(pgx.PgError); ok && pgErr.Code == pgerr.UniqueViolation {
+			return models.Budget{}, services.ErrBudgetExists
+		}
+
+		return models.Budget{}, err
+	}
+
+	budget.Period = models.BudgetPeriod(period)
+	budget.Hours = time.Duration(hours * float64(time.Second))
+	budget.Thresholds = make([]int, 0)
+	if thresholds.Status == pgtype.Present {
+		if err := thresholds.AssignTo(&budget.Thresholds); err != nil {
+			return models.Budget{}, fmt.Errorf("%w failed to read thresholds", err)
+		}
+	}
+
+	budget.Meta.HydrateMeta(deleted, createdAt, updatedAt, version)
+
+	return budget, nil
+}
+
+func intArray(values []int) pgtype.Int4Array {
+	var array pgtype.Int4Array
+
+	if values == nil {
+		values = make([]int, 0)
+	}
+	array.Set(values)
+
+	return array
+}
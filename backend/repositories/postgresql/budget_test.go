@@ -0,0 +1,100 @@
+//go:build integrationdb
+// +build integrationdb
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	_ "github.com/jackc/pgx/stdlib"
+)
+
+func initBudgetStore() (*BudgetStore, error) {
+
+	connString := fmt.Sprintf("host=localhost port=5434 user=postgres password=postgres dbname=postgres sslmode=disable")
+
+	pool, err := sql.Open("pgx", connString)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = pool.Exec(`delete from budget_alert;
+		delete from budget;
+		ALTER SEQUENCE budget_id_seq RESTART WITH 1;
+		INSERT INTO budget(project, period, hours, amount, hourly_rate, currency, thresholds)
+		VALUES ('client_a', 'monthly', 36000, 0, 0, '', '{80,100}');
+		`)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewBudgetStore(pool), nil
+}
+
+func Test_BudgetStore_Store(t *testing.T) {
+	g := NewWithT(t)
+
+	store, _ := initBudgetStore()
+	ctx := context.Background()
+
+	existing, err := store.GetByProject(ctx, "client_a")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(existing.ID).To(Equal(uint64(1)))
+	g.Expect(existing.Hours).To(Equal(10 * time.Hour))
+	g.Expect(existing.Thresholds).To(Equal([]int{80, 100}))
+
+	duplicate := models.NewBudget(0, "client_a", models.BudgetTotal)
+	duplicate.Hours = time.Hour
+	_, err = store.Store(ctx, duplicate, 0)
+	g.Expect(errors.Is(err, services.ErrBudgetExists)).To(BeTrue())
+
+	existing.Amount = 100000
+	existing.HourlyRate = 10000
+	existing.Currency = "EUR"
+	updated, err := store.Store(ctx, existing, 1)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(updated.Amount).To(Equal(int64(100000)))
+	g.Expect(updated.Meta.GetVersion()).To(Equal(uint32(2)))
+
+	_, err = store.Store(ctx, existing, 1)
+	g.Expect(errors.Is(err, services.ErrWrongVersion)).To(BeTrue())
+}
+
+func Test_BudgetStore_RecordAlert(t *testing.T) {
+	g := NewWithT(t)
+
+	store, _ := initBudgetStore()
+	ctx := context.Background()
+
+	budget, err := store.Get(ctx, 1)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	alert := models.BudgetAlert{
+		Budget:      budget,
+		Kind:        models.BudgetHours,
+		Threshold:   80,
+		PeriodStart: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	recorded, err := store.RecordAlert(ctx, alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(recorded).To(BeTrue())
+
+	recorded, err = store.RecordAlert(ctx, alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(recorded).To(BeFalse())
+
+	alert.PeriodStart = alert.PeriodStart.AddDate(0, 1, 0)
+	recorded, err = store.RecordAlert(ctx, alert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(recorded).To(BeTrue())
+}
@@ -0,0 +1,100 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+
+	"github.com/jackc/pgx/pgtype"
+)
+
+// GoalStore keeps the goals in a single row, the application has no users.
+type GoalStore struct {
+	pool *sql.DB
+}
+
+func NewGoalStore(pool *sql.DB) *GoalStore {
+	return &GoalStore{pool}
+}
+
+func (s GoalStore) Get(ctx context.Context) (models.Goals, error) {
+	row := traced(s.pool).QueryRowContext(ctx, `
+		SELECT daily, weekly, created_at, updated_at, version
+		FROM goal
+		WHERE id = 1
+	`)
+
+	goals, err := s.scan(row)
+	if err == sql.ErrNoRows {
+		return models.NewGoals(), nil
+	}
+
+	return goals, err
+}
+
+// Store creates the goals when version is zero and updates them otherwise,
+// the update only applies to the given version.
+func (s GoalStore) Store(ctx context.Context, goals models.Goals, version uint32) (models.Goals, error) {
+	daily := make([]int, 0, len(goals.Daily))
+	for _, target := range goals.Daily {
+		daily = append(daily, int(target/time.Second))
+	}
+
+	var row *sql.Row
+
+	if version == 0 {
+		row = traced(s.pool).QueryRowContext(ctx, `
+			INSERT INTO goal(id, daily, weekly)
+			VALUES (1, $1, $2)
+			ON CONFLICT (id) DO NOTHING
+			RETURNING daily, weekly, created_at, updated_at, version
+		`, intArray(daily), int(goals.Weekly/time.Second))
+	} else {
+		row = traced(s.pool).QueryRowContext(ctx, `
+			UPDATE goal
+			SET daily = $1, weekly = $2, version = $3, updated_at = NOW()
+			WHERE id = 1 AND version = $4
+			RETURNING daily, weekly, created_at, updated_at, version
+		`, intArray(daily), int(goals.Weekly/time.Second), version+1, version)
+	}
+
+	stored, err := s.scan(row)
+	if err == sql.ErrNoRows {
+		return models.Goals{}, services.ErrWrongVersion
+	}
+
+	return stored, err
+}
+
+func (s GoalStore) scan(row *sql.Row) (models.Goals, error) {
+	var (
+		goals     = models.NewGoals()
+		daily     pgtype.Int4Array
+		weekly    int64
+		createdAt time.Time
+		updatedAt time.Time
+		version   uint32
+	)
+
+	if err := row.Scan(&daily, &weekly, &createdAt, &updatedAt, &version); err != nil {
+		return models.Goals{}, err
+	}
+
+	seconds := make([]int, 0, len(goals.Daily))
+	if err := daily.AssignTo(&seconds); err != nil {
+		return models.Goals{}, fmt.Errorf("%w failed to read daily goals", err)
+	}
+
+	for weekday := 0; weekday < len(goals.Daily) && weekday < len(seconds); weekday++ {
+		goals.Daily[weekday] = time.Duration(seconds[weekday]) * time.Second
+	}
+	goals.Weekly = time.Duration(weekly) * time.Second
+
+	goals.Meta.HydrateMeta(false, createdAt, updatedAt, version)
+
+	return goals, nil
+}
@@ -0,0 +1,64 @@
+//go:build integrationdb
+// +build integrationdb
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/services"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	_ "github.com/jackc/pgx/stdlib"
+)
+
+func initGoalStore() (*GoalStore, error) {
+
+	connString := fmt.Sprintf("host=localhost port=5434 user=postgres password=postgres dbname=postgres sslmode=disable")
+
+	pool, err := sql.Open("pgx", connString)
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err = pool.Exec(`delete from goal;`); err != nil {
+		panic(err)
+	}
+
+	return NewGoalStore(pool), nil
+}
+
+func Test_GoalStore_Store(t *testing.T) {
+	g := NewWithT(t)
+
+	store, _ := initGoalStore()
+	ctx := context.Background()
+
+	goals, err := store.Get(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(goals.Meta.GetVersion()).To(Equal(uint32(0)))
+
+	goals.Daily[time.Monday] = 8 * time.Hour
+	goals.Weekly = 40 * time.Hour
+
+	created, err := store.Store(ctx, goals, 0)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(created.Daily[time.Monday]).To(Equal(8 * time.Hour))
+	g.Expect(created.Daily[time.Sunday]).To(Equal(time.Duration(0)))
+	g.Expect(created.Weekly).To(Equal(40 * time.Hour))
+	g.Expect(created.Meta.GetVersion()).To(Equal(uint32(1)))
+
+	_, err = store.Store(ctx, goals, 0)
+	g.Expect(errors.Is(err, services.ErrWrongVersion)).To(BeTrue())
+
+	created.Weekly = 32 * time.Hour
+	updated, err := store.Store(ctx, created, 1)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(updated.Weekly).To(Equal(32 * time.Hour))
+	g.Expect(updated.Meta.GetVersion()).To(Equal(uint32(2)))
+}
@@ -0,0 +1,46 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SchemaVersion is the schema version this build expects, it has to match the
+// latest row of the schema_version table.
+const SchemaVersion = 12
+
+type HealthStore struct {
+	pool *sql.DB
+}
+
+func NewHealthStore(pool *sql.DB) *HealthStore {
+	return &HealthStore{pool}
+}
+
+func (s HealthStore) Ping(ctx context.Context) error {
+	return s.pool.PingContext(ctx)
+}
+
+func (s HealthStore) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+
+	row := s.pool.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(version), 0)
+		FROM schema_version
+	`)
+
+	if err := row.Scan(&version); err != nil {
+		return 0, fmt.Errorf("%w failed to read schema version", err)
+	}
+
+	return version, nil
+}
+
+func (s HealthStore) ExpectedSchemaVersion() int {
+	return SchemaVersion
+}
+
+func (s HealthStore) Stats() sql.DBStats {
+	return s.pool.Stats()
+}
@@ -0,0 +1,102 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pento/code-challenge/domain/idempotency/models"
+)
+
+type IdempotencyStore struct {
+	pool *sql.DB
+	ttl  time.Duration
+}
+
+func NewIdempotencyStore(pool *sql.DB, ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{pool, ttl}
+}
+
+// Reserve claims the key for the given request hash. When the key is already
+// taken it returns the existing record and false.
+func (s IdempotencyStore) Reserve(ctx context.Context, key, requestHash string) (models.Record, bool, error) {
+	_, err := s.pool.ExecContext(ctx, `
+		DELETE FROM idempotency_key
+		WHERE key = $1 AND created_at < NOW() - make_interval(secs => $2)
+	`, key, s.ttl.Seconds())
+	if err != nil {
+		return models.Record{}, false, fmt.Errorf("%w failed to expire idempotency key", err)
+	}
+
+	row := s.pool.QueryRowContext(ctx, `
+		INSERT INTO idempotency_key(key, request_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (key) DO NOTHING
+		RETURNING key, request_hash, status_code, content_type, body, completed, created_at
+	`, key, requestHash)
+
+	record, err := s.scan(row)
+	if err == nil {
+		return record, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return models.Record{}, false, err
+	}
+
+	row = s.pool.QueryRowContext(ctx, `
+		SELECT key, request_hash, status_code, content_type, body, completed, created_at
+		FROM idempotency_key
+		WHERE key = $1
+	`, key)
+
+	record, err = s.scan(row)
+	if err != nil {
+		return models.Record{}, false, err
+	}
+
+	return record, false, nil
+}
+
+func (s IdempotencyStore) Complete(ctx context.Context, record models.Record) error {
+	_, err := s.pool.ExecContext(ctx, `
+		UPDATE idempotency_key
+		SET status_code = $1, content_type = $2, body = $3, completed = 't'
+		WHERE key = $4
+	`, record.StatusCode, record.ContentType, record.Body, record.Key)
+	if err != nil {
+		return fmt.Errorf("%w failed to complete idempotency key", err)
+	}
+
+	return nil
+}
+
+func (s IdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.pool.ExecContext(ctx, `
+		DELETE FROM idempotency_key
+		WHERE key = $1
+	`, key)
+	if err != nil {
+		return fmt.Errorf("%w failed to release idempotency key", err)
+	}
+
+	return nil
+}
+
+func (s IdempotencyStore) scan(row *sql.Row) (models.Record, error) {
+	var record models.Record
+
+	err := row.Scan(
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.Body,
+		&record.Completed,
+		&record.CreatedAt)
+	if err != nil {
+		return models.Record{}, err
+	}
+
+	return record, nil
+}
@@ -0,0 +1,139 @@
+//go:build integrationdb
+// +build integrationdb
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	_ "github.com/jackc/pgx/stdlib"
+
+	"pento/code-challenge/domain/idempotency/models"
+)
+
+func initIdempotencyStore() (*IdempotencyStore, error) {
+
+	connString := fmt.Sprintf("host=localhost port=5434 user=postgres password=postgres dbname=postgres sslmode=disable")
+
+	pool, err := sql.Open("pgx", connString)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = pool.Exec(`delete from idempotency_key;
+		INSERT INTO idempotency_key(key, request_hash, status_code, content_type, body, completed)
+		VALUES ('completed_key', 'hash_1', 201, 'application/json', '{}', 't'),
+			('pending_key', 'hash_2', 0, '', NULL, 'f');
+		`)
+	if err != nil {
+		panic(err)
+	}
+
+	store := NewIdempotencyStore(pool, time.Hour)
+
+	return store, nil
+}
+
+func Test_IdempotencyStore_Reserve(t *testing.T) {
+
+	type testInput struct {
+		key  string
+		hash string
+	}
+
+	type testExpectation struct {
+		err      error
+		reserved bool
+		result   models.Record
+	}
+
+	testCases := []struct {
+		description string
+		input       testInput
+		expected    testExpectation
+	}{
+		{
+			description: "when reserving a new key",
+			input: testInput{
+				key:  "new_key",
+				hash: "hash_3",
+			},
+			expected: testExpectation{
+				reserved: true,
+				result: models.Record{
+					Key:         "new_key",
+					RequestHash: "hash_3",
+				},
+			},
+		},
+		{
+			description: "when reserving a completed key",
+			input: testInput{
+				key:  "completed_key",
+				hash: "hash_3",
+			},
+			expected: testExpectation{
+				reserved: false,
+				result: models.Record{
+					Key:         "completed_key",
+					RequestHash: "hash_1",
+					StatusCode:  http.StatusCreated,
+					ContentType: "application/json",
+					Body:        []byte("{}"),
+					Completed:   true,
+				},
+			},
+		},
+		{
+			description: "when reserving a key still in flight",
+			input: testInput{
+				key:  "pending_key",
+				hash: "hash_2",
+			},
+			expected: testExpectation{
+				reserved: false,
+				result: models.Record{
+					Key:         "pending_key",
+					RequestHash: "hash_2",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var ctx = context.TODO()
+			defer ctx.Done()
+
+			repo, err := initIdempotencyStore()
+			defer repo.pool.Close()
+			g.Expect(err).ToNot(HaveOccurred(), "should not return an error setting up the repository")
+
+			result, reserved, err := repo.Reserve(ctx, tc.input.key, tc.input.hash)
+
+			if tc.expected.err != nil {
+				g.Expect(err).To(Equal(tc.expected.err), "should return the expected error")
+			} else {
+				g.Expect(err).ToNot(HaveOccurred(), "should not return an error")
+				g.Expect(reserved).To(Equal(tc.expected.reserved), "should be the same reservation outcome")
+				g.Expect(result.Key).To(Equal(tc.expected.result.Key), "should be the same key")
+				g.Expect(result.RequestHash).To(Equal(tc.expected.result.RequestHash), "should be the same request hash")
+				g.Expect(result.StatusCode).To(Equal(tc.expected.result.StatusCode), "should be the same status code")
+				g.Expect(result.Completed).To(Equal(tc.expected.result.Completed), "should be the same completed flag")
+				if tc.expected.result.Completed {
+					g.Expect(result.ContentType).To(Equal(tc.expected.result.ContentType), "should be the same content type")
+					g.Expect(result.Body).To(Equal(tc.expected.result.Body), "should be the same body")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+)
+
+const blockColumns = `id, started, ended, project, note, created_at, updated_at, deleted, version`
+
+type PlanStore struct {
+	pool *sql.DB
+}
+
+func NewPlanStore(pool *sql.DB) *PlanStore {
+	return &PlanStore{pool}
+}
+
+func (s PlanStore) Get(ctx context.Context, id uint64) (models.PlannedBlock, error) {
+	row := traced(s.pool).QueryRowContext(ctx, `
+		SELECT `+blockColumns+`
+		FROM planned_block
+		WHERE id = $1 AND deleted = 'f'
+	`, id)
+
+	block, err := s.scan(row)
+	if err == sql.ErrNoRows {
+		return models.PlannedBlock{}, nil
+	}
+
+	return block, err
+}
+
+func (s PlanStore) List(ctx context.Context, start, end time.Time) ([]models.PlannedBlock, error) {
+	rows, err := traced(s.pool).QueryContext(ctx, `
+		SELECT `+blockColumns+`
+		FROM planned_block
+		WHERE deleted = 'f' AND ($1::TIMESTAMP IS NULL OR started >= $1) AND ($2::TIMESTAMP IS NULL OR started < $2)
+		ORDER BY started ASC
+	`, nullTime(start), nullTime(end))
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to query planned blocks", err)
+	}
+	defer rows.Close()
+
+	blocks := make([]models.PlannedBlock, 0)
+	for rows.Next() {
+		block, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w rows returned error", err)
+	}
+
+	return blocks, nil
+}
+
+// Store creates the block when version is zero and updates it otherwise, the
+// update only applies to the given version.
+func (s PlanStore) Store(ctx context.Context, block models.PlannedBlock, version uint32) (models.PlannedBlock, error) {
+	if version == 0 {
+		row := traced(s.pool).QueryRowContext(ctx, `
+			INSERT INTO planned_block(started, ended, project, note)
+			VALUES ($1, $2, $3, $4)
+			RETURNING `+blockColumns+`
+		`, block.Start, block.End, block.Project, block.Note)
+
+		return s.scan(row)
+	}
+
+	row := traced(s.pool).QueryRowContext(ctx, `
+		UPDATE planned_block
+		SET started = $1, ended = $2, project = $3, note = $4, version = $5, updated_at = NOW()
+		WHERE id = $6 AND version = $7 AND deleted = 'f'
+		RETURNING `+blockColumns+`
+	`, block.Start, block.End, block.Project, block.Note, version+1, block.ID, version)
+
+	stored, err := s.scan(row)
+	if err == sql.ErrNoRows {
+		return models.PlannedBlock{}, services.ErrWrongVersion
+	}
+
+	return stored, err
+}
+
+func (s PlanStore) Delete(ctx context.Context, id uint64) error {
+	_, err := traced(s.pool).ExecContext(ctx, `
+		UPDATE planned_block
+		SET deleted = 't', updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("%w failed to set to deleted", err)
+	}
+
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (s PlanStore) scan(row rowScanner) (models.PlannedBlock, error) {
+	var (
+		block     models.PlannedBlock
+		createdAt time.Time
+		updatedAt time.Time
+		deleted   bool
+		version   uint32
+	)
+
+	err := row.Scan(&block.ID, &block.Start, &block.End, &block.Project, &block.Note, &createdAt, &updatedAt, &deleted, &version)
+	if err != nil {
+		return models.PlannedBlock{}, err
+	}
+
+	block.Meta.HydrateMeta(deleted, createdAt, updatedAt, version)
+
+	return block, nil
+}
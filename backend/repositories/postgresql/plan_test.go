@@ -0,0 +1,116 @@
+//go:build integrationdb
+// +build integrationdb
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	_ "github.com/jackc/pgx/stdlib"
+)
+
+func initPlanStore() (*PlanStore, error) {
+
+	connString := fmt.Sprintf("host=localhost port=5434 user=postgres password=postgres dbname=postgres sslmode=disable")
+
+	pool, err := sql.Open("pgx", connString)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = pool.Exec(`delete from planned_block;
+		ALTER SEQUENCE planned_block_id_seq RESTART WITH 1;
+		INSERT INTO planned_block(started, ended, project, note)
+		VALUES ('2021-05-04 09:00:00', '2021-05-04 12:00:00', 'client_a', 'sprint planning'),
+			('2021-05-05 13:00:00', '2021-05-05 17:00:00', 'client_b', '');
+		`)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewPlanStore(pool), nil
+}
+
+func Test_PlanStore_List(t *testing.T) {
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 5, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		description string
+		start       time.Time
+		end         time.Time
+		expected    []string
+	}{
+		{
+			description: "when listing every block",
+			expected:    []string{"client_a", "client_b"},
+		},
+		{
+			description: "when listing the blocks of a day",
+			start:       at(5, 0),
+			end:         at(6, 0),
+			expected:    []string{"client_b"},
+		},
+		{
+			description: "when no block starts in the period",
+			start:       at(4, 10),
+			end:         at(5, 13),
+			expected:    []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			store, _ := initPlanStore()
+
+			blocks, err := store.List(context.Background(), tc.start, tc.end)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			projects := make([]string, 0, len(blocks))
+			for _, block := range blocks {
+				projects = append(projects, block.Project)
+			}
+			g.Expect(projects).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_PlanStore_Store(t *testing.T) {
+	g := NewWithT(t)
+
+	store, _ := initPlanStore()
+	ctx := context.Background()
+
+	created, err := store.Store(ctx, models.NewPlannedBlock(0, time.Date(2021, 5, 6, 9, 0, 0, 0, time.UTC), time.Date(2021, 5, 6, 10, 0, 0, 0, time.UTC), "client_c"), 0)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(created.ID).To(Equal(uint64(3)))
+	g.Expect(created.Meta.GetVersion()).To(Equal(uint32(1)))
+
+	created.Note = "moved"
+	updated, err := store.Store(ctx, created, 1)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(updated.Note).To(Equal("moved"))
+	g.Expect(updated.Meta.GetVersion()).To(Equal(uint32(2)))
+
+	_, err = store.Store(ctx, created, 1)
+	g.Expect(errors.Is(err, services.ErrWrongVersion)).To(BeTrue())
+
+	g.Expect(store.Delete(ctx, created.ID)).To(Succeed())
+
+	deleted, err := store.Get(ctx, created.ID)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(deleted.IsZero()).To(BeTrue())
+}
@@ -0,0 +1,242 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+
+	"github.com/jackc/pgx/pgtype"
+)
+
+const pomodoroColumns = `id, name, project, tags, focus, short_break, long_break, long_break_every, rounds,
+	started, ended, tracker_id, created_at, updated_at, version`
+
+const phaseColumns = `pomodoro_id, round, kind, started, ended, completed, tracker_id`
+
+type PomodoroStore struct {
+	pool *sql.DB
+}
+
+func NewPomodoroStore(pool *sql.DB) *PomodoroStore {
+	return &PomodoroStore{pool}
+}
+
+func (s PomodoroStore) Get(ctx context.Context, id uint64) (models.Pomodoro, error) {
+	row := traced(s.pool).QueryRowContext(ctx, `
+		SELECT `+pomodoroColumns+`
+		FROM pomodoro
+		WHERE id = $1
+	`, id)
+
+	pomodoro, err := s.scan(row)
+	if err == sql.ErrNoRows {
+		return models.Pomodoro{}, nil
+	}
+	if err != nil {
+		return models.Pomodoro{}, err
+	}
+
+	return s.withPhases(ctx, pomodoro)
+}
+
+func (s PomodoroStore) ListActive(ctx context.Context) ([]models.Pomodoro, error) {
+	rows, err := traced(s.pool).QueryContext(ctx, `
+		SELECT `+pomodoroColumns+`
+		FROM pomodoro
+		WHERE ended IS NULL
+		ORDER BY started ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to query pomodoros", err)
+	}
+	defer rows.Close()
+
+	pomodoros := make([]models.Pomodoro, 0)
+	for rows.Next() {
+		pomodoro, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		pomodoros = append(pomodoros, pomodoro)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w rows returned error", err)
+	}
+
+	for i := range pomodoros {
+		if pomodoros[i], err = s.withPhases(ctx, pomodoros[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return pomodoros, nil
+}
+
+// Store creates the pomodoro when version is zero and updates it otherwise,
+// the update only applies to the given version. Phases are written by
+// RecordPhase and left untouched.
+func (s PomodoroStore) Store(ctx context.Context, pomodoro models.Pomodoro, version uint32) (models.Pomodoro, error) {
+	var row *sql.Row
+
+	if version == 0 {
+		row = traced(s.pool).QueryRowContext(ctx, `
+			INSERT INTO pomodoro(name, project, tags, focus, short_break, long_break, long_break_every, rounds,
+				started, ended, tracker_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING `+pomodoroColumns+`
+		`, pomodoro.Name, nullString(pomodoro.Project), textArray(pomodoro.Tags),
+			seconds(pomodoro.Schedule.Focus), seconds(pomodoro.Schedule.ShortBreak), seconds(pomodoro.Schedule.LongBreak),
+			pomodoro.Schedule.LongBreakEvery, pomodoro.Schedule.Rounds,
+			pomodoro.Start, nullTime(pomodoro.End), nullID(pomodoro.TrackerID))
+	} else {
+		row = traced(s.pool).QueryRowContext(ctx, `
+			UPDATE pomodoro
+			SET ended = $1, tracker_id = $2, version = $3, updated_at = NOW()
+			WHERE id = $4 AND version = $5
+			RETURNING `+pomodoroColumns+`
+		`, nullTime(pomodoro.End), nullID(pomodoro.TrackerID), version+1, pomodoro.ID, version)
+	}
+
+	stored, err := s.scan(row)
+	if err == sql.ErrNoRows {
+		return models.Pomodoro{}, services.ErrWrongVersion
+	}
+	if err != nil {
+		return models.Pomodoro{}, err
+	}
+
+	return s.withPhases(ctx, stored)
+}
+
+func (s PomodoroStore) RecordPhase(ctx context.Context, pomodoroID uint64, phase models.PomodoroPhase) error {
+	_, err := traced(s.pool).ExecContext(ctx, `
+		INSERT INTO pomodoro_phase(`+phaseColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT DO NOTHING
+	`, pomodoroID, phase.Round, string(phase.Kind), phase.Start, phase.End, phase.Completed, nullID(phase.TrackerID))
+	if err != nil {
+		return fmt.Errorf("%w failed to record pomodoro phase", err)
+	}
+
+	return nil
+}
+
+func (s PomodoroStore) ListPhases(ctx context.Context, start, end time.Time) ([]models.PomodoroPhase, error) {
+	rows, err := traced(s.pool).QueryContext(ctx, `
+		SELECT `+phaseColumns+`
+		FROM pomodoro_phase
+		WHERE ($1::TIMESTAMP IS NULL OR started >= $1) AND ($2::TIMESTAMP IS NULL OR started < $2)
+		ORDER BY started ASC
+	`, nullTime(start), nullTime(end))
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to query pomodoro phases", err)
+	}
+	defer rows.Close()
+
+	return s.scanPhases(rows)
+}
+
+func (s PomodoroStore) withPhases(ctx context.Context, pomodoro models.Pomodoro) (models.Pomodoro, error) {
+	rows, err := traced(s.pool).QueryContext(ctx, `
+		SELECT `+phaseColumns+`
+		FROM pomodoro_phase
+		WHERE pomodoro_id = $1
+		ORDER BY started ASC, round ASC
+	`, pomodoro.ID)
+	if err != nil {
+		return models.Pomodoro{}, fmt.Errorf("%w failed to query pomodoro phases", err)
+	}
+	defer rows.Close()
+
+	pomodoro.Phases, err = s.scanPhases(rows)
+	if err != nil {
+		return models.Pomodoro{}, err
+	}
+
+	return pomodoro, nil
+}
+
+func (s PomodoroStore) scanPhases(rows *sql.Rows) ([]models.PomodoroPhase, error) {
+	phases := make([]models.PomodoroPhase, 0)
+
+	for rows.Next() {
+		var (
+			phase      models.PomodoroPhase
+			pomodoroID uint64
+			kind       string
+			trackerID  sql.NullInt64
+		)
+
+		err := rows.Scan(&pomodoroID, &phase.Round, &kind, &phase.Start, &phase.End, &phase.Completed, &trackerID)
+		if err != nil {
+			return nil, err
+		}
+
+		phase.Kind = models.PomodoroPhaseKind(kind)
+		phase.TrackerID = uint64(trackerID.Int64)
+
+		phases = append(phases, phase)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w rows returned error", err)
+	}
+
+	return phases, nil
+}
+
+func (s PomodoroStore) scan(row rowScanner) (models.Pomodoro, error) {
+	var (
+		pomodoro   models.Pomodoro
+		project    sql.NullString
+		tags       pgtype.TextArray
+		focus      int64
+		shortBreak int64
+		longBreak  int64
+		end        sql.NullTime
+		trackerID  sql.NullInt64
+		createdAt  time.Time
+		updatedAt  time.Time
+		version    uint32
+	)
+
+	err := row.Scan(&pomodoro.ID, &pomodoro.Name, &project, &tags, &focus, &shortBreak, &longBreak,
+		&pomodoro.Schedule.LongBreakEvery, &pomodoro.Schedule.Rounds, &pomodoro.Start, &end, &trackerID,
+		&createdAt, &updatedAt, &version)
+	if err != nil {
+		return models.Pomodoro{}, err
+	}
+
+	pomodoro.Project = project.String
+	pomodoro.Tags = make([]string, 0)
+	if tags.Status == pgtype.Present {
+		if err := tags.AssignTo(&pomodoro.Tags); err != nil {
+			return models.Pomodoro{}, fmt.Errorf("%w failed to read tags", err)
+		}
+	}
+
+	pomodoro.Schedule.Focus = time.Duration(focus) * time.Second
+	pomodoro.Schedule.ShortBreak = time.Duration(shortBreak) * time.Second
+	pomodoro.Schedule.LongBreak = time.Duration(longBreak) * time.Second
+	pomodoro.End = end.Time
+	pomodoro.TrackerID = uint64(trackerID.Int64)
+	pomodoro.Phases = make([]models.PomodoroPhase, 0)
+
+	pomodoro.Meta.HydrateMeta(false, createdAt, updatedAt, version)
+
+	return pomodoro, nil
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+func nullID(id uint64) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
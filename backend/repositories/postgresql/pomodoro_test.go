@@ -0,0 +1,95 @@
+//go:build integrationdb
+// +build integrationdb
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	_ "github.com/jackc/pgx/stdlib"
+)
+
+func initPomodoroStore() (*PomodoroStore, error) {
+
+	connString := fmt.Sprintf("host=localhost port=5434 user=postgres password=postgres dbname=postgres sslmode=disable")
+
+	pool, err := sql.Open("pgx", connString)
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err = pool.Exec(`delete from pomodoro_phase; delete from pomodoro;`); err != nil {
+		panic(err)
+	}
+
+	return NewPomodoroStore(pool), nil
+}
+
+func Test_PomodoroStore_Store(t *testing.T) {
+	g := NewWithT(t)
+
+	store, _ := initPomodoroStore()
+	ctx := context.Background()
+
+	start := time.Date(2021, 5, 3, 9, 0, 0, 0, time.UTC)
+	pomodoro := models.NewPomodoro(0, "deep work", models.PomodoroSchedule{
+		Focus:          25 * time.Minute,
+		ShortBreak:     5 * time.Minute,
+		LongBreak:      15 * time.Minute,
+		LongBreakEvery: 4,
+		Rounds:         4,
+	}, start)
+	pomodoro.Project = "tracker"
+	pomodoro.Tags = []string{"focus"}
+	pomodoro.TrackerID = 12
+
+	created, err := store.Store(ctx, pomodoro, 0)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(created.ID).ToNot(BeZero())
+	g.Expect(created.Schedule).To(Equal(pomodoro.Schedule))
+	g.Expect(created.Tags).To(Equal([]string{"focus"}))
+	g.Expect(created.TrackerID).To(Equal(uint64(12)))
+	g.Expect(created.Meta.GetVersion()).To(Equal(uint32(1)))
+
+	active, err := store.ListActive(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(active).To(HaveLen(1))
+
+	phase := models.PomodoroPhase{Kind: models.PhaseFocus, Round: 1, Start: start, End: start.Add(25 * time.Minute), TrackerID: 12, Completed: true}
+	g.Expect(store.RecordPhase(ctx, created.ID, phase)).To(Succeed())
+	g.Expect(store.RecordPhase(ctx, created.ID, phase)).To(Succeed())
+
+	created.End = start.Add(25 * time.Minute)
+	created.TrackerID = 0
+
+	updated, err := store.Store(ctx, created, 1)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(updated.End.Equal(created.End)).To(BeTrue())
+	g.Expect(updated.TrackerID).To(BeZero())
+	g.Expect(updated.Phases).To(HaveLen(1))
+	g.Expect(updated.Phases[0].Completed).To(BeTrue())
+
+	_, err = store.Store(ctx, created, 1)
+	g.Expect(errors.Is(err, services.ErrWrongVersion)).To(BeTrue())
+
+	active, err = store.ListActive(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(active).To(BeEmpty())
+
+	phases, err := store.ListPhases(ctx, start, start.Add(24*time.Hour))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(phases).To(HaveLen(1))
+
+	missing, err := store.Get(ctx, updated.ID+1)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(missing.IsZero()).To(BeTrue())
+}
@@ -0,0 +1,210 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+
+	pgerr "github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx"
+)
+
+const timesheetColumns = `id, started, ended, status, created_at, updated_at, version`
+
+type TimesheetStore struct {
+	pool *sql.DB
+}
+
+func NewTimesheetStore(pool *sql.DB) *TimesheetStore {
+	return &TimesheetStore{pool}
+}
+
+func (s TimesheetStore) GetByWeek(ctx context.Context, start time.Time) (models.Timesheet, error) {
+	row := traced(s.pool).QueryRowContext(ctx, `
+		SELECT `+timesheetColumns+`
+		FROM timesheet
+		WHERE started = $1
+	`, start.UTC())
+
+	timesheet, err := s.scan(row)
+	if err == sql.ErrNoRows {
+		return models.Timesheet{}, nil
+	}
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	return s.withComments(ctx, timesheet)
+}
+
+func (s TimesheetStore) List(ctx context.Context, status models.TimesheetStatus) ([]models.Timesheet, error) {
+	rows, err := traced(s.pool).QueryContext(ctx, `
+		SELECT `+timesheetColumns+`
+		FROM timesheet
+		WHERE ($1::TEXT IS NULL OR status = $1)
+		ORDER BY started DESC
+	`, nullString(string(status)))
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to query timesheets", err)
+	}
+
+	timesheets, err := s.scanAll(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range timesheets {
+		if timesheets[i], err = s.withComments(ctx, timesheets[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return timesheets, nil
+}
+
+func (s TimesheetStore) Approved(ctx context.Context, start, end time.Time) ([]models.Timesheet, error) {
+	rows, err := traced(s.pool).QueryContext(ctx, `
+		SELECT `+timesheetColumns+`
+		FROM timesheet
+		WHERE status = $1 AND started <= $3 AND ended > $2
+		ORDER BY started ASC
+	`, string(models.TimesheetApproved), start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to query approved timesheets", err)
+	}
+
+	return s.scanAll(rows)
+}
+
+// Store creates the timesheet when version is zero and updates its status
+// otherwise, the update only applies to the given version. The comment is
+// added in the same transaction.
+func (s TimesheetStore) Store(ctx context.Context, timesheet models.Timesheet, version uint32, comment models.TimesheetComment) (models.Timesheet, error) {
+	tx, err := s.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Timesheet{}, fmt.Errorf("%w failed to begin transaction", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var row *sql.Row
+
+	if version == 0 {
+		row = traced(tx).QueryRowContext(ctx, `
+			INSERT INTO timesheet(started, ended, status)
+			VALUES ($1, $2, $3)
+			RETURNING `+timesheetColumns+`
+		`, timesheet.Start.UTC(), timesheet.End.UTC(), string(timesheet.Status))
+	} else {
+		row = traced(tx).QueryRowContext(ctx, `
+			UPDATE timesheet
+			SET status = $1, version = $2, updated_at = NOW()
+			WHERE id = $3 AND version = $4
+			RETURNING `+timesheetColumns+`
+		`, string(timesheet.Status), version+1, timesheet.ID, version)
+	}
+
+	stored, err := s.scan(row)
+	if err != nil {
+		// a concurrent first action on the same week creates it first
+		if pgErr, ok := err.(pgx.PgError); (ok && pgErr.Code == pgerr.UniqueViolation) || err == sql.ErrNoRows {
+			return models.Timesheet{}, services.ErrWrongVersion
+		}
+
+		return models.Timesheet{}, err
+	}
+
+	_, err = traced(tx).ExecContext(ctx, `
+		INSERT INTO timesheet_comment(timesheet_id, action, author, body, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, stored.ID, string(comment.Action), comment.Author, comment.Body, comment.At.UTC())
+	if err != nil {
+		return models.Timesheet{}, fmt.Errorf("%w failed to add timesheet comment", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Timesheet{}, fmt.Errorf("%w failed to commit timesheet", err)
+	}
+
+	return s.withComments(ctx, stored)
+}
+
+func (s TimesheetStore) withComments(ctx context.Context, timesheet models.Timesheet) (models.Timesheet, error) {
+	rows, err := traced(s.pool).QueryContext(ctx, `
+		SELECT action, author, body, created_at
+		FROM timesheet_comment
+		WHERE timesheet_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, timesheet.ID)
+	if err != nil {
+		return models.Timesheet{}, fmt.Errorf("%w failed to query timesheet comments", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			comment models.TimesheetComment
+			action  string
+		)
+
+		if err := rows.Scan(&action, &comment.Author, &comment.Body, &comment.At); err != nil {
+			return models.Timesheet{}, err
+		}
+
+		comment.Action = models.TimesheetAction(action)
+		timesheet.Comments = append(timesheet.Comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.Timesheet{}, fmt.Errorf("%w rows returned error", err)
+	}
+
+	return timesheet, nil
+}
+
+func (s TimesheetStore) scanAll(rows *sql.Rows) ([]models.Timesheet, error) {
+	defer rows.Close()
+
+	timesheets := make([]models.Timesheet, 0)
+	for rows.Next() {
+		timesheet, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		timesheets = append(timesheets, timesheet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w rows returned error", err)
+	}
+
+	return timesheets, nil
+}
+
+func (s TimesheetStore) scan(row rowScanner) (models.Timesheet, error) {
+	var (
+		timesheet models.Timesheet
+		status    string
+		createdAt time.Time
+		updatedAt time.Time
+		version   uint32
+	)
+
+	err := row.Scan(&timesheet.ID, &timesheet.Start, &timesheet.End, &status, &createdAt, &updatedAt, &version)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	timesheet.Status = models.TimesheetStatus(status)
+	timesheet.Comments = make([]models.TimesheetComment, 0)
+	timesheet.Trackers = make([]models.TimeTracker, 0)
+	timesheet.Days = make([]models.TimesheetDay, 0)
+
+	timesheet.Meta.HydrateMeta(false, createdAt, updatedAt, version)
+
+	return timesheet, nil
+}
@@ -0,0 +1,84 @@
+//go:build integrationdb
+// +build integrationdb
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	_ "github.com/jackc/pgx/stdlib"
+)
+
+func initTimesheetStore() (*TimesheetStore, error) {
+
+	connString := fmt.Sprintf("host=localhost port=5434 user=postgres password=postgres dbname=postgres sslmode=disable")
+
+	pool, err := sql.Open("pgx", connString)
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err = pool.Exec(`delete from timesheet_comment; delete from timesheet;`); err != nil {
+		panic(err)
+	}
+
+	return NewTimesheetStore(pool), nil
+}
+
+func Test_TimesheetStore_Store(t *testing.T) {
+	g := NewWithT(t)
+
+	store, _ := initTimesheetStore()
+	ctx := context.Background()
+
+	week := models.NewTimesheet(time.Date(2021, 5, 5, 12, 0, 0, 0, time.UTC), time.UTC)
+
+	missing, err := store.GetByWeek(ctx, week.Start)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(missing.IsZero()).To(BeTrue())
+
+	week.Status = models.TimesheetSubmitted
+	submitted, err := store.Store(ctx, week, 0, models.TimesheetComment{Action: models.TimesheetSubmit, Author: "ana", At: time.Now()})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(submitted.Start.Equal(week.Start)).To(BeTrue())
+	g.Expect(submitted.Comments).To(HaveLen(1))
+	g.Expect(submitted.Meta.GetVersion()).To(Equal(uint32(1)))
+
+	_, err = store.Store(ctx, week, 0, models.TimesheetComment{Action: models.TimesheetSubmit, At: time.Now()})
+	g.Expect(errors.Is(err, services.ErrWrongVersion)).To(BeTrue())
+
+	submitted.Status = models.TimesheetApproved
+	approved, err := store.Store(ctx, submitted, 1, models.TimesheetComment{Action: models.TimesheetApprove, Body: "thanks", At: time.Now()})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(approved.Status).To(Equal(models.TimesheetApproved))
+	g.Expect(approved.Comments).To(HaveLen(2))
+	g.Expect(approved.Comments[1].Body).To(Equal("thanks"))
+
+	_, err = store.Store(ctx, submitted, 1, models.TimesheetComment{Action: models.TimesheetApprove, At: time.Now()})
+	g.Expect(errors.Is(err, services.ErrWrongVersion)).To(BeTrue())
+
+	locked, err := store.Approved(ctx, week.Start.Add(-time.Hour), week.Start)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(locked).To(HaveLen(1))
+
+	locked, err = store.Approved(ctx, week.End, week.End.Add(time.Hour))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(locked).To(BeEmpty())
+
+	listed, err := store.List(ctx, models.TimesheetSubmitted)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(listed).To(BeEmpty())
+
+	listed, err = store.List(ctx, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(listed).To(HaveLen(1))
+}
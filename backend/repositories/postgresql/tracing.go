@@ -0,0 +1,81 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("pento/code-challenge/repositories/postgresql")
+
+// tracedExecutor starts a client span around every query it runs.
+type tracedExecutor struct {
+	next executor
+}
+
+func traced(next executor) executor {
+	return tracedExecutor{next}
+}
+
+func (e tracedExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	result, err := e.next.ExecContext(ctx, query, args...)
+	recordQueryError(span, err)
+
+	return result, err
+}
+
+func (e tracedExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	rows, err := e.next.QueryContext(ctx, query, args...)
+	recordQueryError(span, err)
+
+	return rows, err
+}
+
+func (e tracedExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	row := e.next.QueryRowContext(ctx, query, args...)
+	if err := row.Err(); err != sql.ErrNoRows {
+		recordQueryError(span, err)
+	}
+
+	return row
+}
+
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	statement := strings.Join(strings.Fields(query), " ")
+	operation := statement
+	if i := strings.IndexByte(statement, ' '); i > 0 {
+		operation = statement[:i]
+	}
+
+	return tracer.Start(ctx, "postgresql "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationKey.String(operation),
+			semconv.DBStatementKey.String(statement),
+		),
+	)
+}
+
+func recordQueryError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,89 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stubExecutor answers every statement with err.
+type stubExecutor struct {
+	executor
+	err error
+}
+
+func (e stubExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, e.err
+}
+
+func Test_tracedExecutor(t *testing.T) {
+
+	// the package tracer sticks to the first provider installed, a single
+	// recorder serves every case.
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	testCases := []struct {
+		description string
+		err         error
+		status      codes.Code
+	}{
+		{
+			description: "when the statement succeeds",
+			status:      codes.Unset,
+		},
+		{
+			description: "when the statement fails",
+			err:         errors.New("deadlock detected"),
+			status:      codes.Error,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			recorded := len(recorder.Ended())
+
+			ctx, parent := provider.Tracer("test").Start(context.Background(), "TrackerService.DeleteTracker")
+			_, err := traced(stubExecutor{err: tc.err}).ExecContext(ctx, `
+				UPDATE time_tracker
+				SET deleted = 't'
+				WHERE id = $1`, 1)
+			parent.End()
+
+			if tc.err != nil {
+				g.Expect(err).To(MatchError(tc.err), "should pass the error on")
+			} else {
+				g.Expect(err).ToNot(HaveOccurred(), "should run the statement")
+			}
+
+			spans := recorder.Ended()[recorded:]
+			g.Expect(spans).To(HaveLen(2), "should trace the statement")
+
+			statement := spans[0]
+			g.Expect(statement.Name()).To(Equal("postgresql UPDATE"), "should name the span after the operation")
+			g.Expect(statement.SpanKind()).To(Equal(trace.SpanKindClient))
+			g.Expect(statement.Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()), "should nest the statement in the caller span")
+			g.Expect(statement.Status().Code).To(Equal(tc.status), "should record the outcome")
+
+			attributes := map[string]string{}
+			for _, attribute := range statement.Attributes() {
+				attributes[string(attribute.Key)] = attribute.Value.Emit()
+			}
+			g.Expect(attributes).To(HaveKeyWithValue("db.system", "postgresql"))
+			g.Expect(attributes).To(HaveKeyWithValue("db.statement", "UPDATE time_tracker SET deleted = 't' WHERE id = $1"), "should record the statement on a single line")
+		})
+	}
+}
@@ -8,9 +8,13 @@ import (
 	"time"
 
 	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
+	"pento/code-challenge/logging"
 
 	pgerr "github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -19,23 +23,57 @@ var (
 	ErrTimeTrackerNotFound = errors.New("user not found")
 )
 
+// trackerColumns are the columns read into a trackerRow.
+const trackerColumns = `id, started, ended, name, created_at, updated_at, deleted, version, project, tags,
+	last_heartbeat, auto_stop_reason, auto_stopped_at, overlap_accepted`
+
+// uniqueIndexErrors maps unique indexes to the domain error they enforce.
+var uniqueIndexErrors = map[string]error{
+	"time_tracker_single_running": services.ErrTrackerRunning,
+}
+
+// uniqueViolationError matches ErrUniqueViolation and the domain error of the
+// violated index with errors.Is.
+type uniqueViolationError struct {
+	index string
+}
+
+func (e uniqueViolationError) Error() string {
+	return fmt.Sprintf("%s on %s", ErrUniqueViolation, e.index)
+}
+
+func (e uniqueViolationError) Is(target error) bool {
+	return target == ErrUniqueViolation || (target != nil && target == uniqueIndexErrors[e.index])
+}
+
 type TrackerStore struct {
 	pool *sql.DB
+	// singleRunning puts the trackers it writes under the unique index
+	// allowing a single running tracker.
+	singleRunning bool
+	logger        logrus.FieldLogger
 }
 
-func NewTrackerStore(pool *sql.DB) *TrackerStore {
-	return &TrackerStore{pool}
+func NewTrackerStore(pool *sql.DB, singleRunning bool, logger logrus.FieldLogger) *TrackerStore {
+	return &TrackerStore{pool, singleRunning, logger}
 }
 
 func (s TrackerStore) Get(ctx context.Context, id uint64) (models.TimeTracker, error) {
 
-	row := s.pool.QueryRowContext(ctx, `
-		SELECT id, started, ended, name, created_at, updated_at, deleted, version
+	row := s.executor(ctx).QueryRowContext(ctx, `
+		SELECT `+trackerColumns+`
 		FROM time_tracker
 		WHERE id = $1 AND deleted = 'f' 
 	`, id)
 
-	return s.scan(row)
+	tracker, err := s.scan(row)
+	if err == ErrTimeTrackerNotFound {
+		// a missing tracker is reported as a zero value, the service decides
+		// how to surface it.
+		return models.TimeTracker{}, nil
+	}
+
+	return tracker, err
 }
 
 func queryComposer(start, end time.Time) string {
@@ -59,8 +97,8 @@ func (s TrackerStore) List(ctx context.Context, start, end time.Time) ([]models.
 
 	arguments := queryComposer(start, end)
 
-	rows, err := s.pool.QueryContext(ctx, fmt.Sprintf(`
-		SELECT id, started, ended, name, created_at, updated_at, deleted, version
+	rows, err := s.executor(ctx).QueryContext(ctx, fmt.Sprintf(`
+		SELECT `+trackerColumns+`
 		FROM time_tracker
 		WHERE %s deleted = 'f'
 		order by created_at ASC
@@ -83,37 +121,139 @@ func (s TrackerStore) List(ctx context.Context, start, end time.Time) ([]models.
 	return tracker, nil
 }
 
-func (s TrackerStore) Store(ctx context.Context, tracker models.TimeTracker, version uint32) (models.TimeTracker, error) {
-	var result models.TimeTracker
+func (s TrackerStore) CountRunning(ctx context.Context) (int, error) {
+	var running int
+
+	row := s.executor(ctx).QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM time_tracker
+		WHERE ended IS NULL AND deleted = 'f'
+	`)
+
+	if err := row.Scan(&running); err != nil {
+		return 0, fmt.Errorf("%w failed to count running trackers", err)
+	}
+
+	return running, nil
+}
 
-	tx, err := s.pool.Begin()
+// Overlapping returns the trackers other than excludeID whose session
+// intersects the period, running trackers extend indefinitely.
+func (s TrackerStore) Overlapping(ctx context.Context, start, end time.Time, excludeID uint64) ([]models.TimeTracker, error) {
+	rows, err := s.executor(ctx).QueryContext(ctx, `
+		SELECT `+trackerColumns+`
+		FROM time_tracker
+		WHERE deleted = 'f' AND id <> $3 AND tsrange(started, ended) && tsrange($1, $2)
+		ORDER BY started ASC
+	`, nullTime(start), nullTime(end), excludeID)
 	if err != nil {
-		return models.TimeTracker{}, fmt.Errorf("%w failed to begin transaction", err)
+		return nil, fmt.Errorf("%w failed to query overlapping trackers", err)
 	}
 
-	current, err := s.lockForUpdate(ctx, tx, tracker.ID)
+	defer rows.Close()
+
+	trackers, err := s.scanMultipleRows(rows)
 	if err != nil {
-		tx.Rollback()
-		return models.TimeTracker{}, err
+		return nil, fmt.Errorf("%w error scan multiple rows", err)
 	}
 
-	if current != version {
-		tx.Rollback()
-		return models.TimeTracker{}, ErrWrongVersion
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w rows returned error", err)
 	}
 
-	if current == 0 {
-		result, err = s.create(ctx, tx, tracker)
-	} else {
-		result, err = s.update(ctx, tx, tracker, version)
+	return trackers, nil
+}
+
+// ProjectTrackers returns the trackers of project whose session intersects
+// the period.
+func (s TrackerStore) ProjectTrackers(ctx context.Context, project string, start, end time.Time) ([]models.TimeTracker, error) {
+	rows, err := s.executor(ctx).QueryContext(ctx, `
+		SELECT `+trackerColumns+`
+		FROM time_tracker
+		WHERE deleted = 'f' AND project = $3 AND tsrange(started, ended) && tsrange($1, $2)
+		ORDER BY started ASC
+	`, nullTime(start), nullTime(end), project)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to query project trackers", err)
 	}
+
+	defer rows.Close()
+
+	trackers, err := s.scanMultipleRows(rows)
 	if err != nil {
-		tx.Rollback()
-		return models.TimeTracker{}, err
+		return nil, fmt.Errorf("%w error scan multiple rows", err)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w rows returned error", err)
+	}
+
+	return trackers, nil
+}
+
+func (s TrackerStore) ListRunning(ctx context.Context) ([]models.TimeTracker, error) {
+	rows, err := s.executor(ctx).QueryContext(ctx, `
+		SELECT `+trackerColumns+`
+		FROM time_tracker
+		WHERE ended IS NULL AND deleted = 'f'
+		ORDER BY started ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%w failed to query running trackers", err)
+	}
+
+	defer rows.Close()
+
+	trackers, err := s.scanMultipleRows(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w error scan multiple rows", err)
 	}
 
-	tx.Commit()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w rows returned error", err)
+	}
+
+	return trackers, nil
+}
+
+// Heartbeat records activity on a running tracker, it reports whether a
+// running tracker was found. The version is left alone so heartbeats don't
+// conflict with edits.
+func (s TrackerStore) Heartbeat(ctx context.Context, id uint64, at time.Time) (bool, error) {
+	result, err := s.executor(ctx).ExecContext(ctx, `
+		UPDATE time_tracker
+		SET last_heartbeat = $2
+		WHERE id = $1 AND ended IS NULL AND deleted = 'f'
+	`, id, at)
+	if err != nil {
+		return false, fmt.Errorf("%w failed to record heartbeat", err)
+	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%w failed to read affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (s TrackerStore) Store(ctx context.Context, tracker models.TimeTracker, version uint32) (models.TimeTracker, error) {
+	if current, ok := transactionFromContext(ctx); ok {
+		return s.store(ctx, current.tx, tracker, version)
+	}
+
+	tx, err := s.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return models.TimeTracker{}, fmt.Errorf("%w failed to begin transaction", err)
+	}
+
+	result, err := s.store(ctx, tx, tracker, version)
+	if err != nil {
+		s.rollback(ctx, tx)
+		return models.TimeTracker{}, err
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return models.TimeTracker{}, err
 	}
@@ -121,10 +261,27 @@ func (s TrackerStore) Store(ctx context.Context, tracker models.TimeTracker, ver
 	return result, nil
 }
 
+func (s TrackerStore) store(ctx context.Context, tx *sql.Tx, tracker models.TimeTracker, version uint32) (models.TimeTracker, error) {
+	current, err := s.lockForUpdate(ctx, tx, tracker.ID)
+	if err != nil {
+		return models.TimeTracker{}, err
+	}
+
+	if current != version {
+		return models.TimeTracker{}, ErrWrongVersion
+	}
+
+	if current == 0 {
+		return s.create(ctx, tx, tracker)
+	}
+
+	return s.update(ctx, tx, tracker, version)
+}
+
 func (s TrackerStore) lockForUpdate(ctx context.Context, tx *sql.Tx, id uint64) (uint32, error) {
 	var version uint32
 
-	row := tx.QueryRowContext(ctx, `
+	row := traced(tx).QueryRowContext(ctx, `
 		SELECT version
 		FROM time_tracker
 		WHERE id = $1 FOR UPDATE NOWAIT
@@ -138,71 +295,114 @@ func (s TrackerStore) lockForUpdate(ctx context.Context, tx *sql.Tx, id uint64)
 	return version, nil
 }
 
-func (s TrackerStore) Delete(ctx context.Context, id uint64) error {
-	_, err := s.pool.ExecContext(ctx, `
+// Delete marks the tracker deleted, a non zero version only deletes it while
+// it is still at that version and returns services.ErrWrongVersion otherwise.
+func (s TrackerStore) Delete(ctx context.Context, id uint64, version uint32) error {
+	result, err := s.executor(ctx).ExecContext(ctx, `
 		UPDATE time_tracker
 		SET deleted = 't', updated_at = NOW()
-		WHERE id = $1
-	`, id)
+		WHERE id = $1 AND ($2 = 0 OR version = $2)
+	`, id, version)
 
 	if err != nil {
 		return fmt.Errorf("%w failed to set to deleted", err)
 	}
 
+	if version == 0 {
+		return nil
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w failed to count deleted trackers", err)
+	}
+
+	if deleted == 0 {
+		return services.ErrWrongVersion
+	}
+
 	return nil
 }
 
 func (s TrackerStore) create(ctx context.Context, tx *sql.Tx, tracker models.TimeTracker) (models.TimeTracker, error) {
 
-	row := tx.QueryRowContext(ctx, `
-		INSERT INTO time_tracker(started, name)
-		VALUES ($1, $2)
-		RETURNING id, started, ended, name, created_at, updated_at, deleted, version
+	row := traced(tx).QueryRowContext(ctx, `
+		INSERT INTO time_tracker(started, name, project, tags, overlap_accepted, single_running)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+trackerColumns+`
 	`,
 		tracker.Start,
 		tracker.Name,
+		nullString(tracker.Project),
+		textArray(tracker.Tags),
+		tracker.OverlapAccepted,
+		s.singleRunning,
 	)
 	return s.scan(row)
 }
 
 func (s TrackerStore) update(ctx context.Context, tx *sql.Tx, tracker models.TimeTracker, version uint32) (models.TimeTracker, error) {
 
-	row := tx.QueryRowContext(ctx, `
+	row := traced(tx).QueryRowContext(ctx, `
 		UPDATE time_tracker
-		SET started = $1, ended = $2, name = $3, version = $4, updated_at = NOW()
-		WHERE id = $5 AND version = $6
-		RETURNING id, started, ended, name, created_at, updated_at, deleted, version
+		SET started = $1, ended = $2, name = $3, project = $4, tags = $5, version = $6, overlap_accepted = $7,
+			single_running = $8, auto_stop_reason = $9, auto_stopped_at = $10, updated_at = NOW()
+		WHERE id = $11 AND version = $12
+		RETURNING `+trackerColumns+`
 	`,
 		tracker.Start,
-		tracker.End,
+		nullTime(tracker.End),
 		tracker.Name,
+		nullString(tracker.Project),
+		textArray(tracker.Tags),
 		version+1,
+		tracker.OverlapAccepted,
+		s.singleRunning,
+		autoStopReason(tracker.AutoStop),
+		autoStoppedAt(tracker.AutoStop),
 		tracker.ID,
 		tracker.Meta.GetVersion(),
 	)
 	return s.scan(row)
 }
 
+// trackerRow holds the columns of trackerColumns as they are scanned.
+type trackerRow struct {
+	id              uint64
+	start           time.Time
+	end             sql.NullTime
+	name            string
+	createdAt       time.Time
+	updatedAt       time.Time
+	deleted         bool
+	version         uint32
+	project         sql.NullString
+	tags            pgtype.TextArray
+	lastHeartbeat   sql.NullTime
+	autoStopReason  sql.NullString
+	autoStoppedAt   sql.NullTime
+	overlapAccepted bool
+}
+
+func (r *trackerRow) fields() []interface{} {
+	return []interface{}{
+		&r.id, &r.start, &r.end, &r.name, &r.createdAt, &r.updatedAt, &r.deleted, &r.version,
+		&r.project, &r.tags, &r.lastHeartbeat, &r.autoStopReason, &r.autoStoppedAt, &r.overlapAccepted,
+	}
+}
+
 func (s TrackerStore) scan(row *sql.Row) (models.TimeTracker, error) {
-	var (
-		id        uint64
-		start     time.Time
-		end       sql.NullTime
-		name      string
-		deleted   bool
-		version   uint32
-		createdAt time.Time
-		updatedAt time.Time
-	)
+	var tracker trackerRow
 
-	if err := row.Scan(
-		&id,
-		&start,
-		&end,
-		&name, &createdAt, &updatedAt, &deleted, &version); err != nil {
+	if err := row.Scan(tracker.fields()...); err != nil {
 		if pgErr, ok := err.(pgx.PgError); ok {
-			if pgErr.Code == pgerr.UniqueViolation {
-				return models.TimeTracker{}, ErrUniqueViolation
+			switch pgErr.Code {
+			case pgerr.UniqueViolation:
+				return models.TimeTracker{}, uniqueViolationError{index: pgErr.ConstraintName}
+			case pgerr.ExclusionViolation:
+				// a concurrent write got in between the overlap check and
+				// this one.
+				return models.TimeTracker{}, services.ErrOverlap
 			}
 		}
 
@@ -213,32 +413,17 @@ func (s TrackerStore) scan(row *sql.Row) (models.TimeTracker, error) {
 		return models.TimeTracker{}, err
 	}
 
-	return s.hydrateTimeTracker(id, start, end, name, deleted, version, createdAt, updatedAt), nil
+	return s.hydrateTimeTracker(tracker)
 }
 
 func (s TrackerStore) scanMultipleRows(rows *sql.Rows) ([]models.TimeTracker, error) {
 	var (
-		tracker []models.TimeTracker = make([]models.TimeTracker, 0)
+		trackers []models.TimeTracker = make([]models.TimeTracker, 0)
 	)
 
-	type timeTracker struct {
-		id        uint64
-		start     time.Time
-		end       sql.NullTime
-		name      string
-		deleted   bool
-		version   uint32
-		createdAt time.Time
-		updatedAt time.Time
-	}
-
 	for rows.Next() {
-		var timetracker timeTracker
-		if err := rows.Scan(
-			&timetracker.id,
-			&timetracker.start,
-			&timetracker.end,
-			&timetracker.name, &timetracker.createdAt, &timetracker.updatedAt, &timetracker.deleted, &timetracker.version); err != nil {
+		var row trackerRow
+		if err := rows.Scan(row.fields()...); err != nil {
 			if pgErr, ok := err.(pgx.PgError); ok {
 				if pgErr.Code == pgerr.UniqueViolation {
 					return nil, ErrUniqueViolation
@@ -252,27 +437,88 @@ func (s TrackerStore) scanMultipleRows(rows *sql.Rows) ([]models.TimeTracker, er
 			return nil, err
 		}
 
-		user := s.hydrateTimeTracker(timetracker.id, timetracker.start, timetracker.end,
-			timetracker.name, timetracker.deleted, timetracker.version, timetracker.createdAt, timetracker.updatedAt)
+		tracker, err := s.hydrateTimeTracker(row)
+		if err != nil {
+			return nil, err
+		}
 
-		tracker = append(tracker, user)
+		trackers = append(trackers, tracker)
 	}
 
-	return tracker, nil
+	return trackers, nil
 }
 
-func (s TrackerStore) hydrateTimeTracker(id uint64, start time.Time, end sql.NullTime,
-	name string, deleted bool, version uint32, createdAt, updatedAt time.Time) models.TimeTracker {
+func (s TrackerStore) hydrateTimeTracker(row trackerRow) (models.TimeTracker, error) {
 
 	var tracker models.TimeTracker
 
-	if end.Valid {
-		tracker = models.NewTimeTracker(id, start, end.Time, name)
+	if row.end.Valid {
+		tracker = models.NewTimeTracker(row.id, row.start, row.end.Time, row.name)
 	} else {
-		tracker = models.NewTimeTracker(id, start, time.Time{}, name)
+		tracker = models.NewTimeTracker(row.id, row.start, time.Time{}, row.name)
+	}
+
+	tracker.Project = row.project.String
+	tracker.Tags = make([]string, 0)
+	if row.tags.Status == pgtype.Present {
+		if err := row.tags.AssignTo(&tracker.Tags); err != nil {
+			return models.TimeTracker{}, fmt.Errorf("%w failed to read tags", err)
+		}
+	}
+
+	tracker.LastHeartbeat = row.lastHeartbeat.Time
+	tracker.OverlapAccepted = row.overlapAccepted
+	if row.autoStopReason.Valid {
+		tracker.AutoStop = &models.AutoStop{
+			Reason: models.AutoStopReason(row.autoStopReason.String),
+			At:     row.autoStoppedAt.Time,
+		}
 	}
 
-	tracker.Meta.HydrateMeta(deleted, createdAt, updatedAt, version)
+	tracker.Meta.HydrateMeta(row.deleted, row.createdAt, row.updatedAt, row.version)
+
+	return tracker, nil
+}
+
+// rollback aborts tx, a failure is only logged since the error that caused the
+// rollback is the one worth returning.
+func (s TrackerStore) rollback(ctx context.Context, tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+		logging.FromContext(ctx, s.logger).WithError(err).Warn("failed to roll back transaction")
+	}
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
+
+func autoStopReason(autoStop *models.AutoStop) sql.NullString {
+	if autoStop == nil {
+		return sql.NullString{}
+	}
+
+	return nullString(string(autoStop.Reason))
+}
+
+func autoStoppedAt(autoStop *models.AutoStop) sql.NullTime {
+	if autoStop == nil {
+		return sql.NullTime{}
+	}
+
+	return nullTime(autoStop.At)
+}
+
+func textArray(values []string) pgtype.TextArray {
+	var array pgtype.TextArray
+
+	if values == nil {
+		values = make([]string, 0)
+	}
+	array.Set(values)
 
-	return tracker
+	return array
 }
@@ -1,3 +1,4 @@
+//go:build integrationdb
 // +build integrationdb
 
 package postgresql
@@ -8,10 +9,12 @@ import (
 	"fmt"
 	"pento/code-challenge/domain"
 	"pento/code-challenge/domain/tracker/models"
+	"pento/code-challenge/domain/tracker/services"
 	"testing"
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
 
 	_ "github.com/jackc/pgx/stdlib"
 
@@ -39,7 +42,7 @@ func initTrackerStore() (*TrackerStore, error) {
 		panic(err)
 	}
 
-	store := NewTrackerStore(pool)
+	store := NewTrackerStore(pool, false, logrus.New())
 
 	return store, nil
 }
@@ -160,7 +163,8 @@ func Test_TrackerStore_Store(t *testing.T) {
 func Test_TrackerStore_Delete(t *testing.T) {
 
 	type testInput struct {
-		id uint64
+		id      uint64
+		version uint32
 	}
 
 	type testExpectation struct {
@@ -188,6 +192,16 @@ func Test_TrackerStore_Delete(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			description: "when the tracker changed since it was read",
+			input: testInput{
+				id:      1,
+				version: 5,
+			},
+			expected: testExpectation{
+				err: services.ErrWrongVersion,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -201,7 +215,7 @@ func Test_TrackerStore_Delete(t *testing.T) {
 			defer repo.pool.Close()
 			g.Expect(err).ToNot(HaveOccurred(), "should not return an error setting up the repository")
 
-			err = repo.Delete(ctx, tc.input.id)
+			err = repo.Delete(ctx, tc.input.id, tc.input.version)
 
 			if tc.expected.err != nil {
 				g.Expect(err).To(Equal(tc.expected.err), "should return the expected error")
@@ -254,6 +268,16 @@ func Test_TrackerStore_Get(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			description: "when getting a tracker that does not exist",
+			input: testInput{
+				id: 10,
+			},
+			expected: testExpectation{
+				result: models.TimeTracker{},
+				err:    nil,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -386,3 +410,238 @@ func Test_TrackerStore_List(t *testing.T) {
 		})
 	}
 }
+
+func Test_TrackerStore_WithinTransaction(t *testing.T) {
+
+	type testInput struct {
+		name     string
+		innerErr error
+		outerErr error
+	}
+
+	type testExpectation struct {
+		err    error
+		stored []string
+	}
+
+	testCases := []struct {
+		description string
+		input       testInput
+		expected    testExpectation
+	}{
+		{
+			description: "when every step succeeds",
+			input: testInput{
+				name: "test_tracker_3",
+			},
+			expected: testExpectation{
+				err:    nil,
+				stored: []string{"test_time_tracker_1", "test_time_tracker_2", "test_tracker_3", "test_tracker_3_inner"},
+			},
+		},
+		{
+			description: "when a nested step fails only the nested changes are rolled back",
+			input: testInput{
+				name:     "test_tracker_3",
+				innerErr: ERROR,
+			},
+			expected: testExpectation{
+				err:    nil,
+				stored: []string{"test_time_tracker_1", "test_time_tracker_2", "test_tracker_3"},
+			},
+		},
+		{
+			description: "when the outer step fails everything is rolled back",
+			input: testInput{
+				name:     "test_tracker_3",
+				outerErr: ERROR,
+			},
+			expected: testExpectation{
+				err:    ERROR,
+				stored: []string{"test_time_tracker_1", "test_time_tracker_2"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var ctx = context.TODO()
+			defer ctx.Done()
+
+			repo, err := initTrackerStore()
+			defer repo.pool.Close()
+			g.Expect(err).ToNot(HaveOccurred(), "should not return an error setting up the repository")
+
+			err = repo.WithinTransaction(ctx, func(ctx context.Context) error {
+				_, err := repo.Store(ctx, models.NewTimeTracker(0, time.Now(), time.Time{}, tc.input.name), 0)
+				if err != nil {
+					return err
+				}
+
+				innerErr := repo.WithinTransaction(ctx, func(ctx context.Context) error {
+					_, err := repo.Store(ctx, models.NewTimeTracker(0, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour), tc.input.name+"_inner"), 0)
+					if err != nil {
+						return err
+					}
+
+					return tc.input.innerErr
+				})
+				g.Expect(innerErr).To(Equal(tc.input.innerErr), "should return the nested error")
+
+				return tc.input.outerErr
+			})
+
+			if tc.expected.err != nil {
+				g.Expect(err).To(Equal(tc.expected.err), "should return the expected error")
+			} else {
+				g.Expect(err).ToNot(HaveOccurred(), "should not return an error")
+			}
+
+			result, err := repo.List(ctx, time.Time{}, time.Time{})
+			g.Expect(err).ToNot(HaveOccurred(), "should not return an error listing")
+
+			names := make([]string, 0)
+			for _, tracker := range result {
+				names = append(names, tracker.Name)
+			}
+			g.Expect(names).To(ConsistOf(tc.expected.stored), "should store the expected trackers")
+		})
+	}
+}
+
+func Test_TrackerStore_Overlapping(t *testing.T) {
+
+	type testInput struct {
+		start     time.Time
+		end       time.Time
+		excludeID uint64
+	}
+
+	testCases := []struct {
+		description string
+		input       testInput
+		expected    []uint64
+	}{
+		{
+			description: "when the period overlaps a session",
+			input: testInput{
+				start: time.Date(2020, time.May, 15, 9, 0, 0, 0, time.UTC),
+				end:   time.Date(2020, time.May, 15, 11, 0, 0, 0, time.UTC),
+			},
+			expected: []uint64{1},
+		},
+		{
+			description: "when the period starts as a session ends",
+			input: testInput{
+				start: time.Date(2020, time.May, 15, 10, 0, 0, 0, time.UTC),
+				end:   time.Date(2020, time.May, 15, 11, 0, 0, 0, time.UTC),
+			},
+			expected: []uint64{},
+		},
+		{
+			description: "when the period is open ended",
+			input: testInput{
+				start: time.Date(2020, time.May, 15, 9, 0, 0, 0, time.UTC),
+			},
+			expected: []uint64{1, 2},
+		},
+		{
+			description: "when the overlapping session is excluded",
+			input: testInput{
+				start:     time.Date(2020, time.May, 15, 9, 0, 0, 0, time.UTC),
+				end:       time.Date(2020, time.May, 15, 11, 0, 0, 0, time.UTC),
+				excludeID: 1,
+			},
+			expected: []uint64{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			repo, err := initTrackerStore()
+			defer repo.pool.Close()
+			g.Expect(err).ToNot(HaveOccurred(), "should not return an error setting up the repository")
+
+			result, err := repo.Overlapping(context.TODO(), tc.input.start, tc.input.end, tc.input.excludeID)
+			g.Expect(err).ToNot(HaveOccurred(), "should not return an error")
+
+			ids := make([]uint64, 0)
+			for _, tracker := range result {
+				ids = append(ids, tracker.ID)
+			}
+			g.Expect(ids).To(Equal(tc.expected), "should return the overlapping trackers")
+		})
+	}
+}
+
+func Test_TrackerStore_Store_Overlap(t *testing.T) {
+	g := NewWithT(t)
+
+	repo, err := initTrackerStore()
+	defer repo.pool.Close()
+	g.Expect(err).ToNot(HaveOccurred(), "should not return an error setting up the repository")
+
+	start := time.Date(2020, time.May, 15, 9, 0, 0, 0, time.UTC)
+	overlapping := models.NewTimeTracker(0, start, start.Add(16*time.Hour), "overlapping")
+
+	_, err = repo.Store(context.TODO(), overlapping, 0)
+	g.Expect(err).To(MatchError(services.ErrOverlap), "should be rejected by the exclusion constraint")
+
+	_, err = repo.Store(context.TODO(), models.NewTimeTracker(0, start, time.Time{}, "running"), 0)
+	g.Expect(err).ToNot(HaveOccurred(), "should leave running trackers to the service")
+
+	overlapping.OverlapAccepted = true
+	_, err = repo.Store(context.TODO(), overlapping, 0)
+	g.Expect(err).ToNot(HaveOccurred(), "should store an accepted overlap")
+}
+
+func Test_TrackerStore_Store_SingleRunning(t *testing.T) {
+	g := NewWithT(t)
+
+	repo, err := initTrackerStore()
+	defer repo.pool.Close()
+	g.Expect(err).ToNot(HaveOccurred(), "should not return an error setting up the repository")
+
+	repo.singleRunning = true
+
+	_, err = repo.Store(context.TODO(), models.NewTimeTracker(0, time.Now(), time.Time{}, "first"), 0)
+	g.Expect(err).ToNot(HaveOccurred(), "should store the first running tracker")
+
+	_, err = repo.Store(context.TODO(), models.NewTimeTracker(0, time.Now(), time.Time{}, "second"), 0)
+	g.Expect(err).To(MatchError(ErrUniqueViolation), "should be rejected by the unique index")
+	g.Expect(err).To(MatchError(services.ErrTrackerRunning), "should map to the domain error")
+
+	running, err := repo.ListRunning(context.TODO())
+	g.Expect(err).ToNot(HaveOccurred(), "should not return an error listing")
+	g.Expect(running).To(HaveLen(1), "should keep a single running tracker")
+}
+
+func Test_TrackerStore_Heartbeat(t *testing.T) {
+	g := NewWithT(t)
+
+	repo, err := initTrackerStore()
+	defer repo.pool.Close()
+	g.Expect(err).ToNot(HaveOccurred(), "should not return an error setting up the repository")
+
+	running, err := repo.Store(context.TODO(), models.NewTimeTracker(0, time.Now(), time.Time{}, "running"), 0)
+	g.Expect(err).ToNot(HaveOccurred(), "should store the running tracker")
+
+	at := time.Date(2021, time.May, 1, 2, 0, 0, 0, time.UTC)
+
+	found, err := repo.Heartbeat(context.TODO(), running.ID, at)
+	g.Expect(err).ToNot(HaveOccurred(), "should not return an error")
+	g.Expect(found).To(BeTrue(), "should record the heartbeat of a running tracker")
+
+	result, err := repo.Get(context.TODO(), running.ID)
+	g.Expect(err).ToNot(HaveOccurred(), "should not return an error getting the tracker")
+	g.Expect(result.LastHeartbeat).To(Equal(at), "should read the heartbeat back")
+	g.Expect(result.Meta.GetVersion()).To(Equal(running.Meta.GetVersion()), "should leave the version alone")
+
+	found, err = repo.Heartbeat(context.TODO(), 1, at)
+	g.Expect(err).ToNot(HaveOccurred(), "should not return an error")
+	g.Expect(found).To(BeFalse(), "should ignore stopped trackers")
+}
@@ -0,0 +1,86 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type txKey struct{}
+
+type transaction struct {
+	tx         *sql.Tx
+	savepoints int
+}
+
+// executor is satisfied by both *sql.DB and *sql.Tx.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func transactionFromContext(ctx context.Context) (*transaction, bool) {
+	current, ok := ctx.Value(txKey{}).(*transaction)
+
+	return current, ok
+}
+
+// WithinTransaction runs fn with a context carrying a database transaction,
+// every store call made with that context joins the transaction. Nested calls
+// are mapped to savepoints so a failing inner call only rolls back its own
+// changes.
+func (s TrackerStore) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if current, ok := transactionFromContext(ctx); ok {
+		return s.withinSavepoint(ctx, current, fn)
+	}
+
+	tx, err := s.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w failed to begin transaction", err)
+	}
+
+	err = fn(context.WithValue(ctx, txKey{}, &transaction{tx: tx}))
+	if err != nil {
+		s.rollback(ctx, tx)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+func (s TrackerStore) withinSavepoint(ctx context.Context, current *transaction, fn func(ctx context.Context) error) error {
+	current.savepoints++
+	savepoint := fmt.Sprintf("sp_%d", current.savepoints)
+
+	if _, err := traced(current.tx).ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("%w failed to create savepoint", err)
+	}
+
+	err := fn(ctx)
+	if err != nil {
+		if _, rollbackErr := traced(current.tx).ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rollbackErr != nil {
+			return fmt.Errorf("%w failed to rollback to savepoint", rollbackErr)
+		}
+
+		return err
+	}
+
+	if _, err := traced(current.tx).ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("%w failed to release savepoint", err)
+	}
+
+	return nil
+}
+
+func (s TrackerStore) executor(ctx context.Context) executor {
+	if current, ok := transactionFromContext(ctx); ok {
+		return traced(current.tx)
+	}
+
+	return traced(s.pool)
+}
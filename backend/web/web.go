@@ -0,0 +1,143 @@
+package web
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	indexFile  = "index.html"
+	configPath = "/config.js"
+
+	// create-react-app fingerprints everything under static/
+	immutableCache = "public, max-age=31536000, immutable"
+	revalidate     = "no-cache"
+)
+
+//go:embed dist
+var dist embed.FS
+
+// Dist returns the frontend bundle built into the binary.
+func Dist() fs.FS {
+	files, err := fs.Sub(dist, "dist")
+	if err != nil {
+		// the directory is embedded, it can't be missing
+		panic(err)
+	}
+
+	return files
+}
+
+type config struct {
+	APIBaseURL string `json:"apiBaseURL"`
+}
+
+// Handler serves a single page app: existing files are served as is, any
+// other path without an extension falls back to index.html so the client
+// side router can handle it.
+type Handler struct {
+	files    fs.FS
+	configJS []byte
+	started  time.Time
+}
+
+// NewHandler serves files, /config.js exposes apiBaseURL to the frontend.
+func NewHandler(files fs.FS, apiBaseURL string) (*Handler, error) {
+	if _, err := fs.Stat(files, indexFile); err != nil {
+		return nil, fmt.Errorf("%w frontend bundle has no %s", err, indexFile)
+	}
+
+	encoded, err := json.Marshal(config{APIBaseURL: apiBaseURL})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		files:    files,
+		configJS: []byte(fmt.Sprintf("window.TRACKER_CONFIG = %s;\n", encoded)),
+		started:  time.Now(),
+	}, nil
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if r.URL.Path == configPath {
+		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", revalidate)
+		http.ServeContent(w, r, configPath, h.started, bytes.NewReader(h.configJS))
+
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if name == "" {
+		name = indexFile
+	}
+
+	info, err := fs.Stat(h.files, name)
+	switch {
+	case err == nil && !info.IsDir():
+	case strings.HasPrefix(name, "api/") || path.Ext(name) != "":
+		// unknown API routes and missing assets are real 404s
+		http.NotFound(w, r)
+
+		return
+	default:
+		name = indexFile
+	}
+
+	if strings.HasPrefix(name, "static/") {
+		w.Header().Set("Cache-Control", immutableCache)
+	} else {
+		w.Header().Set("Cache-Control", revalidate)
+	}
+
+	h.serveFile(w, r, name)
+}
+
+func (h Handler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	file, err := h.files.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	// embedded files have no modification time, fall back to the start of
+	// the process so conditional requests still work.
+	modTime := info.ModTime()
+	if modTime.IsZero() {
+		modTime = h.started
+	}
+
+	http.ServeContent(w, r, name, modTime, content)
+}
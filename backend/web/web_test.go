@@ -0,0 +1,107 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_Handler_ServeHTTP(t *testing.T) {
+
+	files := fstest.MapFS{
+		"index.html":             {Data: []byte("<html>app</html>")},
+		"favicon.ico":            {Data: []byte("icon")},
+		"static/js/main.1a2b.js": {Data: []byte("console.log(1)")},
+	}
+
+	type testExpectation struct {
+		status       int
+		body         string
+		cacheControl string
+	}
+
+	testCases := []struct {
+		description string
+		method      string
+		path        string
+		expected    testExpectation
+	}{
+		{
+			description: "when the root is requested",
+			method:      http.MethodGet,
+			path:        "/",
+			expected:    testExpectation{status: http.StatusOK, body: "<html>app</html>", cacheControl: revalidate},
+		},
+		{
+			description: "when a fingerprinted asset is requested",
+			method:      http.MethodGet,
+			path:        "/static/js/main.1a2b.js",
+			expected:    testExpectation{status: http.StatusOK, body: "console.log(1)", cacheControl: immutableCache},
+		},
+		{
+			description: "when a client side route is requested",
+			method:      http.MethodGet,
+			path:        "/trackers/12",
+			expected:    testExpectation{status: http.StatusOK, body: "<html>app</html>", cacheControl: revalidate},
+		},
+		{
+			description: "when a missing asset is requested",
+			method:      http.MethodGet,
+			path:        "/static/js/missing.js",
+			expected:    testExpectation{status: http.StatusNotFound},
+		},
+		{
+			description: "when an unknown API route is requested",
+			method:      http.MethodGet,
+			path:        "/api/v3/tracker",
+			expected:    testExpectation{status: http.StatusNotFound},
+		},
+		{
+			description: "when the runtime config is requested",
+			method:      http.MethodGet,
+			path:        "/config.js",
+			expected: testExpectation{
+				status:       http.StatusOK,
+				body:         "window.TRACKER_CONFIG = {\"apiBaseURL\":\"https://tracker.example.com/api/v1\"};\n",
+				cacheControl: revalidate,
+			},
+		},
+		{
+			description: "when the method is not a read",
+			method:      http.MethodPost,
+			path:        "/",
+			expected:    testExpectation{status: http.StatusMethodNotAllowed},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			handler, err := NewHandler(files, "https://tracker.example.com/api/v1")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(tc.method, tc.path, nil))
+
+			g.Expect(recorder.Code).To(Equal(tc.expected.status))
+			if tc.expected.status != http.StatusOK {
+				return
+			}
+
+			g.Expect(recorder.Body.String()).To(Equal(tc.expected.body))
+			g.Expect(recorder.Header().Get("Cache-Control")).To(Equal(tc.expected.cacheControl))
+		})
+	}
+}
+
+func Test_Dist(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := NewHandler(Dist(), "/api/v1")
+
+	g.Expect(err).ToNot(HaveOccurred())
+}
@@ -0,0 +1,2 @@
+// Replaced at serve time by the Go server with its frontend.api_base_url.
+window.TRACKER_CONFIG = { apiBaseURL: "http://localhost:8080/api/v1" };
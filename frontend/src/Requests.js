@@ -7,7 +7,9 @@ const headers = {
     referrerPolicy: "no-referrer"
 }
 
-const url = "http://localhost:8080/api/v1"
+// the Go server injects the API base URL in config.js at serve time,
+// public/config.js holds the value used by the development server.
+const url = (window.TRACKER_CONFIG && window.TRACKER_CONFIG.apiBaseURL) || "http://localhost:8080/api/v1"
 
 const getTrackerByID = async (id) => {
     const uri = `${url}/tracker/${id}`;